@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"backend/internal/cache"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/repository"
+	"backend/internal/service"
+)
+
+func main() {
+	file := flag.String("file", "", "CSV file with code, name_id and name_en columns")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("Usage: import-icd10 -file codes.csv")
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	dbConfig := database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.DBName,
+		SSLMode:  cfg.Database.SSLMode,
+	}
+
+	db, err := database.NewPostgresDB(dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	// The API cache is not reachable from here; cached searches expire on their own.
+	icd10Service := service.NewICD10Service(repository.NewICD10Repository(db), cache.NewNoOpCache())
+
+	log.Printf("📥 Importing ICD-10 codes from %s...", *file)
+	count, err := icd10Service.ImportCSV(context.Background(), f)
+	if err != nil {
+		log.Fatalf("Failed to import ICD-10 codes: %v", err)
+	}
+	log.Printf("✅ Imported %d ICD-10 code(s)", count)
+}
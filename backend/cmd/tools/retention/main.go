@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"backend/internal/cache"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"backend/internal/storage"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "anonymize eligible patients instead of only reporting them")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize database
+	dbConfig := database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.DBName,
+		SSLMode:  cfg.Database.SSLMode,
+	}
+
+	db, err := database.NewPostgresDB(dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	fileStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// The API cache is not reachable from here; cached entries expire on their own.
+	retentionService := service.NewRetentionService(
+		repository.NewRetentionRepository(db),
+		fileStorage,
+		cache.NewNoOpCache(),
+		db,
+		cfg.Retention.YearsByPatientType,
+	)
+
+	ctx := context.Background()
+
+	for patientType, years := range cfg.Retention.YearsByPatientType {
+		log.Printf("📋 Policy: %-8s %d year(s)", patientType, years)
+	}
+
+	candidates, err := retentionService.DryRun(ctx)
+	if err != nil {
+		log.Fatalf("Failed to build retention report: %v", err)
+	}
+
+	log.Printf("🔍 %d patient(s) past their retention period", len(candidates))
+	for _, c := range candidates {
+		log.Printf("  %s  %-8s last activity %s  eligible since %s  %s",
+			c.PatientID, c.PatientType,
+			c.LastActivityAt.Format("2006-01-02"), c.EligibleSince.Format("2006-01-02"),
+			c.FullName)
+	}
+
+	if !*apply {
+		log.Println("ℹ Dry run only, pass -apply to anonymize these patients")
+		return
+	}
+
+	audits, err := retentionService.Run(ctx, service.RetentionTriggerCLI)
+	for _, audit := range audits {
+		log.Printf("  ✓ %s  guardians=%d attachments=%d checkups=%d",
+			audit.PatientID, audit.GuardiansDeleted, audit.AttachmentsDeleted, audit.CheckupsDeidentified)
+	}
+	log.Println("=" + fmt.Sprintf("%50s", "="))
+	log.Printf("✅ Anonymized %d patient(s)", len(audits))
+	if err != nil {
+		log.Fatalf("Some erasures failed: %v", err)
+	}
+}
@@ -14,7 +14,11 @@ import (
 	"backend/internal/cache"
 	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/events"
+	"backend/internal/jobs"
+	"backend/internal/notify"
 	"backend/internal/router"
+	"backend/internal/storage"
 
 	"gorm.io/gorm"
 )
@@ -23,7 +27,11 @@ type App struct {
 	config              *config.Config
 	db                  *gorm.DB
 	cache               cache.Cache
+	storage             storage.Storage
+	notifier            notify.Notifier
 	server              *http.Server
+	scheduler           *jobs.Scheduler
+	queueEvents         *events.Broker
 	telemetryShutdownFn func(context.Context) error
 }
 
@@ -52,6 +60,16 @@ func New() (*App, error) {
 		log.Printf("Warning: Failed to initialize cache: %v", err)
 	}
 
+	// Initialize file storage
+	if err := app.initStorage(); err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	// Initialize notification channel
+	if err := app.initNotifier(); err != nil {
+		return nil, fmt.Errorf("failed to initialize notifier: %w", err)
+	}
+
 	// Initialize server
 	app.initServer()
 
@@ -110,8 +128,36 @@ func (a *App) initCache() error {
 	return nil
 }
 
+func (a *App) initStorage() error {
+	fileStorage, err := storage.New(a.config.Storage)
+	if err != nil {
+		return err
+	}
+
+	a.storage = fileStorage
+	if a.config.Storage.Driver == "s3" {
+		log.Printf("✓ S3 storage initialized (bucket: %s)", a.config.Storage.S3Bucket)
+	} else {
+		log.Printf("✓ Local storage initialized (%s)", a.config.Storage.LocalPath)
+	}
+	return nil
+}
+
+func (a *App) initNotifier() error {
+	notifier, err := notify.New(a.config.Notification)
+	if err != nil {
+		return err
+	}
+
+	a.notifier = notifier
+	log.Printf("✓ Notifications initialized (%s)", a.config.Notification.Driver)
+	return nil
+}
+
 func (a *App) initServer() {
-	container := NewContainer(a.db, a.cache)
+	container := NewContainer(a.config, a.db, a.cache, a.storage, a.notifier)
+	a.scheduler = container.Scheduler
+	a.queueEvents = container.QueueEvents
 
 	r := router.New(container.Handlers())
 	ginRouter := r.Setup(a.config.IsDevelopment())
@@ -146,6 +192,9 @@ func (a *App) Run() error {
 		serverErrors <- a.server.ListenAndServe()
 	}()
 
+	// Start background jobs
+	a.scheduler.Start(context.Background())
+
 	// Channel to listen for interrupt signal
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -173,11 +222,22 @@ func (a *App) Run() error {
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
+	// End open event streams; the server waits for them otherwise
+	if a.queueEvents != nil {
+		a.queueEvents.Close()
+	}
+
 	// Shutdown HTTP server
 	if err := a.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 
+	// Stop background jobs before closing their dependencies
+	if a.scheduler != nil {
+		a.scheduler.Stop()
+		log.Println("✓ Background jobs stopped")
+	}
+
 	// Close database connection
 	if a.db != nil {
 		sqlDB, err := a.db.DB()
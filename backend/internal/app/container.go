@@ -2,71 +2,210 @@ package app
 
 import (
 	"backend/internal/cache"
+	"backend/internal/config"
+	"backend/internal/documents"
+	"backend/internal/events"
 	"backend/internal/handlers"
+	"backend/internal/jobs"
+	"backend/internal/notify"
 	"backend/internal/repository"
 	"backend/internal/service"
+	"backend/internal/storage"
 
 	"gorm.io/gorm"
 )
 
 type Container struct {
-	UserHandler           *handlers.UserHandler
-	PatientHandler        *handlers.PatientHandler
-	PatientCheckupHandler *handlers.PatientCheckupHandler
-	AuthHandler           *handlers.AuthHandler
-	MedicineHandler       *handlers.MedicineHandler
-	MedicineBatchHandler  *handlers.MedicineBatchHandler
-	DashboardHandler      *handlers.DashboardHandler
+	UserHandler            *handlers.UserHandler
+	PatientHandler         *handlers.PatientHandler
+	PatientGuardianHandler *handlers.PatientGuardianHandler
+	PatientCheckupHandler  *handlers.PatientCheckupHandler
+	AuthHandler            *handlers.AuthHandler
+	MedicineHandler        *handlers.MedicineHandler
+	MedicineBatchHandler   *handlers.MedicineBatchHandler
+	DashboardHandler       *handlers.DashboardHandler
+	AttachmentHandler      *handlers.AttachmentHandler
+	RetentionHandler       *handlers.RetentionHandler
+	AppointmentHandler     *handlers.AppointmentHandler
+	ScheduleHandler        *handlers.ScheduleHandler
+	QueueHandler           *handlers.QueueHandler
+	ICD10Handler           *handlers.ICD10Handler
+	ReportHandler          *handlers.ReportHandler
+	FollowUpHandler        *handlers.FollowUpHandler
+	CheckupDocumentHandler *handlers.CheckupDocumentHandler
+	CheckupExportHandler   *handlers.CheckupExportHandler
+	StockAdjustmentHandler *handlers.StockAdjustmentHandler
+	StockTakeHandler       *handlers.StockTakeHandler
+	BatchExpiryHandler     *handlers.BatchExpiryHandler
+	DisposalHandler        *handlers.DisposalHandler
+	SupplierHandler        *handlers.SupplierHandler
+	PurchaseOrderHandler   *handlers.PurchaseOrderHandler
+	ReorderHandler         *handlers.ReorderHandler
+
+	Scheduler   *jobs.Scheduler
+	QueueEvents *events.Broker
 }
 
-func NewContainer(db *gorm.DB, cache cache.Cache) *Container {
+func NewContainer(cfg *config.Config, db *gorm.DB, cache cache.Cache, fileStorage storage.Storage, notifier notify.Notifier) *Container {
 	// repositories
 	userRepo := repository.NewUserRepository(db)
 	patientRepo := repository.NewPatientRepository(db)
+	patientGuardianRepo := repository.NewPatientGuardianRepository(db)
 	patientCheckupRepo := repository.NewPatientCheckupRepository(db)
 	medicineRepo := repository.NewMedicineRepository(db)
 	medicineBatchRepo := repository.NewMedicineBatchRepository(db)
 	medicineStockActivityRepo := repository.NewMedicineStockActivityRepository(db)
 	dashboardRepo := repository.NewDashboardRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	appointmentRepo := repository.NewAppointmentRepository(db)
+	scheduleRepo := repository.NewScheduleRepository(db)
+	queueRepo := repository.NewQueueRepository(db)
+	icd10Repo := repository.NewICD10Repository(db)
+	reportRepo := repository.NewReportRepository(db)
+	followUpRepo := repository.NewFollowUpRepository(db)
+	checkupDocumentRepo := repository.NewCheckupDocumentRepository(db)
+	stockAdjustmentRepo := repository.NewStockAdjustmentRepository(db)
+	stockTakeRepo := repository.NewStockTakeRepository(db)
+	batchExpiryRepo := repository.NewBatchExpiryRepository(db)
+	disposalRepo := repository.NewDisposalRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
+	reorderRepo := repository.NewReorderRepository(db)
 
 	// services
 	userService := service.NewUserService(userRepo, cache)
-	patientService := service.NewPatientService(patientRepo, cache)
-	medicineStockActivityService := service.NewMedicineStockActivityService(medicineStockActivityRepo, db)
+	patientService := service.NewPatientService(patientRepo, patientGuardianRepo, cache)
+	patientGuardianService := service.NewPatientGuardianService(patientGuardianRepo, patientRepo, cache, db)
+	medicineStockActivityService := service.NewMedicineStockActivityService(medicineStockActivityRepo, db, cfg.Clinic.Location())
 	patientCheckupService := service.NewPatientCheckupService(patientCheckupRepo, cache, db, medicineStockActivityService)
 	authService := service.NewAuthService(userRepo)
 	medicineService := service.NewMedicineService(medicineRepo, cache)
 	medicineBatchService := service.NewMedicineBatchService(medicineBatchRepo, cache, db, medicineStockActivityService)
 	dashboardService := service.NewDashboardService(dashboardRepo)
+	maxUploadSize := cfg.Storage.MaxUploadSizeMB << 20
+	attachmentService := service.NewAttachmentService(attachmentRepo, patientRepo, patientCheckupRepo, fileStorage, maxUploadSize)
+	retentionService := service.NewRetentionService(retentionRepo, fileStorage, cache, db, cfg.Retention.YearsByPatientType)
+	appointmentService := service.NewAppointmentService(appointmentRepo, scheduleRepo, cache, db, cfg.Clinic.Location())
+	scheduleService := service.NewScheduleService(scheduleRepo, db)
+	queueEvents := events.NewBroker()
+	queueService := service.NewQueueService(queueRepo, cache, db, queueEvents, cfg.Clinic.Location())
+	icd10Service := service.NewICD10Service(icd10Repo, cache)
+	reportService := service.NewReportService(reportRepo, cfg.Clinic.Location())
+	followUpService := service.NewFollowUpService(followUpRepo, db, notifier, cfg.Clinic.Location(), cfg.FollowUp.ReminderDaysBefore)
+	documentRenderer := documents.NewRenderer(documents.Letterhead{
+		Name:     cfg.Clinic.Name,
+		Address:  cfg.Clinic.Address,
+		Phone:    cfg.Clinic.Phone,
+		Email:    cfg.Clinic.Email,
+		LogoPath: cfg.Clinic.LogoPath,
+	}, cfg.Clinic.Location())
+	checkupDocumentService := service.NewCheckupDocumentService(checkupDocumentRepo, documentRenderer, cfg.Clinic.DocumentVerifyURL, cfg.Clinic.Location())
+	checkupExportService := service.NewCheckupExportService(patientCheckupRepo, patientRepo, cfg.Clinic.Location())
+	stockAdjustmentService := service.NewStockAdjustmentService(stockAdjustmentRepo, medicineBatchRepo, cache, db, medicineStockActivityService, cfg.Stock.AdjustmentApprovalThreshold)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, cache, db, stockAdjustmentService, documentRenderer, cfg.Clinic.Location())
+	batchExpiryService := service.NewBatchExpiryService(batchExpiryRepo, cache, db, medicineStockActivityService, notifier, cfg.Stock.ExpiryAlertDays)
+	disposalService := service.NewDisposalService(disposalRepo, cache, db, medicineStockActivityService, documentRenderer, cfg.Clinic.Location())
+	supplierService := service.NewSupplierService(supplierRepo)
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, cache, db, medicineStockActivityService, cfg.Clinic.Location())
+	reorderService := service.NewReorderService(reorderRepo, reportRepo, purchaseOrderService, cfg.Stock, cfg.Clinic.Location())
 
 	// handlers
 	userHandler := handlers.NewUserHandler(userService)
 	patientHandler := handlers.NewPatientHandler(patientService)
+	patientGuardianHandler := handlers.NewPatientGuardianHandler(patientGuardianService)
 	patientCheckupHandler := handlers.NewPatientCheckupHandler(patientCheckupService)
 	authHandler := handlers.NewAuthHandler(authService)
 	medicineHandler := handlers.NewMedicineHandler(medicineService, medicineStockActivityService)
 	medicineBatchHandler := handlers.NewMedicineBatchHandler(medicineBatchService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, maxUploadSize)
+	retentionHandler := handlers.NewRetentionHandler(retentionService)
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	queueHandler := handlers.NewQueueHandler(queueService)
+	icd10Handler := handlers.NewICD10Handler(icd10Service)
+	reportHandler := handlers.NewReportHandler(reportService)
+	followUpHandler := handlers.NewFollowUpHandler(followUpService)
+	checkupDocumentHandler := handlers.NewCheckupDocumentHandler(checkupDocumentService, cfg.Clinic.Name)
+	checkupExportHandler := handlers.NewCheckupExportHandler(checkupExportService)
+	stockAdjustmentHandler := handlers.NewStockAdjustmentHandler(stockAdjustmentService)
+	stockTakeHandler := handlers.NewStockTakeHandler(stockTakeService)
+	batchExpiryHandler := handlers.NewBatchExpiryHandler(batchExpiryService)
+	disposalHandler := handlers.NewDisposalHandler(disposalService)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+	reorderHandler := handlers.NewReorderHandler(reorderService)
+
+	// background jobs
+	scheduler := jobs.NewScheduler()
+	if cfg.Retention.JobEnabled {
+		scheduler.Register(jobs.NewRetentionJob(retentionService, cfg.Retention.JobInterval))
+	}
+	if cfg.FollowUp.ReminderJobEnabled {
+		scheduler.Register(jobs.NewFollowUpReminderJob(followUpService, cfg.FollowUp.ReminderJobInterval))
+	}
+	if cfg.Stock.ExpiryJobEnabled {
+		scheduler.Register(jobs.NewBatchExpiryJob(batchExpiryService, cfg.Stock.ExpiryJobInterval))
+	}
 
 	return &Container{
-		UserHandler:           userHandler,
-		PatientHandler:        patientHandler,
-		PatientCheckupHandler: patientCheckupHandler,
-		AuthHandler:           authHandler,
-		MedicineHandler:       medicineHandler,
-		MedicineBatchHandler:  medicineBatchHandler,
-		DashboardHandler:      dashboardHandler,
+		UserHandler:            userHandler,
+		PatientHandler:         patientHandler,
+		PatientGuardianHandler: patientGuardianHandler,
+		PatientCheckupHandler:  patientCheckupHandler,
+		AuthHandler:            authHandler,
+		MedicineHandler:        medicineHandler,
+		MedicineBatchHandler:   medicineBatchHandler,
+		DashboardHandler:       dashboardHandler,
+		AttachmentHandler:      attachmentHandler,
+		RetentionHandler:       retentionHandler,
+		AppointmentHandler:     appointmentHandler,
+		ScheduleHandler:        scheduleHandler,
+		QueueHandler:           queueHandler,
+		ICD10Handler:           icd10Handler,
+		ReportHandler:          reportHandler,
+		FollowUpHandler:        followUpHandler,
+		CheckupDocumentHandler: checkupDocumentHandler,
+		CheckupExportHandler:   checkupExportHandler,
+		StockAdjustmentHandler: stockAdjustmentHandler,
+		StockTakeHandler:       stockTakeHandler,
+		BatchExpiryHandler:     batchExpiryHandler,
+		DisposalHandler:        disposalHandler,
+		SupplierHandler:        supplierHandler,
+		PurchaseOrderHandler:   purchaseOrderHandler,
+		ReorderHandler:         reorderHandler,
+		Scheduler:              scheduler,
+		QueueEvents:            queueEvents,
 	}
 }
 
 func (c *Container) Handlers() *handlers.CombinedHandler {
 	return &handlers.CombinedHandler{
-		UserHandler:           c.UserHandler,
-		PatientHandler:        c.PatientHandler,
-		PatientCheckupHandler: c.PatientCheckupHandler,
-		AuthHandler:           c.AuthHandler,
-		MedicineHandler:       c.MedicineHandler,
-		MedicineBatchHandler:  c.MedicineBatchHandler,
-		DashboardHandler:      c.DashboardHandler,
+		UserHandler:            c.UserHandler,
+		PatientHandler:         c.PatientHandler,
+		PatientGuardianHandler: c.PatientGuardianHandler,
+		PatientCheckupHandler:  c.PatientCheckupHandler,
+		AuthHandler:            c.AuthHandler,
+		MedicineHandler:        c.MedicineHandler,
+		MedicineBatchHandler:   c.MedicineBatchHandler,
+		DashboardHandler:       c.DashboardHandler,
+		AttachmentHandler:      c.AttachmentHandler,
+		RetentionHandler:       c.RetentionHandler,
+		AppointmentHandler:     c.AppointmentHandler,
+		ScheduleHandler:        c.ScheduleHandler,
+		QueueHandler:           c.QueueHandler,
+		ICD10Handler:           c.ICD10Handler,
+		ReportHandler:          c.ReportHandler,
+		FollowUpHandler:        c.FollowUpHandler,
+		CheckupDocumentHandler: c.CheckupDocumentHandler,
+		CheckupExportHandler:   c.CheckupExportHandler,
+		StockAdjustmentHandler: c.StockAdjustmentHandler,
+		StockTakeHandler:       c.StockTakeHandler,
+		BatchExpiryHandler:     c.BatchExpiryHandler,
+		DisposalHandler:        c.DisposalHandler,
+		SupplierHandler:        c.SupplierHandler,
+		PurchaseOrderHandler:   c.PurchaseOrderHandler,
+		ReorderHandler:         c.ReorderHandler,
 	}
 }
@@ -3,6 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the production image has no zoneinfo
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +15,12 @@ type Config struct {
 	Server        ServerConfig
 	Database      DatabaseConfig
 	Redis         RedisConfig
+	Storage       StorageConfig
+	Retention     RetentionConfig
+	FollowUp      FollowUpConfig
+	Notification  NotificationConfig
+	Stock         StockConfig
+	Clinic        ClinicConfig
 	Observability ObservabilityConfig
 }
 
@@ -35,6 +45,89 @@ type RedisConfig struct {
 	DB       int
 }
 
+type StorageConfig struct {
+	Driver          string // local, s3
+	LocalPath       string
+	S3Endpoint      string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3Bucket        string
+	S3Region        string
+	S3UseSSL        bool
+	MaxUploadSizeMB int64
+}
+
+// RetentionConfig controls when patient identifiers are anonymized. A
+// retention period of zero years disables erasure for that patient type.
+type RetentionConfig struct {
+	JobEnabled         bool
+	JobInterval        time.Duration
+	YearsByPatientType map[string]int
+}
+
+// FollowUpConfig controls the follow-up reminder job. Reminders go out once,
+// ReminderDaysBefore days before the follow-up date (0 reminds on the day).
+type FollowUpConfig struct {
+	ReminderJobEnabled  bool
+	ReminderJobInterval time.Duration
+	ReminderDaysBefore  int
+}
+
+// NotificationConfig selects the channel used to reach patients and guardians.
+type NotificationConfig struct {
+	Driver string // log
+}
+
+// StockConfig controls stock adjustments and the batch expiry job. An
+// adjustment that changes stock by more than AdjustmentApprovalThreshold
+// units waits for a second admin to approve it before it is applied. The
+// expiry job marks expired batches and alerts admins when a batch comes
+// within each of ExpiryAlertDays days of its expiration date.
+//
+// Reorder suggestions forecast consumption from the last ForecastWindowDays
+// days, with seasonal factors for the flu and exam season months learnt
+// over ForecastHistoryDays. An order should last ForecastReviewDays beyond
+// the lead time, which is ForecastLeadTimeDays unless the supplier's own
+// record says otherwise. ForecastSafetyFactor is the number of standard
+// deviations of demand held as safety stock (1.65 for a 95% service level).
+type StockConfig struct {
+	AdjustmentApprovalThreshold int
+	ExpiryJobEnabled            bool
+	ExpiryJobInterval           time.Duration
+	ExpiryAlertDays             []int
+
+	ForecastHistoryDays  int
+	ForecastWindowDays   int
+	ForecastReviewDays   int
+	ForecastLeadTimeDays int
+	ForecastSafetyFactor float64
+	FluSeasonMonths      []int
+	ExamSeasonMonths     []int
+}
+
+// ClinicConfig describes the clinic itself. Doctor working hours and
+// holidays are interpreted in Timezone. Name, address, phone, email and
+// logo make up the letterhead of printed documents, whose QR code points
+// at DocumentVerifyURL followed by the verification code.
+type ClinicConfig struct {
+	Timezone          string
+	Name              string
+	Address           string
+	Phone             string
+	Email             string
+	LogoPath          string // optional PNG or JPEG
+	DocumentVerifyURL string
+}
+
+// Location returns the clinic time zone, falling back to UTC.
+func (c ClinicConfig) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 type ObservabilityConfig struct {
 	ServiceName string
 }
@@ -64,6 +157,56 @@ func Load() (*Config, error) {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       0,
 		},
+		Storage: StorageConfig{
+			Driver:          getEnv("STORAGE_DRIVER", "local"),
+			LocalPath:       getEnv("STORAGE_LOCAL_PATH", "./storage"),
+			S3Endpoint:      getEnv("S3_ENDPOINT", "localhost:9000"),
+			S3AccessKey:     getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:     getEnv("S3_SECRET_KEY", ""),
+			S3Bucket:        getEnv("S3_BUCKET", "mcu-attachments"),
+			S3Region:        getEnv("S3_REGION", "us-east-1"),
+			S3UseSSL:        getEnv("S3_USE_SSL", "false") == "true",
+			MaxUploadSizeMB: getEnvInt64("ATTACHMENT_MAX_SIZE_MB", 10),
+		},
+		Retention: RetentionConfig{
+			JobEnabled:  getEnv("RETENTION_JOB_ENABLED", "false") == "true",
+			JobInterval: getEnvDuration("RETENTION_JOB_INTERVAL", 24*time.Hour),
+			YearsByPatientType: map[string]int{
+				"student": int(getEnvInt64("RETENTION_YEARS_STUDENT", 5)),
+				"teacher": int(getEnvInt64("RETENTION_YEARS_TEACHER", 10)),
+				"general": int(getEnvInt64("RETENTION_YEARS_GENERAL", 10)),
+			},
+		},
+		FollowUp: FollowUpConfig{
+			ReminderJobEnabled:  getEnv("FOLLOW_UP_REMINDER_JOB_ENABLED", "false") == "true",
+			ReminderJobInterval: getEnvDuration("FOLLOW_UP_REMINDER_JOB_INTERVAL", time.Hour),
+			ReminderDaysBefore:  int(getEnvInt64("FOLLOW_UP_REMINDER_DAYS_BEFORE", 1)),
+		},
+		Notification: NotificationConfig{
+			Driver: getEnv("NOTIFICATION_DRIVER", "log"),
+		},
+		Stock: StockConfig{
+			AdjustmentApprovalThreshold: int(getEnvInt64("STOCK_ADJUSTMENT_APPROVAL_THRESHOLD", 20)),
+			ExpiryJobEnabled:            getEnv("STOCK_EXPIRY_JOB_ENABLED", "true") == "true",
+			ExpiryJobInterval:           getEnvDuration("STOCK_EXPIRY_JOB_INTERVAL", 24*time.Hour),
+			ExpiryAlertDays:             getEnvIntList("STOCK_EXPIRY_ALERT_DAYS", []int{90, 60, 30}),
+			ForecastHistoryDays:         int(getEnvInt64("STOCK_FORECAST_HISTORY_DAYS", 365)),
+			ForecastWindowDays:          int(getEnvInt64("STOCK_FORECAST_WINDOW_DAYS", 90)),
+			ForecastReviewDays:          int(getEnvInt64("STOCK_FORECAST_REVIEW_DAYS", 30)),
+			ForecastLeadTimeDays:        int(getEnvInt64("STOCK_FORECAST_LEAD_TIME_DAYS", 7)),
+			ForecastSafetyFactor:        getEnvFloat("STOCK_FORECAST_SAFETY_FACTOR", 1.65),
+			FluSeasonMonths:             getEnvIntList("STOCK_FLU_SEASON_MONTHS", []int{11, 12, 1, 2, 3}),
+			ExamSeasonMonths:            getEnvIntList("STOCK_EXAM_SEASON_MONTHS", []int{3, 6, 12}),
+		},
+		Clinic: ClinicConfig{
+			Timezone:          getEnv("CLINIC_TIMEZONE", "Asia/Jakarta"),
+			Name:              getEnv("CLINIC_NAME", "Klinik"),
+			Address:           getEnv("CLINIC_ADDRESS", ""),
+			Phone:             getEnv("CLINIC_PHONE", ""),
+			Email:             getEnv("CLINIC_EMAIL", ""),
+			LogoPath:          getEnv("CLINIC_LOGO_PATH", ""),
+			DocumentVerifyURL: getEnv("DOCUMENT_VERIFY_URL", "http://localhost:8080/api/v1/document-verifications"),
+		},
 		Observability: ObservabilityConfig{
 			ServiceName: getEnv("OTEL_SERVICE_NAME", "mcu-backend"),
 		},
@@ -86,6 +229,62 @@ func (c *Config) Validate() error {
 	if c.Database.DBName == "" {
 		return fmt.Errorf("database name is required")
 	}
+	if c.Storage.Driver != "local" && c.Storage.Driver != "s3" {
+		return fmt.Errorf("unsupported storage driver %q", c.Storage.Driver)
+	}
+	if c.Retention.JobEnabled && c.Retention.JobInterval <= 0 {
+		return fmt.Errorf("retention job interval must be positive")
+	}
+	for patientType, years := range c.Retention.YearsByPatientType {
+		if years < 0 {
+			return fmt.Errorf("retention years for %s must not be negative", patientType)
+		}
+	}
+	if c.FollowUp.ReminderJobEnabled && c.FollowUp.ReminderJobInterval <= 0 {
+		return fmt.Errorf("follow-up reminder job interval must be positive")
+	}
+	if c.FollowUp.ReminderDaysBefore < 0 {
+		return fmt.Errorf("follow-up reminder days must not be negative")
+	}
+	if c.Notification.Driver != "log" {
+		return fmt.Errorf("unsupported notification driver %q", c.Notification.Driver)
+	}
+	if c.Stock.AdjustmentApprovalThreshold < 0 {
+		return fmt.Errorf("stock adjustment approval threshold must not be negative")
+	}
+	if c.Stock.ExpiryJobEnabled && c.Stock.ExpiryJobInterval <= 0 {
+		return fmt.Errorf("stock expiry job interval must be positive")
+	}
+	for _, days := range c.Stock.ExpiryAlertDays {
+		if days <= 0 {
+			return fmt.Errorf("stock expiry alert days must be positive")
+		}
+	}
+	if c.Stock.ForecastWindowDays <= 0 || c.Stock.ForecastHistoryDays < c.Stock.ForecastWindowDays {
+		return fmt.Errorf("stock forecast window must be positive and within the forecast history")
+	}
+	if c.Stock.ForecastReviewDays < 0 || c.Stock.ForecastLeadTimeDays <= 0 {
+		return fmt.Errorf("stock forecast lead time must be positive and review days not negative")
+	}
+	if c.Stock.ForecastSafetyFactor < 0 {
+		return fmt.Errorf("stock forecast safety factor must not be negative")
+	}
+	for _, month := range append(append([]int{}, c.Stock.FluSeasonMonths...), c.Stock.ExamSeasonMonths...) {
+		if month < 1 || month > 12 {
+			return fmt.Errorf("season months must be between 1 and 12")
+		}
+	}
+	if _, err := time.LoadLocation(c.Clinic.Timezone); err != nil {
+		return fmt.Errorf("invalid clinic timezone %q: %w", c.Clinic.Timezone, err)
+	}
+	if c.Clinic.LogoPath != "" {
+		if _, err := os.Stat(c.Clinic.LogoPath); err != nil {
+			return fmt.Errorf("clinic logo: %w", err)
+		}
+	}
+	if c.Clinic.DocumentVerifyURL == "" {
+		return fmt.Errorf("document verification URL is required")
+	}
 	return nil
 }
 
@@ -96,6 +295,33 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Env == "development"
 }
@@ -103,3 +329,22 @@ func (c *Config) IsDevelopment() bool {
 func (c *Config) IsProduction() bool {
 	return c.Server.Env == "production"
 }
+
+// getEnvIntList parses a comma-separated list of integers. An unparsable
+// list falls back to the default as a whole.
+func getEnvIntList(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var parsed []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return defaultValue
+		}
+		parsed = append(parsed, n)
+	}
+	return parsed
+}
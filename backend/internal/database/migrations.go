@@ -0,0 +1,387 @@
+package database
+
+import (
+	"backend/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// dataMigrations run after AutoMigrate. Each step must be idempotent because
+// it runs on every startup.
+var dataMigrations = []func(db *gorm.DB) error{
+	migrateEmergencyContactsToGuardians,
+	backfillCheckupStatusTimestamps,
+	markLegacyPrescriptionsDispensed,
+	backfillMedicineAllocations,
+	migrateBloodPressureReadings,
+	backfillCheckupRevisions,
+	splitExpiredMedicineStock,
+	keepExpiredStockOnHand,
+	disposeExpiredStockOnHand,
+}
+
+func runDataMigrations(db *gorm.DB) error {
+	for _, migrate := range dataMigrations {
+		if err := migrate(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnBackfill fills a column from the data that was there before it.
+type columnBackfill struct {
+	model  any
+	column string
+	fill   func(db *gorm.DB) error
+}
+
+// columnBackfills run once, on the startup that adds their column, so rows
+// written after that are left as they were saved.
+var columnBackfills = []columnBackfill{
+	{model: &models.PatientCheckup{}, column: "doctor_id", fill: mapCheckupDoctorNames},
+	{model: &models.PatientCheckupMedicineAllocation{}, column: "unit_cost", fill: priceDispensedAllocations},
+	{model: &models.MedicineStockActivity{}, column: "unit_cost", fill: priceDispensedActivities},
+}
+
+// pendingColumnBackfills lists the backfills whose column does not exist
+// yet. It must be called before AutoMigrate adds them.
+func pendingColumnBackfills(db *gorm.DB) []columnBackfill {
+	var pending []columnBackfill
+	for _, backfill := range columnBackfills {
+		if !db.Migrator().HasColumn(backfill.model, backfill.column) {
+			pending = append(pending, backfill)
+		}
+	}
+	return pending
+}
+
+func runColumnBackfills(db *gorm.DB, backfills []columnBackfill) error {
+	for _, backfill := range backfills {
+		if err := backfill.fill(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateEmergencyContactsToGuardians moves the legacy emergency_contact_*
+// columns of patients into patient_guardians and drops them afterwards.
+func migrateEmergencyContactsToGuardians(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Patient{}, "emergency_contact_name") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO patient_guardians (
+				id, patient_id, full_name, relationship, phone_number,
+				is_primary, can_be_notified, can_consent_treatment, created_at, updated_at
+			)
+			SELECT
+				gen_random_uuid(), p.id,
+				COALESCE(NULLIF(p.emergency_contact_name, ''), 'Emergency contact'),
+				'other',
+				COALESCE(p.emergency_contact_phone, ''),
+				true, true, false, NOW(), NOW()
+			FROM patients p
+			WHERE (NULLIF(p.emergency_contact_name, '') IS NOT NULL OR NULLIF(p.emergency_contact_phone, '') IS NOT NULL)
+				AND NOT EXISTS (SELECT 1 FROM patient_guardians g WHERE g.patient_id = p.id)
+		`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Migrator().DropColumn(&models.Patient{}, "emergency_contact_name"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&models.Patient{}, "emergency_contact_phone")
+	})
+}
+
+// backfillCheckupStatusTimestamps fills the workflow timestamps of checkups
+// created before status transitions were recorded.
+func backfillCheckupStatusTimestamps(db *gorm.DB) error {
+	if err := db.Exec(`
+		UPDATE patient_checkups
+		SET completed_at = updated_at
+		WHERE status = 'completed' AND completed_at IS NULL
+	`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		UPDATE patient_checkups
+		SET status_changed_at = updated_at
+		WHERE status_changed_at IS NULL
+	`).Error
+}
+
+// markLegacyPrescriptionsDispensed flags prescription lines written before the
+// dispense step existed as dispensed, since their stock was taken on creation.
+// Lines serialized since then always carry the dispensed_quantity key.
+func markLegacyPrescriptionsDispensed(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE patient_checkups
+		SET medicines = (
+				SELECT jsonb_agg(m || jsonb_build_object('dispensed_quantity', m->'quantity'))
+				FROM jsonb_array_elements(medicines) m
+			),
+			dispensed_at = created_at
+		WHERE jsonb_typeof(medicines) = 'array'
+			AND jsonb_array_length(medicines) > 0
+			AND NOT jsonb_exists(medicines->0, 'dispensed_quantity')
+	`).Error
+}
+
+// backfillMedicineAllocations rebuilds per-batch allocations of checkups
+// dispensed before allocations were recorded, from their net stock activity
+// per batch.
+func backfillMedicineAllocations(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO patient_checkup_medicine_allocations (
+			id, patient_checkup_id, medicine_id, medicine_batch_id,
+			quantity, returned_quantity, created_at, updated_at
+		)
+		SELECT
+			gen_random_uuid(), net.patient_checkup_id, net.medicine_id, net.medicine_batch_id,
+			net.quantity, 0, net.dispensed_at, NOW()
+		FROM (
+			SELECT
+				patient_checkup_id, medicine_id, medicine_batch_id,
+				-SUM(quantity_delta) AS quantity,
+				MIN(created_at) AS dispensed_at
+			FROM medicine_stock_activities
+			WHERE source = 'patient_checkup'
+				AND patient_checkup_id IS NOT NULL
+				AND medicine_batch_id IS NOT NULL
+				AND deleted_at IS NULL
+			GROUP BY patient_checkup_id, medicine_id, medicine_batch_id
+		) net
+		JOIN patient_checkups c ON c.id = net.patient_checkup_id
+		WHERE net.quantity > 0
+			AND NOT EXISTS (
+				SELECT 1 FROM patient_checkup_medicine_allocations x
+				WHERE x.patient_checkup_id = net.patient_checkup_id
+			)
+	`).Error
+}
+
+// mapCheckupDoctorNames links the checkups recorded before doctor accounts
+// to the doctor whose name matches the free-text doctor_name. Names are
+// compared without case, the "dr."/"drg." title, academic degrees after a
+// comma and repeated spaces; a name matching more than one doctor is left
+// unlinked.
+func mapCheckupDoctorNames(db *gorm.DB) error {
+	return db.Exec(fmt.Sprintf(`
+		UPDATE patient_checkups c
+		SET doctor_id = m.user_id
+		FROM (
+			SELECT %s AS name, MIN(id::text)::uuid AS user_id
+			FROM users
+			WHERE role = 'doctor' AND deleted_at IS NULL
+			GROUP BY 1
+			HAVING COUNT(*) = 1
+		) m
+		WHERE c.doctor_id IS NULL
+			AND NULLIF(TRIM(c.doctor_name), '') IS NOT NULL
+			AND %s = m.name
+	`, normalizedDoctorName("name"), normalizedDoctorName("c.doctor_name"))).Error
+}
+
+func normalizedDoctorName(column string) string {
+	return fmt.Sprintf(
+		`regexp_replace(regexp_replace(lower(trim(split_part(%s, ',', 1))), '^drg?(\.\s*|\s+)', ''), '\s+', ' ', 'g')`,
+		column,
+	)
+}
+
+// migrateBloodPressureReadings splits the legacy "120/80" blood_pressure text
+// of checkups and queue entries into systolic and diastolic columns, derives
+// the abnormal vital flags of existing checkups and drops the text column.
+// Checkup readings that cannot be parsed are kept in the notes.
+func migrateBloodPressureReadings(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&models.PatientCheckup{}, &models.QueueEntry{}} {
+			if !tx.Migrator().HasColumn(model, "blood_pressure") {
+				continue
+			}
+
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			table := stmt.Schema.Table
+
+			if err := tx.Exec(fmt.Sprintf(`
+				UPDATE %s t
+				SET blood_pressure_systolic = r.systolic, blood_pressure_diastolic = r.diastolic
+				FROM (
+					SELECT id, m[1]::int AS systolic, m[2]::int AS diastolic
+					FROM (
+						SELECT id, regexp_match(blood_pressure, '^\s*(\d{2,3})\s*/\s*(\d{2,3})\s*$') AS m
+						FROM %s
+						WHERE blood_pressure_systolic IS NULL AND NULLIF(TRIM(blood_pressure), '') IS NOT NULL
+					) parsed
+					WHERE m IS NOT NULL
+				) r
+				WHERE t.id = r.id
+					AND r.systolic BETWEEN 50 AND 280
+					AND r.diastolic BETWEEN 20 AND 180
+					AND r.systolic > r.diastolic
+			`, table, table)).Error; err != nil {
+				return err
+			}
+
+			if table == "patient_checkups" {
+				if err := tx.Exec(`
+					UPDATE patient_checkups
+					SET notes = CONCAT_WS(E'\n', NULLIF(notes, ''), 'Blood pressure (legacy): ' || TRIM(blood_pressure))
+					WHERE blood_pressure_systolic IS NULL AND NULLIF(TRIM(blood_pressure), '') IS NOT NULL
+				`).Error; err != nil {
+					return err
+				}
+
+				if err := tx.Exec(`
+					UPDATE patient_checkups
+					SET fever = COALESCE(temperature_c >= ?, FALSE),
+						hypoxia = COALESCE(oxygen_saturation < ?, FALSE),
+						blood_pressure_category = CASE
+							WHEN blood_pressure_systolic IS NULL OR blood_pressure_diastolic IS NULL THEN NULL
+							WHEN blood_pressure_systolic > 180 OR blood_pressure_diastolic > 120 THEN ?
+							WHEN blood_pressure_systolic >= 140 OR blood_pressure_diastolic >= 90 THEN ?
+							WHEN blood_pressure_systolic >= 130 OR blood_pressure_diastolic >= 80 THEN ?
+							WHEN blood_pressure_systolic >= 120 THEN ?
+							ELSE ?
+						END
+				`,
+					models.FeverThresholdC, models.HypoxiaThresholdO2,
+					models.BloodPressureCrisis, models.BloodPressureStage2, models.BloodPressureStage1,
+					models.BloodPressureElevated, models.BloodPressureNormal,
+				).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Migrator().DropColumn(model, "blood_pressure"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillCheckupRevisions records the current state of checkups created
+// before revisions were kept as their first revision, so every checkup has a
+// baseline to diff later changes against. Snapshot keys follow
+// models.PatientCheckupSnapshot.
+func backfillCheckupRevisions(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO patient_checkup_revisions (
+			id, patient_checkup_id, revision, reason, changed_by_user_id,
+			snapshot, changes, created_at
+		)
+		SELECT
+			gen_random_uuid(), c.id, 1, 'Recorded when revision history was introduced', NULL,
+			jsonb_build_object(
+				'visit_date', c.visit_date,
+				'status', c.status,
+				'chief_complaint', c.chief_complaint,
+				'symptoms', c.symptoms,
+				'diagnosis', c.diagnosis,
+				'diagnoses', COALESCE((
+					SELECT jsonb_agg(jsonb_build_object('code', d.code, 'type', d.type) ORDER BY d.position)
+					FROM patient_checkup_diagnoses d
+					WHERE d.patient_checkup_id = c.id
+				), '[]'::jsonb),
+				'temperature_c', c.temperature_c,
+				'blood_pressure_systolic', c.blood_pressure_systolic,
+				'blood_pressure_diastolic', c.blood_pressure_diastolic,
+				'heart_rate', c.heart_rate,
+				'respiratory_rate', c.respiratory_rate,
+				'oxygen_saturation', c.oxygen_saturation,
+				'height_cm', c.height_cm,
+				'weight_kg', c.weight_kg,
+				'medicines', c.medicines,
+				'treatment_plan', c.treatment_plan,
+				'notes', c.notes,
+				'doctor_id', c.doctor_id,
+				'doctor_name', c.doctor_name,
+				'follow_up_date', to_char(c.follow_up_date, 'YYYY-MM-DD"T00:00:00Z"'),
+				'started_at', c.started_at,
+				'completed_at', c.completed_at,
+				'dispensed_at', c.dispensed_at
+			),
+			'[]'::jsonb,
+			c.updated_at
+		FROM patient_checkups c
+		WHERE NOT EXISTS (
+			SELECT 1 FROM patient_checkup_revisions r
+			WHERE r.patient_checkup_id = c.id
+		)
+	`).Error
+}
+
+// splitExpiredMedicineStock moves the quantity of expired batches out of
+// current_stock into expired_stock. It only touches medicines whose figures
+// do not match their batches yet.
+func splitExpiredMedicineStock(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE medicines m
+		SET current_stock = totals.sellable, expired_stock = totals.expired
+		FROM (
+			SELECT
+				medicine_id,
+				COALESCE(SUM(quantity) FILTER (WHERE status <> 'expired'), 0) AS sellable,
+				COALESCE(SUM(quantity) FILTER (WHERE status = 'expired'), 0) AS expired
+			FROM medicine_batches
+			GROUP BY medicine_id
+		) totals
+		WHERE totals.medicine_id = m.id
+			AND (m.current_stock <> totals.sellable OR m.expired_stock <> totals.expired)
+	`).Error
+}
+
+// keepExpiredStockOnHand rewrites expiry activities logged as decreases. An
+// expiring batch stays on hand until it is disposed of, so the move belongs
+// in expired_delta and the quantity delta is 0.
+func keepExpiredStockOnHand(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE medicine_stock_activities
+		SET expired_delta = -quantity_delta, quantity_delta = 0, change_type = 'status', stock_after = stock_before
+		WHERE source = 'expiry' AND quantity_delta <> 0
+	`).Error
+}
+
+// disposeExpiredStockOnHand rewrites disposals of expired batches that were
+// logged against sellable stock, which did not move. The units leave the
+// stock on hand and the expired stock.
+func disposeExpiredStockOnHand(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE medicine_stock_activities
+		SET expired_delta = quantity_delta, stock_before = stock_after - quantity_delta
+		WHERE source = 'disposal' AND quantity_delta < 0 AND expired_delta = 0 AND stock_before = stock_after
+	`).Error
+}
+
+// priceDispensedAllocations prices the allocations recorded before prices
+// were kept with them, at the prices their batches carried when the column
+// was added, the closest left.
+func priceDispensedAllocations(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE patient_checkup_medicine_allocations a
+		SET unit_cost = b.unit_cost, selling_price = b.selling_price
+		FROM medicine_batches b
+		WHERE b.id = a.medicine_batch_id
+	`).Error
+}
+
+// priceDispensedActivities does the same for the patient checkup activities.
+func priceDispensedActivities(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE medicine_stock_activities a
+		SET unit_cost = b.unit_cost, selling_price = b.selling_price
+		FROM medicine_batches b
+		WHERE b.id = a.medicine_batch_id AND a.source = 'patient_checkup'
+	`).Error
+}
@@ -53,12 +53,47 @@ func NewPostgresDB(cfg Config) (*gorm.DB, error) {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	backfills := pendingColumnBackfills(db)
+
+	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Patient{},
+		&models.PatientGuardian{},
+		&models.ICD10Code{},
 		&models.PatientCheckup{},
+		&models.PatientCheckupDiagnosis{},
+		&models.PatientCheckupTransition{},
+		&models.PatientCheckupAmendment{},
+		&models.PatientCheckupRevision{},
+		&models.CheckupDocument{},
 		&models.Medicine{},
 		&models.MedicineBatch{},
 		&models.MedicineStockActivity{},
-	)
+		&models.BatchExpiryAlert{},
+		&models.StockAdjustment{},
+		&models.StockTake{},
+		&models.StockTakeLine{},
+		&models.Disposal{},
+		&models.DisposalItem{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderLine{},
+		&models.GoodsReceipt{},
+		&models.GoodsReceiptLine{},
+		&models.PatientCheckupMedicineAllocation{},
+		&models.Attachment{},
+		&models.PatientErasureAudit{},
+		&models.DoctorSchedule{},
+		&models.ClinicHoliday{},
+		&models.Appointment{},
+		&models.QueueCounter{},
+		&models.QueueEntry{},
+	); err != nil {
+		return err
+	}
+
+	if err := runDataMigrations(db); err != nil {
+		return err
+	}
+	return runColumnBackfills(db, backfills)
 }
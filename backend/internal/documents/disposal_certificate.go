@@ -0,0 +1,112 @@
+package documents
+
+import (
+	"backend/internal/models"
+	"bytes"
+	"fmt"
+)
+
+var (
+	disposalWidths  = []float64{10, 22, 44, 24, 22, 14, 16, 18}
+	disposalHeaders = []string{"No", "Kode", "Obat", "Batch", "Kedaluwarsa", "Jumlah", "Satuan", "Alasan"}
+)
+
+const disposalRowHeight = 6.5
+
+var disposalMethodLabels = map[string]string{
+	models.DisposalMethodIncineration:         "Insinerasi",
+	models.DisposalMethodEncapsulation:        "Enkapsulasi",
+	models.DisposalMethodChemicalInactivation: "Inaktivasi kimia",
+	models.DisposalMethodReturnToSupplier:     "Dikembalikan ke pemasok",
+	models.DisposalMethodWasteContractor:      "Diserahkan ke pengolah limbah berizin",
+	models.DisposalMethodOther:                "Lainnya",
+}
+
+var disposalReasonLabels = map[string]string{
+	models.DisposalReasonExpired:  "Kedaluwarsa",
+	models.DisposalReasonDamaged:  "Rusak",
+	models.DisposalReasonRecalled: "Ditarik",
+}
+
+// RenderDisposalCertificate builds the disposal record (berita acara
+// pemusnahan) of a disposal. Its items must be loaded with their medicine
+// and batch.
+func (r *Renderer) RenderDisposalCertificate(disposal *models.Disposal) ([]byte, error) {
+	p := r.newPage()
+	p.title("BERITA ACARA PEMUSNAHAN OBAT")
+
+	witness := disposal.WitnessName
+	if disposal.WitnessPosition != nil && *disposal.WitnessPosition != "" {
+		witness = fmt.Sprintf("%s (%s)", witness, *disposal.WitnessPosition)
+	}
+
+	p.field("Nomor", disposal.Number)
+	p.field("Tanggal pemusnahan", p.date(disposal.DisposedAt))
+	p.field("Metode", disposalMethodLabels[disposal.Method])
+	p.field("Dokumen referensi", disposal.ReferenceDocument)
+	p.field("Saksi", witness)
+	if disposal.Notes != nil && *disposal.Notes != "" {
+		p.field("Catatan", *disposal.Notes)
+	}
+	p.pdf.Ln(3)
+	p.paragraph(fmt.Sprintf("Pada tanggal %s telah dilakukan pemusnahan obat dengan rincian sebagai berikut:", p.date(disposal.DisposedAt)))
+	p.pdf.Ln(2)
+
+	p.disposalHeader()
+	p.pdf.SetFont("Helvetica", "", 9)
+	total := 0
+	for i, item := range disposal.Items {
+		if p.pdf.GetY()+disposalRowHeight > 297-pageMargin {
+			p.pdf.AddPage()
+			p.disposalHeader()
+			p.pdf.SetFont("Helvetica", "", 9)
+		}
+
+		cells := []string{
+			fmt.Sprintf("%d", i+1),
+			item.Medicine.Code,
+			item.Medicine.Name,
+			item.MedicineBatch.BatchNumber,
+			item.MedicineBatch.ExpirationDate.Format("02-01-2006"),
+			fmt.Sprintf("%d", item.Quantity),
+			item.MedicineBatch.Unit,
+			disposalReasonLabels[item.Reason],
+		}
+		for j, cell := range cells {
+			align := "L"
+			if j == 0 || j == 4 || j == 5 {
+				align = "C"
+			}
+			p.pdf.CellFormat(disposalWidths[j], disposalRowHeight, p.fit(cell, disposalWidths[j]), "1", 0, align, false, 0, "")
+		}
+		p.pdf.Ln(-1)
+		total += item.Quantity
+	}
+
+	p.pdf.SetFont("Helvetica", "B", 9)
+	totalWidth := disposalWidths[0] + disposalWidths[1] + disposalWidths[2] + disposalWidths[3] + disposalWidths[4]
+	p.pdf.CellFormat(totalWidth, disposalRowHeight, "Jumlah", "1", 0, "R", false, 0, "")
+	p.pdf.CellFormat(disposalWidths[5], disposalRowHeight, fmt.Sprintf("%d", total), "1", 0, "C", false, 0, "")
+	p.pdf.CellFormat(disposalWidths[6]+disposalWidths[7], disposalRowHeight, "", "1", 1, "L", false, 0, "")
+
+	officer := ""
+	if disposal.DisposedBy != nil {
+		officer = disposal.DisposedBy.Name
+	}
+	p.signatures([]string{"Petugas pelaksana", "Saksi"}, []string{officer, disposal.WitnessName})
+
+	var buf bytes.Buffer
+	if err := p.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *page) disposalHeader() {
+	p.pdf.SetFont("Helvetica", "B", 9)
+	p.pdf.SetFillColor(230, 230, 230)
+	for i, header := range disposalHeaders {
+		p.pdf.CellFormat(disposalWidths[i], disposalRowHeight, header, "1", 0, "C", true, 0, "")
+	}
+	p.pdf.Ln(-1)
+}
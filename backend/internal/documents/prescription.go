@@ -0,0 +1,35 @@
+package documents
+
+import (
+	"backend/internal/models"
+	"fmt"
+)
+
+func (p *page) prescription(checkup *models.PatientCheckup) {
+	p.title("RESEP OBAT")
+
+	p.patientFields(checkup.Patient)
+	p.field("Tanggal", p.date(checkup.VisitDate))
+	if checkup.Patient.Allergies != nil && *checkup.Patient.Allergies != "" {
+		p.field("Alergi", *checkup.Patient.Allergies)
+	}
+	p.pdf.Ln(4)
+
+	for _, m := range checkup.Medicines {
+		p.pdf.SetFont("Helvetica", "B", 11)
+		p.pdf.CellFormat(10, 6, "R/", "", 0, "L", false, 0, "")
+		p.pdf.CellFormat(contentWidth-40, 6, p.fit(m.MedicineName, contentWidth-40), "", 0, "L", false, 0, "")
+		p.pdf.CellFormat(30, 6, fmt.Sprintf("No. %d", m.Quantity), "", 1, "R", false, 0, "")
+
+		p.pdf.SetX(pageMargin + 10)
+		p.pdf.SetFont("Helvetica", "", 10)
+		usage := fmt.Sprintf("S %s, %s selama %d hari", m.Frequency, m.Dosage, m.DurationDays)
+		p.pdf.MultiCell(contentWidth-10, 5.5, p.tr(usage), "", "L", false)
+		if m.Notes != nil && *m.Notes != "" {
+			p.pdf.SetX(pageMargin + 10)
+			p.pdf.SetFont("Helvetica", "I", 9)
+			p.pdf.MultiCell(contentWidth-10, 5, p.tr(*m.Notes), "", "L", false)
+		}
+		p.pdf.Ln(3)
+	}
+}
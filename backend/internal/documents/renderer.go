@@ -0,0 +1,285 @@
+package documents
+
+import (
+	"backend/internal/models"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	pageMargin   = 20.0
+	contentWidth = 210.0 - 2*pageMargin
+	labelWidth   = 45.0
+)
+
+var monthNames = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// Letterhead is printed at the top of every document.
+type Letterhead struct {
+	Name     string
+	Address  string
+	Phone    string
+	Email    string
+	LogoPath string
+}
+
+// Renderer turns checkup documents into A4 PDFs. Dates are printed in the
+// clinic time zone.
+type Renderer struct {
+	letterhead Letterhead
+	loc        *time.Location
+}
+
+func NewRenderer(letterhead Letterhead, loc *time.Location) *Renderer {
+	return &Renderer{letterhead: letterhead, loc: loc}
+}
+
+// Render builds the PDF of document. The checkup must have its patient and
+// diagnoses loaded; verifyURL is encoded in the QR code.
+func (r *Renderer) Render(document *models.CheckupDocument, checkup *models.PatientCheckup, verifyURL string) ([]byte, error) {
+	p := r.newPage()
+
+	switch document.Type {
+	case models.CheckupDocumentSummary:
+		p.summary(checkup)
+	case models.CheckupDocumentPrescription:
+		p.prescription(checkup)
+	case models.CheckupDocumentSickNote:
+		p.sickNote(document, checkup)
+	default:
+		return nil, fmt.Errorf("unknown document type %q", document.Type)
+	}
+
+	p.signature(document.CreatedAt, checkup.DoctorName)
+	if err := p.verification(document.VerificationCode, verifyURL); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := p.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// page wraps the PDF being written. Text goes through tr because the core
+// fonts only cover cp1252.
+type page struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+	loc *time.Location
+}
+
+func (r *Renderer) newPage() *page {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle(r.letterhead.Name, true)
+	pdf.AddPage()
+
+	p := &page{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor(""), loc: r.loc}
+	p.letterhead(r.letterhead)
+	return p
+}
+
+func (p *page) letterhead(l Letterhead) {
+	textX := pageMargin
+	top := p.pdf.GetY()
+	if l.LogoPath != "" {
+		p.pdf.ImageOptions(l.LogoPath, pageMargin, top, 0, 20, false, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+		textX += 25
+	}
+
+	p.pdf.SetXY(textX, top)
+	p.pdf.SetFont("Helvetica", "B", 16)
+	p.pdf.CellFormat(0, 8, p.tr(l.Name), "", 1, "L", false, 0, "")
+
+	var contact []string
+	if l.Phone != "" {
+		contact = append(contact, "Telp. "+l.Phone)
+	}
+	if l.Email != "" {
+		contact = append(contact, l.Email)
+	}
+	p.pdf.SetFont("Helvetica", "", 9)
+	for _, line := range []string{l.Address, strings.Join(contact, " | ")} {
+		if line == "" {
+			continue
+		}
+		p.pdf.SetX(textX)
+		p.pdf.CellFormat(0, 4.5, p.tr(line), "", 1, "L", false, 0, "")
+	}
+
+	y := p.pdf.GetY() + 2
+	if l.LogoPath != "" && y < top+22 {
+		y = top + 22
+	}
+	p.pdf.SetLineWidth(0.6)
+	p.pdf.Line(pageMargin, y, pageMargin+contentWidth, y)
+	p.pdf.SetLineWidth(0.2)
+	p.pdf.SetY(y + 6)
+}
+
+func (p *page) title(text string) {
+	p.pdf.SetFont("Helvetica", "BU", 13)
+	p.pdf.CellFormat(0, 7, p.tr(text), "", 1, "C", false, 0, "")
+	p.pdf.Ln(4)
+}
+
+func (p *page) heading(text string) {
+	p.pdf.Ln(2)
+	p.pdf.SetFont("Helvetica", "B", 11)
+	p.pdf.CellFormat(0, 6, p.tr(text), "", 1, "L", false, 0, "")
+}
+
+// field prints a "label : value" row, wrapping long values.
+func (p *page) field(label, value string) {
+	if value == "" {
+		value = "-"
+	}
+	p.pdf.SetFont("Helvetica", "", 10)
+	p.pdf.CellFormat(labelWidth, 5.5, p.tr(label), "", 0, "L", false, 0, "")
+	p.pdf.CellFormat(4, 5.5, ":", "", 0, "L", false, 0, "")
+	p.pdf.MultiCell(contentWidth-labelWidth-4, 5.5, p.tr(value), "", "L", false)
+}
+
+func (p *page) paragraph(text string) {
+	p.pdf.SetFont("Helvetica", "", 10)
+	p.pdf.MultiCell(0, 5.5, p.tr(text), "", "L", false)
+}
+
+// fit shortens text so it fits into width at the current font.
+func (p *page) fit(text string, width float64) string {
+	text = p.tr(text)
+	if p.pdf.GetStringWidth(text) <= width-2 {
+		return text
+	}
+	for len(text) > 0 && p.pdf.GetStringWidth(text+"...") > width-2 {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+func (p *page) patientFields(patient models.Patient) {
+	p.field("Nama", patient.FullName)
+	if patient.MedicalRecordNumber != nil {
+		p.field("No. rekam medis", *patient.MedicalRecordNumber)
+	}
+	p.field("Tanggal lahir", p.birthDate(patient.DateOfBirth))
+	p.field("Jenis kelamin", genderLabel(patient.Gender))
+}
+
+// signature prints the issue date and the examining doctor on the right.
+func (p *page) signature(issuedAt time.Time, doctorName *string) {
+	name := "(.................................)"
+	if doctorName != nil && *doctorName != "" {
+		name = *doctorName
+	}
+
+	p.pdf.Ln(10)
+	if p.pdf.GetY() > 297-pageMargin-75 {
+		p.pdf.AddPage()
+	}
+	x := pageMargin + contentWidth - 70
+	p.pdf.SetFont("Helvetica", "", 10)
+	for _, line := range []string{p.date(issuedAt), "Dokter pemeriksa"} {
+		p.pdf.SetX(x)
+		p.pdf.CellFormat(70, 5.5, p.tr(line), "", 1, "C", false, 0, "")
+	}
+	p.pdf.Ln(18)
+	p.pdf.SetX(x)
+	p.pdf.SetFont("Helvetica", "BU", 10)
+	p.pdf.CellFormat(70, 5.5, p.tr(name), "", 1, "C", false, 0, "")
+}
+
+// signatures prints a signature block per role side by side, with the name
+// under each or a dotted line where names has none.
+func (p *page) signatures(roles []string, names []string) {
+	p.pdf.Ln(10)
+	if p.pdf.GetY() > 297-pageMargin-40 {
+		p.pdf.AddPage()
+	}
+
+	const width = 70.0
+	top := p.pdf.GetY()
+	for i, role := range roles {
+		name := "(.................................)"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		x := pageMargin
+		if len(roles) > 1 {
+			x += float64(i) * (contentWidth - width) / float64(len(roles)-1)
+		}
+		p.pdf.SetXY(x, top)
+		p.pdf.SetFont("Helvetica", "", 10)
+		p.pdf.CellFormat(width, 5.5, p.tr(role), "", 2, "C", false, 0, "")
+		p.pdf.Ln(18)
+		p.pdf.SetX(x)
+		p.pdf.CellFormat(width, 5.5, p.tr(name), "", 2, "C", false, 0, "")
+	}
+}
+
+// verification prints the QR code and the code it resolves.
+func (p *page) verification(code, verifyURL string) error {
+	png, err := qrcode.Encode(verifyURL, qrcode.Medium, 256)
+	if err != nil {
+		return fmt.Errorf("encode verification QR code: %w", err)
+	}
+
+	p.pdf.Ln(8)
+	top := p.pdf.GetY()
+	const size = 28.0
+	p.pdf.RegisterImageOptionsReader("verification-qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	p.pdf.ImageOptions("verification-qr", pageMargin, top, size, size, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, verifyURL)
+
+	p.pdf.SetXY(pageMargin+size+4, top+4)
+	p.pdf.SetFont("Helvetica", "", 8)
+	p.pdf.MultiCell(contentWidth-size-4, 4, p.tr("Keaslian dokumen ini dapat diperiksa dengan memindai kode QR atau membuka:"), "", "L", false)
+	p.pdf.SetX(pageMargin + size + 4)
+	p.pdf.SetTextColor(0, 0, 160)
+	p.pdf.CellFormat(contentWidth-size-4, 4, p.tr(verifyURL), "", 1, "L", false, 0, verifyURL)
+	p.pdf.SetTextColor(0, 0, 0)
+	p.pdf.SetX(pageMargin + size + 4)
+	p.pdf.SetFont("Helvetica", "B", 9)
+	p.pdf.CellFormat(contentWidth-size-4, 5, "Kode verifikasi: "+code, "", 1, "L", false, 0, "")
+
+	return p.pdf.Error()
+}
+
+func (p *page) date(t time.Time) string {
+	t = t.In(p.loc)
+	return fmt.Sprintf("%d %s %d", t.Day(), monthNames[t.Month()-1], t.Year())
+}
+
+// calendarDate formats a date column, which carries no time zone.
+func (p *page) calendarDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), monthNames[t.Month()-1], t.Year())
+}
+
+func (p *page) birthDate(value string) string {
+	date, err := time.Parse("2006-01-02", value[:min(len(value), 10)])
+	if err != nil {
+		return value
+	}
+	return p.calendarDate(date)
+}
+
+func genderLabel(gender string) string {
+	switch gender {
+	case "male":
+		return "Laki-laki"
+	case "female":
+		return "Perempuan"
+	}
+	return "Lainnya"
+}
@@ -0,0 +1,57 @@
+package documents
+
+import (
+	"backend/internal/models"
+	"fmt"
+)
+
+// sickNote prints a "surat keterangan sakit". The diagnosis is left out on
+// purpose; the note goes to the school or employer.
+func (p *page) sickNote(document *models.CheckupDocument, checkup *models.PatientCheckup) {
+	p.title("SURAT KETERANGAN SAKIT")
+
+	p.paragraph("Yang bertanda tangan di bawah ini menerangkan bahwa:")
+	p.pdf.Ln(2)
+	p.patientFields(checkup.Patient)
+	p.field("Tanggal pemeriksaan", p.date(checkup.VisitDate))
+	p.pdf.Ln(2)
+
+	restDays := 0
+	if document.RestDays != nil {
+		restDays = *document.RestDays
+	}
+	period := ""
+	if document.RestFrom != nil && document.RestTo != nil {
+		period = fmt.Sprintf(", terhitung mulai tanggal %s sampai dengan %s",
+			p.calendarDate(*document.RestFrom), p.calendarDate(*document.RestTo))
+	}
+	p.paragraph(fmt.Sprintf(
+		"telah diperiksa dan dalam keadaan sakit, sehingga perlu beristirahat selama %d (%s) hari%s.",
+		restDays, spellNumber(restDays), period))
+	p.pdf.Ln(2)
+	p.paragraph("Demikian surat keterangan ini dibuat untuk dipergunakan sebagaimana mestinya.")
+}
+
+var numberWords = []string{
+	"nol", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan",
+	"sepuluh", "sebelas",
+}
+
+// spellNumber spells the rest days in Indonesian, as sick notes usually do.
+func spellNumber(n int) string {
+	switch {
+	case n < 0:
+		return fmt.Sprintf("%d", n)
+	case n < len(numberWords):
+		return numberWords[n]
+	case n < 20:
+		return numberWords[n-10] + " belas"
+	case n < 100:
+		words := numberWords[n/10] + " puluh"
+		if n%10 > 0 {
+			words += " " + numberWords[n%10]
+		}
+		return words
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,121 @@
+package documents
+
+import (
+	"backend/internal/models"
+	"fmt"
+	"strings"
+)
+
+func (p *page) summary(checkup *models.PatientCheckup) {
+	p.title("RINGKASAN PEMERIKSAAN")
+
+	p.heading("Data pasien")
+	p.patientFields(checkup.Patient)
+	p.field("Tanggal kunjungan", p.date(checkup.VisitDate))
+
+	p.heading("Anamnesis")
+	p.field("Keluhan utama", checkup.ChiefComplaint)
+	p.field("Gejala", strings.Join(checkup.Symptoms, ", "))
+
+	p.heading("Tanda vital")
+	for _, vital := range checkupVitals(checkup) {
+		p.field(vital[0], vital[1])
+	}
+
+	p.heading("Diagnosis")
+	for _, d := range checkup.Diagnoses {
+		label := "Diagnosis sekunder"
+		if d.Type == models.DiagnosisTypePrimary {
+			label = "Diagnosis utama"
+		}
+		name := d.ICD10Code.NameID
+		if name == "" {
+			name = d.ICD10Code.NameEN
+		}
+		p.field(label, strings.TrimSpace(d.Code+" "+name))
+	}
+	if checkup.Diagnosis != nil && *checkup.Diagnosis != "" {
+		p.field("Catatan diagnosis", *checkup.Diagnosis)
+	} else if len(checkup.Diagnoses) == 0 {
+		p.field("Diagnosis", "")
+	}
+
+	p.heading("Tata laksana")
+	p.field("Rencana terapi", deref(checkup.TreatmentPlan))
+	if checkup.FollowUpDate != nil {
+		p.field("Kontrol kembali", p.calendarDate(*checkup.FollowUpDate))
+	}
+	if len(checkup.Medicines) > 0 {
+		p.pdf.Ln(2)
+		p.medicineTable(checkup.Medicines)
+	}
+}
+
+// checkupVitals lists the recorded vitals as label and value pairs.
+func checkupVitals(checkup *models.PatientCheckup) [][2]string {
+	var vitals [][2]string
+	if checkup.TemperatureC != nil {
+		vitals = append(vitals, [2]string{"Suhu", fmt.Sprintf("%.1f °C", *checkup.TemperatureC)})
+	}
+	if bp := models.FormatBloodPressure(checkup.BloodPressureSystolic, checkup.BloodPressureDiastolic); bp != nil {
+		vitals = append(vitals, [2]string{"Tekanan darah", *bp + " mmHg"})
+	}
+	if checkup.HeartRate != nil {
+		vitals = append(vitals, [2]string{"Nadi", fmt.Sprintf("%d x/menit", *checkup.HeartRate)})
+	}
+	if checkup.RespiratoryRate != nil {
+		vitals = append(vitals, [2]string{"Laju napas", fmt.Sprintf("%d x/menit", *checkup.RespiratoryRate)})
+	}
+	if checkup.OxygenSaturation != nil {
+		vitals = append(vitals, [2]string{"Saturasi oksigen", fmt.Sprintf("%d%%", *checkup.OxygenSaturation)})
+	}
+	if checkup.HeightCm != nil {
+		vitals = append(vitals, [2]string{"Tinggi badan", fmt.Sprintf("%.1f cm", *checkup.HeightCm)})
+	}
+	if checkup.WeightKg != nil {
+		vitals = append(vitals, [2]string{"Berat badan", fmt.Sprintf("%.1f kg", *checkup.WeightKg)})
+	}
+	if len(vitals) == 0 {
+		vitals = append(vitals, [2]string{"Tanda vital", ""})
+	}
+	return vitals
+}
+
+func (p *page) medicineTable(medicines []models.PatientCheckupMedicine) {
+	widths := []float64{10, 58, 17, 30, 30, 25}
+	headers := []string{"No", "Obat", "Jumlah", "Dosis", "Frekuensi", "Durasi"}
+
+	p.pdf.SetFont("Helvetica", "B", 9)
+	p.pdf.SetFillColor(230, 230, 230)
+	for i, header := range headers {
+		p.pdf.CellFormat(widths[i], 6, header, "1", 0, "C", true, 0, "")
+	}
+	p.pdf.Ln(-1)
+
+	p.pdf.SetFont("Helvetica", "", 9)
+	for i, m := range medicines {
+		cells := []string{
+			fmt.Sprintf("%d", i+1),
+			m.MedicineName,
+			fmt.Sprintf("%d", m.Quantity),
+			m.Dosage,
+			m.Frequency,
+			fmt.Sprintf("%d hari", m.DurationDays),
+		}
+		for j, cell := range cells {
+			align := "L"
+			if j == 0 || j == 2 {
+				align = "C"
+			}
+			p.pdf.CellFormat(widths[j], 6, p.fit(cell, widths[j]), "1", 0, align, false, 0, "")
+		}
+		p.pdf.Ln(-1)
+	}
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
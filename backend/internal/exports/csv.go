@@ -0,0 +1,62 @@
+package exports
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// utf8BOM makes spreadsheet programs read the file as UTF-8.
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	buf    *bufio.Writer
+	w      *csv.Writer
+	record []string
+}
+
+// newCSVWriter buffers the output, so nothing reaches w until the first few
+// kilobytes of rows are written.
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	buf := bufio.NewWriter(w)
+	if _, err := buf.WriteString(utf8BOM); err != nil {
+		return nil, err
+	}
+	return &csvWriter{buf: buf, w: csv.NewWriter(buf)}, nil
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(cells []any) error {
+	c.record = c.record[:0]
+	for _, cell := range cells {
+		c.record = append(c.record, csvCell(cell))
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+func csvCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(cell)
+}
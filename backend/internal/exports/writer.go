@@ -0,0 +1,49 @@
+// Package exports writes tables as Excel workbooks or CSV files one row at a
+// time, so an export never has to hold all of its rows in memory.
+package exports
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv"
+)
+
+var ErrUnknownFormat = errors.New("export format must be xlsx or csv")
+
+// Writer receives the header and then the rows of a table. Cells may be
+// strings, integers, floats or nil for an empty cell. Close must be called to
+// finish the file; a workbook is only written out on Close.
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(cells []any) error
+	Close() error
+}
+
+// New returns a writer for format that writes to w. sheet names the
+// worksheet of a workbook and is ignored for CSV.
+func New(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	case FormatCSV:
+		return newCSVWriter(w)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ContentType returns the media type of files in format.
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// IsValidFormat reports whether New accepts format.
+func IsValidFormat(format string) bool {
+	return format == FormatXLSX || format == FormatCSV
+}
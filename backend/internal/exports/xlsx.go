@@ -0,0 +1,80 @@
+package exports
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxWriter streams rows into a single worksheet. excelize spills the rows
+// to a temporary file once they outgrow its buffer, and the workbook is
+// zipped straight into the output on Close.
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if sheet != "" {
+		if err := file.SetSheetName("Sheet1", sheet); err != nil {
+			file.Close()
+			return nil, err
+		}
+	} else {
+		sheet = "Sheet1"
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: file, stream: stream}, nil
+}
+
+// WriteHeader writes the columns in bold and keeps them visible while
+// scrolling.
+func (x *xlsxWriter) WriteHeader(columns []string) error {
+	style, err := x.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	if err := x.stream.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	cells := make([]any, len(columns))
+	for i, column := range columns {
+		cells[i] = excelize.Cell{StyleID: style, Value: column}
+	}
+	return x.writeCells(cells)
+}
+
+func (x *xlsxWriter) WriteRow(cells []any) error {
+	return x.writeCells(cells)
+}
+
+func (x *xlsxWriter) writeCells(cells []any) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
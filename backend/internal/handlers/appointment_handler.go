@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AppointmentHandler struct {
+	service service.AppointmentService
+}
+
+func NewAppointmentHandler(service service.AppointmentService) *AppointmentHandler {
+	return &AppointmentHandler{service: service}
+}
+
+func (h *AppointmentHandler) ListAppointments(c *gin.Context, params generated.ListAppointmentsParams) {
+	page := 1
+	perPage := 10
+
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	filter := repository.AppointmentFilter{}
+	if params.DoctorId != nil {
+		filter.DoctorID = uuid.UUID(*params.DoctorId).String()
+	}
+	if params.PatientId != nil {
+		filter.PatientID = uuid.UUID(*params.PatientId).String()
+	}
+	if params.Status != nil {
+		filter.Status = string(*params.Status)
+	}
+	if params.DateFrom != nil {
+		t := params.DateFrom.Time
+		filter.DateFrom = &t
+	}
+	if params.DateTo != nil {
+		t := params.DateTo.Time
+		filter.DateTo = &t
+	}
+
+	appointments, total, err := h.service.ListAppointments(c.Request.Context(), page, perPage, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch appointments",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAppointments(appointments),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
+	var req generated.CreateAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	appointment := mapper.ToModelAppointment(req)
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+
+	if err := h.service.BookAppointment(ctx, appointment); err != nil {
+		respondAppointmentError(c, err, "Failed to book appointment")
+		return
+	}
+
+	booked, err := h.service.GetAppointment(c.Request.Context(), appointment.ID)
+	if err != nil {
+		booked = appointment
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedAppointment(booked),
+	})
+}
+
+func (h *AppointmentHandler) GetAppointmentAvailability(c *gin.Context, params generated.GetAppointmentAvailabilityParams) {
+	doctorID := ""
+	if params.DoctorId != nil {
+		doctorID = uuid.UUID(*params.DoctorId).String()
+	}
+	from := params.DateFrom.Time
+	to := from
+	if params.DateTo != nil {
+		to = params.DateTo.Time
+	}
+
+	slots, err := h.service.GetAvailability(c.Request.Context(), doctorID, from, to)
+	if err != nil {
+		respondAppointmentError(c, err, "Failed to fetch availability")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAvailabilitySlots(slots),
+	})
+}
+
+func (h *AppointmentHandler) GetAppointment(c *gin.Context, id generated.IdParam) {
+	appointment, err := h.service.GetAppointment(c.Request.Context(), id)
+	if err != nil {
+		respondAppointmentError(c, err, "Failed to fetch appointment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAppointment(appointment),
+	})
+}
+
+func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context, id generated.IdParam) {
+	var req generated.RescheduleAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	var doctorID *string
+	if req.DoctorId != nil {
+		value := uuid.UUID(*req.DoctorId).String()
+		doctorID = &value
+	}
+
+	appointment, err := h.service.RescheduleAppointment(c.Request.Context(), id, req.StartAt, doctorID)
+	if err != nil {
+		respondAppointmentError(c, err, "Failed to reschedule appointment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAppointment(appointment),
+	})
+}
+
+func (h *AppointmentHandler) CancelAppointment(c *gin.Context, id generated.IdParam) {
+	var req generated.CancelAppointmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: "Invalid request body",
+			})
+			return
+		}
+	}
+
+	appointment, err := h.service.CancelAppointment(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		respondAppointmentError(c, err, "Failed to cancel appointment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAppointment(appointment),
+	})
+}
+
+func (h *AppointmentHandler) MarkAppointmentNoShow(c *gin.Context, id generated.IdParam) {
+	appointment, err := h.service.MarkAppointmentNoShow(c.Request.Context(), id)
+	if err != nil {
+		respondAppointmentError(c, err, "Failed to mark appointment as no-show")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAppointment(appointment),
+	})
+}
+
+func (h *AppointmentHandler) CheckInAppointment(c *gin.Context, id generated.IdParam) {
+	var req generated.CheckInAppointmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: "Invalid request body",
+			})
+			return
+		}
+	}
+
+	input := service.AppointmentCheckIn{ChiefComplaint: req.ChiefComplaint}
+	if req.Symptoms != nil {
+		input.Symptoms = *req.Symptoms
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	checkup, err := h.service.CheckInAppointment(ctx, id, input)
+	if err != nil {
+		respondAppointmentError(c, err, "Failed to check in appointment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedPatientCheckup(checkup),
+	})
+}
+
+// respondAppointmentError maps scheduling errors to responses; anything
+// unexpected becomes a 500 with fallback as the message.
+func respondAppointmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Appointment not found"})
+	case errors.Is(err, service.ErrNotADoctor):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: "Doctor not found or inactive"})
+	case errors.Is(err, service.ErrAvailabilityRange),
+		errors.Is(err, service.ErrSlotUnavailable),
+		errors.Is(err, service.ErrClinicClosed),
+		errors.Is(err, service.ErrAppointmentInPast):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrAppointmentConflict),
+		errors.Is(err, service.ErrPatientDoubleBooked),
+		errors.Is(err, service.ErrAppointmentNotBooked),
+		errors.Is(err, service.ErrAppointmentNotToday),
+		errors.Is(err, service.ErrAppointmentNotStarted):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Patient not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
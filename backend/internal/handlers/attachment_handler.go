@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"backend/internal/storage"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AttachmentHandler struct {
+	service       service.AttachmentService
+	maxUploadSize int64
+}
+
+func NewAttachmentHandler(service service.AttachmentService, maxUploadSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		service:       service,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (h *AttachmentHandler) ListPatientAttachments(c *gin.Context, id generated.IdParam) {
+	h.list(c, service.AttachmentOwnerPatient, id.String(), "Patient not found")
+}
+
+func (h *AttachmentHandler) UploadPatientAttachment(c *gin.Context, id generated.IdParam) {
+	h.upload(c, service.AttachmentOwnerPatient, id.String(), "Patient not found")
+}
+
+func (h *AttachmentHandler) DeletePatientAttachment(c *gin.Context, id generated.IdParam, attachmentId generated.AttachmentIdParam) {
+	h.delete(c, service.AttachmentOwnerPatient, id.String(), attachmentId.String())
+}
+
+func (h *AttachmentHandler) DownloadPatientAttachment(c *gin.Context, id generated.IdParam, attachmentId generated.AttachmentIdParam) {
+	h.download(c, service.AttachmentOwnerPatient, id.String(), attachmentId.String())
+}
+
+func (h *AttachmentHandler) ListPatientCheckupAttachments(c *gin.Context, id generated.IdParam) {
+	h.list(c, service.AttachmentOwnerPatientCheckup, id.String(), "Patient checkup not found")
+}
+
+func (h *AttachmentHandler) UploadPatientCheckupAttachment(c *gin.Context, id generated.IdParam) {
+	h.upload(c, service.AttachmentOwnerPatientCheckup, id.String(), "Patient checkup not found")
+}
+
+func (h *AttachmentHandler) DeletePatientCheckupAttachment(c *gin.Context, id generated.IdParam, attachmentId generated.AttachmentIdParam) {
+	h.delete(c, service.AttachmentOwnerPatientCheckup, id.String(), attachmentId.String())
+}
+
+func (h *AttachmentHandler) DownloadPatientCheckupAttachment(c *gin.Context, id generated.IdParam, attachmentId generated.AttachmentIdParam) {
+	h.download(c, service.AttachmentOwnerPatientCheckup, id.String(), attachmentId.String())
+}
+
+func (h *AttachmentHandler) list(c *gin.Context, ownerType, ownerID, notFoundMessage string) {
+	attachments, err := h.service.List(c.Request.Context(), ownerType, ownerID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: notFoundMessage,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch attachments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedAttachments(attachments),
+	})
+}
+
+func (h *AttachmentHandler) upload(c *gin.Context, ownerType, ownerID, notFoundMessage string) {
+	// Leave headroom for the multipart envelope and the other form fields.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: service.ErrAttachmentTooLarge.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "File is required",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	input := service.AttachmentUploadInput{
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		FileName:  fileHeader.Filename,
+		Size:      fileHeader.Size,
+		Category:  c.PostForm("category"),
+		Content:   file,
+	}
+	if input.Category != "" && !isValidAttachmentCategory(input.Category) {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid attachment category",
+		})
+		return
+	}
+	if description := c.PostForm("description"); description != "" {
+		input.Description = &description
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	attachment, err := h.service.Upload(ctx, input)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: notFoundMessage,
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAttachmentTooLarge) || errors.Is(err, service.ErrAttachmentTypeNotAllowed) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to upload attachment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedAttachment(attachment),
+	})
+}
+
+func (h *AttachmentHandler) delete(c *gin.Context, ownerType, ownerID, attachmentID string) {
+	if err := h.service.Delete(c.Request.Context(), ownerType, ownerID, attachmentID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Attachment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to delete attachment",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AttachmentHandler) download(c *gin.Context, ownerType, ownerID, attachmentID string) {
+	attachment, reader, err := h.service.Open(c.Request.Context(), ownerType, ownerID, attachmentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound || errors.Is(err, storage.ErrObjectNotFound) {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Attachment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to download attachment",
+		})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", attachment.FileName),
+	})
+}
+
+func isValidAttachmentCategory(category string) bool {
+	switch generated.AttachmentCategory(category) {
+	case generated.AttachmentCategoryDocument,
+		generated.AttachmentCategoryLabResult,
+		generated.AttachmentCategoryPhoto,
+		generated.AttachmentCategoryOther:
+		return true
+	}
+	return false
+}
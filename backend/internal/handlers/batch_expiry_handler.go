@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BatchExpiryHandler struct {
+	service service.BatchExpiryService
+}
+
+func NewBatchExpiryHandler(service service.BatchExpiryService) *BatchExpiryHandler {
+	return &BatchExpiryHandler{service: service}
+}
+
+func (h *BatchExpiryHandler) RunBatchExpiry(c *gin.Context) {
+	run, err := h.service.Run(c.Request.Context())
+	if err != nil && run.ExpiredBatches == 0 && len(run.Alerts) == 0 {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to run batch expiry check",
+		})
+		return
+	}
+
+	// Partial failures still report the batches and alerts that were committed.
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedBatchExpiryRun(run),
+	})
+}
+
+func (h *BatchExpiryHandler) ListBatchExpiryAlerts(c *gin.Context, params generated.ListBatchExpiryAlertsParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	filter := repository.BatchExpiryAlertFilter{}
+	if params.MedicineId != nil {
+		filter.MedicineID = params.MedicineId.String()
+	}
+	if params.HorizonDays != nil {
+		filter.HorizonDays = *params.HorizonDays
+	}
+
+	alerts, total, err := h.service.ListAlerts(c.Request.Context(), page, perPage, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch expiry alerts",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedBatchExpiryAlerts(alerts),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
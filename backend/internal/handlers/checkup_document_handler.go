@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CheckupDocumentHandler struct {
+	service    service.CheckupDocumentService
+	clinicName string
+}
+
+func NewCheckupDocumentHandler(service service.CheckupDocumentService, clinicName string) *CheckupDocumentHandler {
+	return &CheckupDocumentHandler{service: service, clinicName: clinicName}
+}
+
+func (h *CheckupDocumentHandler) ListPatientCheckupDocuments(c *gin.Context, id generated.IdParam) {
+	documents, err := h.service.ListDocuments(c.Request.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch checkup documents",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedCheckupDocuments(documents),
+	})
+}
+
+func (h *CheckupDocumentHandler) IssuePatientCheckupDocument(c *gin.Context, id generated.IdParam) {
+	var req generated.IssueCheckupDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	input := service.IssueDocumentInput{
+		Type:     string(req.Type),
+		RestDays: req.RestDays,
+		RestFrom: mapper.DatePtrToTimePtr(req.RestFrom),
+	}
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+
+	document, pdf, err := h.service.IssueDocument(ctx, id, input)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidDocumentType) || errors.Is(err, service.ErrInvalidRestDays) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrCheckupNotPrintable) || errors.Is(err, service.ErrNoPrescription) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to print checkup document",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.pdf", document.Type, document.VerificationCode)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Header("X-Verification-Code", document.VerificationCode)
+	c.Data(http.StatusCreated, "application/pdf", pdf)
+}
+
+func (h *CheckupDocumentHandler) VerifyDocument(c *gin.Context, code generated.VerificationCodeParam) {
+	document, err := h.service.VerifyDocument(c.Request.Context(), code)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Document not found; it was not issued by this clinic",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to verify document",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedDocumentVerification(document, h.clinicName),
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"backend/internal/exports"
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CheckupExportHandler struct {
+	service service.CheckupExportService
+}
+
+func NewCheckupExportHandler(service service.CheckupExportService) *CheckupExportHandler {
+	return &CheckupExportHandler{service: service}
+}
+
+func (h *CheckupExportHandler) ExportPatientCheckups(c *gin.Context, params generated.ExportPatientCheckupsParams) {
+	format := exportFormat(params.Format)
+
+	filter := repository.PatientCheckupFilter{
+		VisitDate:     mapper.DatePtrToTimePtr(params.VisitDate),
+		VisitDateFrom: mapper.DatePtrToTimePtr(params.VisitDateFrom),
+		VisitDateTo:   mapper.DatePtrToTimePtr(params.VisitDateTo),
+		Fever:         params.Fever,
+		Hypoxia:       params.Hypoxia,
+	}
+	if params.Search != nil {
+		filter.Search = *params.Search
+	}
+	if params.PatientId != nil {
+		filter.PatientID = uuid.UUID(*params.PatientId).String()
+	}
+	if params.Status != nil {
+		filter.Status = string(*params.Status)
+	}
+	if params.BloodPressureCategory != nil {
+		filter.BloodPressureCategory = string(*params.BloodPressureCategory)
+	}
+	filter.AbnormalVitals = params.AbnormalVitals
+	if params.DiagnosisCode != nil {
+		filter.DiagnosisCode = *params.DiagnosisCode
+	}
+	if params.DoctorId != nil {
+		filter.DoctorID = params.DoctorId.String()
+	}
+
+	fileName := fmt.Sprintf("patient-checkups-%s.%s", time.Now().Format("20060102"), format)
+	h.stream(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportCheckups(c.Request.Context(), filter, format, w)
+	})
+}
+
+func (h *CheckupExportHandler) ExportPatientCheckupHistory(c *gin.Context, id generated.IdParam, params generated.ExportPatientCheckupHistoryParams) {
+	format := exportFormat(params.Format)
+
+	fileName := fmt.Sprintf("checkup-history-%s.%s", id, format)
+	h.stream(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportPatientHistory(c.Request.Context(), id, format, w)
+	})
+}
+
+func (h *CheckupExportHandler) stream(c *gin.Context, format, fileName string, export func(w io.Writer) error) {
+	err := streamExport(c, format, fileName, export)
+	if err == nil {
+		return
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, generated.Error{
+			Message: "Patient not found",
+		})
+		return
+	}
+	if errors.Is(err, exports.ErrUnknownFormat) {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, generated.Error{
+		Message: "Failed to export patient checkups",
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DisposalHandler struct {
+	service service.DisposalService
+}
+
+func NewDisposalHandler(service service.DisposalService) *DisposalHandler {
+	return &DisposalHandler{service: service}
+}
+
+func (h *DisposalHandler) ListDisposals(c *gin.Context, params generated.ListDisposalsParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	input := service.DisposalListInput{
+		DateFrom: mapper.DatePtrToTimePtr(params.DateFrom),
+		DateTo:   mapper.DatePtrToTimePtr(params.DateTo),
+	}
+	if params.Method != nil {
+		input.Method = string(*params.Method)
+	}
+
+	disposals, total, err := h.service.ListDisposals(c.Request.Context(), page, perPage, input)
+	if err != nil {
+		respondDisposalError(c, err, "Failed to fetch disposals")
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedDisposals(disposals),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *DisposalHandler) CreateDisposal(c *gin.Context) {
+	var req generated.CreateDisposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	items := make([]service.DisposalItemInput, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = service.DisposalItemInput{MedicineBatchID: item.MedicineBatchId}
+		if item.Reason != nil {
+			items[i].Reason = string(*item.Reason)
+		}
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	disposal, err := h.service.CreateDisposal(ctx, service.DisposalInput{
+		Method:            string(req.Method),
+		WitnessName:       req.WitnessName,
+		WitnessPosition:   req.WitnessPosition,
+		ReferenceDocument: req.ReferenceDocument,
+		Notes:             req.Notes,
+		DisposedAt:        req.DisposedAt,
+		Items:             items,
+	})
+	if err != nil {
+		respondDisposalError(c, err, "Failed to record disposal")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedDisposal(disposal),
+	})
+}
+
+func (h *DisposalHandler) GetDisposal(c *gin.Context, id generated.IdParam) {
+	disposal, err := h.service.GetDisposal(c.Request.Context(), id)
+	if err != nil {
+		respondDisposalError(c, err, "Failed to fetch disposal")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedDisposal(disposal),
+	})
+}
+
+func (h *DisposalHandler) PrintDisposalCertificate(c *gin.Context, id generated.IdParam) {
+	disposal, pdf, err := h.service.Certificate(c.Request.Context(), id)
+	if err != nil {
+		respondDisposalError(c, err, "Failed to print disposal certificate")
+		return
+	}
+
+	filename := strings.ReplaceAll(disposal.Number, "/", "-") + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func respondDisposalError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Disposal not found"})
+	case errors.Is(err, service.ErrInvalidDisposal):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrBatchNotDisposable):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"backend/internal/exports"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamExport runs export against the response. The file headers are only
+// sent with the first bytes of the file, so the error of an export that fails
+// before that is returned for the caller to answer. An export that fails
+// later ends the download short and nil is returned.
+func streamExport(c *gin.Context, format, fileName string, export func(w io.Writer) error) error {
+	response := &exportResponse{c: c, contentType: exports.ContentType(format), fileName: fileName}
+
+	err := export(response)
+	if err == nil || !response.started {
+		return err
+	}
+	log.Printf("Export %s failed after the download started: %v", fileName, err)
+	c.Abort()
+	return nil
+}
+
+type exportResponse struct {
+	c           *gin.Context
+	contentType string
+	fileName    string
+	started     bool
+}
+
+func (r *exportResponse) Write(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		r.c.Header("Content-Type", r.contentType)
+		r.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", r.fileName))
+		r.c.Status(http.StatusOK)
+	}
+	return r.c.Writer.Write(p)
+}
+
+// exportFormat returns the requested export format, xlsx by default.
+func exportFormat[T ~string](format *T) string {
+	if format == nil {
+		return exports.FormatXLSX
+	}
+	return string(*format)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FollowUpHandler struct {
+	service service.FollowUpService
+}
+
+func NewFollowUpHandler(service service.FollowUpService) *FollowUpHandler {
+	return &FollowUpHandler{service: service}
+}
+
+func (h *FollowUpHandler) ListFollowUps(c *gin.Context, params generated.ListFollowUpsParams) {
+	page := 1
+	perPage := 10
+
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	status := ""
+	if params.Status != nil {
+		status = string(*params.Status)
+	}
+	dueWithinDays := 0
+	if params.DueWithinDays != nil {
+		dueWithinDays = *params.DueWithinDays
+	}
+	patientID := ""
+	if params.PatientId != nil {
+		patientID = uuid.UUID(*params.PatientId).String()
+	}
+
+	today := h.service.Today()
+	checkups, total, err := h.service.ListFollowUps(c.Request.Context(), page, perPage, status, dueWithinDays, patientID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFollowUpFilter) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch follow-ups",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedFollowUps(checkups, today),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
@@ -9,16 +9,35 @@ import (
 type CombinedHandler struct {
 	*UserHandler
 	*PatientHandler
+	*PatientGuardianHandler
 	*PatientCheckupHandler
 	*AuthHandler
 	*MedicineHandler
 	*MedicineBatchHandler
 	*DashboardHandler
+	*AttachmentHandler
+	*RetentionHandler
+	*AppointmentHandler
+	*ScheduleHandler
+	*QueueHandler
+	*ICD10Handler
+	*ReportHandler
+	*FollowUpHandler
+	*CheckupDocumentHandler
+	*CheckupExportHandler
+	*StockAdjustmentHandler
+	*StockTakeHandler
+	*BatchExpiryHandler
+	*DisposalHandler
+	*SupplierHandler
+	*PurchaseOrderHandler
+	*ReorderHandler
 }
 
 func NewCombinedHandler(
 	userService service.UserService,
 	patientService service.PatientService,
+	patientGuardianService service.PatientGuardianService,
 	patientCheckupService service.PatientCheckupService,
 	authService service.AuthService,
 	medicineService service.MedicineService,
@@ -26,12 +45,13 @@ func NewCombinedHandler(
 	medicineStockActivityService service.MedicineStockActivityService,
 ) *CombinedHandler {
 	return &CombinedHandler{
-		UserHandler:           NewUserHandler(userService),
-		PatientHandler:        NewPatientHandler(patientService),
-		PatientCheckupHandler: NewPatientCheckupHandler(patientCheckupService),
-		AuthHandler:           NewAuthHandler(authService),
-		MedicineHandler:       NewMedicineHandler(medicineService, medicineStockActivityService),
-		MedicineBatchHandler:  NewMedicineBatchHandler(medicineBatchService),
+		UserHandler:            NewUserHandler(userService),
+		PatientHandler:         NewPatientHandler(patientService),
+		PatientGuardianHandler: NewPatientGuardianHandler(patientGuardianService),
+		PatientCheckupHandler:  NewPatientCheckupHandler(patientCheckupService),
+		AuthHandler:            NewAuthHandler(authService),
+		MedicineHandler:        NewMedicineHandler(medicineService, medicineStockActivityService),
+		MedicineBatchHandler:   NewMedicineBatchHandler(medicineBatchService),
 	}
 }
 
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ICD10Handler struct {
+	service service.ICD10Service
+}
+
+func NewICD10Handler(service service.ICD10Service) *ICD10Handler {
+	return &ICD10Handler{service: service}
+}
+
+func (h *ICD10Handler) ListICD10Codes(c *gin.Context, params generated.ListICD10CodesParams) {
+	page := 1
+	perPage := 10
+
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	filter := repository.ICD10CodeFilter{
+		IsActive: params.IsActive,
+	}
+	if params.Search != nil {
+		filter.Search = *params.Search
+	}
+
+	codes, total, err := h.service.ListCodes(c.Request.Context(), page, perPage, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch ICD-10 codes",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedICD10Codes(codes),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *ICD10Handler) GetICD10Code(c *gin.Context, code generated.ICD10CodeParam) {
+	icd10Code, err := h.service.GetCode(c.Request.Context(), code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "ICD-10 code not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch ICD-10 code",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedICD10Code(icd10Code),
+	})
+}
@@ -0,0 +1,67 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedAppointment(a *models.Appointment) generated.Appointment {
+	patientID, _ := uuid.Parse(a.PatientID)
+	doctorID, _ := uuid.Parse(a.DoctorID)
+
+	return generated.Appointment{
+		Id:                 openapi_types.UUID(a.ID),
+		PatientId:          openapi_types.UUID(patientID),
+		PatientName:        a.Patient.FullName,
+		DoctorId:           openapi_types.UUID(doctorID),
+		DoctorName:         a.Doctor.Name,
+		StartAt:            a.StartAt,
+		EndAt:              a.EndAt,
+		Status:             generated.AppointmentStatus(a.Status),
+		Reason:             a.Reason,
+		Notes:              a.Notes,
+		PatientCheckupId:   toUUIDPtr(a.PatientCheckupID),
+		CheckedInAt:        a.CheckedInAt,
+		CancelledAt:        a.CancelledAt,
+		CancellationReason: a.CancellationReason,
+		CreatedByUserId:    toUUIDPtr(a.CreatedByUserID),
+		CreatedAt:          a.CreatedAt,
+		UpdatedAt:          a.UpdatedAt,
+	}
+}
+
+func ToGeneratedAppointments(appointments []models.Appointment) []generated.Appointment {
+	result := make([]generated.Appointment, len(appointments))
+	for i := range appointments {
+		result[i] = ToGeneratedAppointment(&appointments[i])
+	}
+	return result
+}
+
+func ToModelAppointment(req generated.CreateAppointmentRequest) *models.Appointment {
+	return &models.Appointment{
+		PatientID: uuid.UUID(req.PatientId).String(),
+		DoctorID:  uuid.UUID(req.DoctorId).String(),
+		StartAt:   req.StartAt,
+		Reason:    req.Reason,
+		Notes:     req.Notes,
+	}
+}
+
+func ToGeneratedAvailabilitySlots(slots []models.AvailabilitySlot) []generated.AvailabilitySlot {
+	result := make([]generated.AvailabilitySlot, len(slots))
+	for i, s := range slots {
+		doctorID, _ := uuid.Parse(s.DoctorID)
+		result[i] = generated.AvailabilitySlot{
+			DoctorId:   openapi_types.UUID(doctorID),
+			DoctorName: s.DoctorName,
+			StartAt:    s.StartAt,
+			EndAt:      s.EndAt,
+			Available:  s.Available,
+		}
+	}
+	return result
+}
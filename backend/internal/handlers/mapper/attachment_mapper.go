@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedAttachment(a *models.Attachment) generated.Attachment {
+	ownerID, _ := uuid.Parse(a.OwnerID)
+	patientID, _ := uuid.Parse(a.PatientID)
+
+	result := generated.Attachment{
+		Id:          openapi_types.UUID(a.ID),
+		OwnerType:   generated.AttachmentOwnerType(a.OwnerType),
+		OwnerId:     openapi_types.UUID(ownerID),
+		PatientId:   openapi_types.UUID(patientID),
+		Category:    generated.AttachmentCategory(a.Category),
+		FileName:    a.FileName,
+		ContentType: generated.AttachmentContentType(a.ContentType),
+		SizeBytes:   a.SizeBytes,
+		Checksum:    a.Checksum,
+		Description: a.Description,
+		CreatedAt:   a.CreatedAt,
+	}
+
+	if a.UploadedByUserID != nil {
+		if parsed, err := uuid.Parse(*a.UploadedByUserID); err == nil {
+			userID := openapi_types.UUID(parsed)
+			result.UploadedByUserId = &userID
+		}
+	}
+
+	return result
+}
+
+func ToGeneratedAttachments(attachments []models.Attachment) []generated.Attachment {
+	result := make([]generated.Attachment, len(attachments))
+	for i := range attachments {
+		result[i] = ToGeneratedAttachment(&attachments[i])
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedBatchExpiryAlert(a *models.BatchExpiryAlert) generated.BatchExpiryAlert {
+	medicineID, _ := uuid.Parse(a.MedicineID)
+	batchID, _ := uuid.Parse(a.MedicineBatchID)
+	return generated.BatchExpiryAlert{
+		Id:              openapi_types.UUID(a.ID),
+		MedicineId:      openapi_types.UUID(medicineID),
+		MedicineCode:    a.Medicine.Code,
+		MedicineName:    a.Medicine.Name,
+		MedicineBatchId: openapi_types.UUID(batchID),
+		BatchNumber:     a.MedicineBatch.BatchNumber,
+		Unit:            a.MedicineBatch.Unit,
+		HorizonDays:     a.HorizonDays,
+		ExpirationDate:  openapi_types.Date{Time: a.ExpirationDate},
+		DaysLeft:        a.DaysLeft,
+		Quantity:        a.Quantity,
+		CreatedAt:       a.CreatedAt,
+	}
+}
+
+func ToGeneratedBatchExpiryAlerts(alerts []models.BatchExpiryAlert) []generated.BatchExpiryAlert {
+	result := make([]generated.BatchExpiryAlert, len(alerts))
+	for i := range alerts {
+		result[i] = ToGeneratedBatchExpiryAlert(&alerts[i])
+	}
+	return result
+}
+
+func ToGeneratedBatchExpiryRun(r *models.BatchExpiryRun) generated.BatchExpiryRun {
+	return generated.BatchExpiryRun{
+		ExpiredBatches:  r.ExpiredBatches,
+		ExpiredQuantity: r.ExpiredQuantity,
+		Alerts:          ToGeneratedBatchExpiryAlerts(r.Alerts),
+	}
+}
@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedCheckupDocuments(documents []models.CheckupDocument) []generated.CheckupDocument {
+	result := make([]generated.CheckupDocument, len(documents))
+	for i, d := range documents {
+		checkupID, _ := uuid.Parse(d.PatientCheckupID)
+		result[i] = generated.CheckupDocument{
+			Id:               openapi_types.UUID(d.ID),
+			PatientCheckupId: openapi_types.UUID(checkupID),
+			Type:             generated.CheckupDocumentType(d.Type),
+			VerificationCode: d.VerificationCode,
+			RestDays:         d.RestDays,
+			RestFrom:         toDatePtr(d.RestFrom),
+			RestTo:           toDatePtr(d.RestTo),
+			IssuedByUserId:   toUUIDPtr(d.IssuedByUserID),
+			CreatedAt:        d.CreatedAt,
+		}
+	}
+	return result
+}
+
+// ToGeneratedDocumentVerification exposes only what confirms the document;
+// the patient name is masked and no clinical details are included.
+func ToGeneratedDocumentVerification(d *models.CheckupDocument, clinicName string) generated.DocumentVerification {
+	return generated.DocumentVerification{
+		Valid:       true,
+		Type:        generated.DocumentVerificationType(d.Type),
+		IssuedAt:    d.CreatedAt,
+		ClinicName:  clinicName,
+		PatientName: maskName(d.PatientCheckup.Patient.FullName),
+		DoctorName:  d.PatientCheckup.DoctorName,
+		VisitDate:   d.PatientCheckup.VisitDate,
+		RestDays:    d.RestDays,
+		RestFrom:    toDatePtr(d.RestFrom),
+		RestTo:      toDatePtr(d.RestTo),
+	}
+}
+
+func toDatePtr(value *time.Time) *openapi_types.Date {
+	if value == nil {
+		return nil
+	}
+	return &openapi_types.Date{Time: *value}
+}
+
+// maskName keeps the first letter of every word, e.g. "Budi Santoso"
+// becomes "B*** S******".
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,66 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedDisposal(d *models.Disposal) generated.Disposal {
+	var disposedByName *string
+	if d.DisposedBy != nil {
+		disposedByName = &d.DisposedBy.Name
+	}
+
+	items := make([]generated.DisposalItem, len(d.Items))
+	totalQuantity := 0
+	for i := range d.Items {
+		items[i] = ToGeneratedDisposalItem(&d.Items[i])
+		totalQuantity += d.Items[i].Quantity
+	}
+
+	return generated.Disposal{
+		Id:                openapi_types.UUID(d.ID),
+		Number:            d.Number,
+		Method:            generated.DisposalMethod(d.Method),
+		WitnessName:       d.WitnessName,
+		WitnessPosition:   d.WitnessPosition,
+		ReferenceDocument: d.ReferenceDocument,
+		Notes:             d.Notes,
+		DisposedAt:        d.DisposedAt,
+		DisposedByUserId:  toUUIDPtr(d.DisposedByUserID),
+		DisposedByName:    disposedByName,
+		TotalQuantity:     totalQuantity,
+		Items:             items,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+	}
+}
+
+func ToGeneratedDisposals(disposals []models.Disposal) []generated.Disposal {
+	result := make([]generated.Disposal, len(disposals))
+	for i := range disposals {
+		result[i] = ToGeneratedDisposal(&disposals[i])
+	}
+	return result
+}
+
+func ToGeneratedDisposalItem(item *models.DisposalItem) generated.DisposalItem {
+	medicineID, _ := uuid.Parse(item.MedicineID)
+	batchID, _ := uuid.Parse(item.MedicineBatchID)
+	return generated.DisposalItem{
+		Id:              openapi_types.UUID(item.ID),
+		MedicineId:      openapi_types.UUID(medicineID),
+		MedicineCode:    item.Medicine.Code,
+		MedicineName:    item.Medicine.Name,
+		MedicineBatchId: openapi_types.UUID(batchID),
+		BatchNumber:     item.MedicineBatch.BatchNumber,
+		ExpirationDate:  openapi_types.Date{Time: item.MedicineBatch.ExpirationDate},
+		Unit:            item.MedicineBatch.Unit,
+		Reason:          generated.DisposalItemReason(item.Reason),
+		Quantity:        item.Quantity,
+		UnitCost:        item.UnitCost,
+	}
+}
@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// ToGeneratedFollowUps maps open follow-ups; today is the clinic date their
+// status is judged against.
+func ToGeneratedFollowUps(checkups []models.PatientCheckup, today time.Time) []generated.FollowUp {
+	result := make([]generated.FollowUp, len(checkups))
+	for i, c := range checkups {
+		patientID, _ := uuid.Parse(c.PatientID)
+		followUpDate := time.Date(c.FollowUpDate.Year(), c.FollowUpDate.Month(), c.FollowUpDate.Day(), 0, 0, 0, 0, time.UTC)
+
+		result[i] = generated.FollowUp{
+			PatientCheckupId: openapi_types.UUID(c.ID),
+			PatientId:        openapi_types.UUID(patientID),
+			PatientName:      c.Patient.FullName,
+			FollowUpDate:     openapi_types.Date{Time: followUpDate},
+			Status:           generated.FollowUpStatusDue,
+			VisitDate:        c.VisitDate,
+			Diagnosis:        c.Diagnosis,
+			DoctorName:       c.DoctorName,
+			ReminderSentAt:   c.FollowUpReminderSentAt,
+		}
+		if c.Patient.PhoneNumber != "" {
+			phone := c.Patient.PhoneNumber
+			result[i].PatientPhoneNumber = &phone
+		}
+		if followUpDate.Before(today) {
+			result[i].Status = generated.FollowUpStatusOverdue
+			result[i].DaysOverdue = int(today.Sub(followUpDate).Hours() / 24)
+		}
+	}
+	return result
+}
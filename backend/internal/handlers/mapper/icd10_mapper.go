@@ -0,0 +1,23 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+)
+
+func ToGeneratedICD10Code(c *models.ICD10Code) generated.ICD10Code {
+	return generated.ICD10Code{
+		Code:     c.Code,
+		NameId:   c.NameID,
+		NameEn:   c.NameEN,
+		IsActive: c.IsActive,
+	}
+}
+
+func ToGeneratedICD10Codes(codes []models.ICD10Code) []generated.ICD10Code {
+	result := make([]generated.ICD10Code, len(codes))
+	for i := range codes {
+		result[i] = ToGeneratedICD10Code(&codes[i])
+	}
+	return result
+}
@@ -46,8 +46,26 @@ func ToModelMedicineBatchUpdate(req generated.UpdateMedicineBatchRequest) *model
 	return &models.MedicineBatch{
 		BatchNumber:    req.BatchNumber,
 		ExpirationDate: req.ExpirationDate.Time,
-		Quantity:       req.Stock,
 		Unit:           req.Unit,
-		Status:         "active",
 	}
 }
+
+func ToGeneratedBatchRecipients(recipients []models.BatchRecipient) []generated.BatchRecipient {
+	result := make([]generated.BatchRecipient, len(recipients))
+	for i, r := range recipients {
+		allocationID, _ := uuid.Parse(r.AllocationID)
+		checkupID, _ := uuid.Parse(r.PatientCheckupID)
+		patientID, _ := uuid.Parse(r.PatientID)
+		result[i] = generated.BatchRecipient{
+			AllocationId:     openapi_types.UUID(allocationID),
+			PatientCheckupId: openapi_types.UUID(checkupID),
+			PatientId:        openapi_types.UUID(patientID),
+			PatientName:      r.PatientName,
+			VisitDate:        r.VisitDate,
+			Quantity:         r.Quantity,
+			ReturnedQuantity: r.ReturnedQuantity,
+			DispensedAt:      r.DispensedAt,
+		}
+	}
+	return result
+}
@@ -18,6 +18,7 @@ func ToGeneratedMedicine(m *models.Medicine) generated.Medicine {
 		Name:                   m.Name,
 		Code:                   m.Code,
 		CurrentStock:           m.CurrentStock,
+		ExpiredStock:           m.ExpiredStock,
 		MinimumStock:           m.MinimumStock,
 		DosageForm:             generated.MedicineDosageForm(m.DosageForm),
 		Strength:               strength,
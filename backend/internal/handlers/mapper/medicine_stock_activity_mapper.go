@@ -18,9 +18,11 @@ func ToGeneratedMedicineStockActivity(a *models.MedicineStockActivity) generated
 		QuantityDelta:   a.QuantityDelta,
 		StockBefore:     a.StockBefore,
 		StockAfter:      a.StockAfter,
+		ExpiredDelta:    a.ExpiredDelta,
 		Notes:           a.Notes,
 		CreatedAt:       a.CreatedAt,
 		CreatedByUserId: nil,
+		ReasonCode:      a.ReasonCode,
 	}
 
 	if a.MedicineBatchID != nil {
@@ -35,6 +37,24 @@ func ToGeneratedMedicineStockActivity(a *models.MedicineStockActivity) generated
 			result.PatientCheckupId = &checkupID
 		}
 	}
+	if a.StockAdjustmentID != nil {
+		if parsed, err := uuid.Parse(*a.StockAdjustmentID); err == nil {
+			adjustmentID := openapi_types.UUID(parsed)
+			result.StockAdjustmentId = &adjustmentID
+		}
+	}
+	if a.DisposalID != nil {
+		if parsed, err := uuid.Parse(*a.DisposalID); err == nil {
+			disposalID := openapi_types.UUID(parsed)
+			result.DisposalId = &disposalID
+		}
+	}
+	if a.GoodsReceiptID != nil {
+		if parsed, err := uuid.Parse(*a.GoodsReceiptID); err == nil {
+			receiptID := openapi_types.UUID(parsed)
+			result.GoodsReceiptId = &receiptID
+		}
+	}
 	if a.CreatedByUserID != nil {
 		if parsed, err := uuid.Parse(*a.CreatedByUserID); err == nil {
 			userID := openapi_types.UUID(parsed)
@@ -52,3 +72,42 @@ func ToGeneratedMedicineStockActivities(activities []models.MedicineStockActivit
 	}
 	return result
 }
+
+func ToGeneratedStockLedgerEntry(e *models.StockLedgerEntry) generated.StockLedgerEntry {
+	id, _ := uuid.Parse(e.ID)
+	medicineID, _ := uuid.Parse(e.MedicineID)
+	return generated.StockLedgerEntry{
+		Id:                openapi_types.UUID(id),
+		MedicineId:        openapi_types.UUID(medicineID),
+		MedicineCode:      e.MedicineCode,
+		MedicineName:      e.MedicineName,
+		MedicineBatchId:   toUUIDPtr(e.MedicineBatchID),
+		BatchNumber:       e.BatchNumber,
+		PatientCheckupId:  toUUIDPtr(e.PatientCheckupID),
+		StockAdjustmentId: toUUIDPtr(e.StockAdjustmentID),
+		DisposalId:        toUUIDPtr(e.DisposalID),
+		GoodsReceiptId:    toUUIDPtr(e.GoodsReceiptID),
+		ReasonCode:        e.ReasonCode,
+		ChangeType:        generated.StockLedgerEntryChangeType(e.ChangeType),
+		Source:            e.Source,
+		QuantityDelta:     e.QuantityDelta,
+		StockBefore:       e.StockBefore,
+		StockAfter:        e.StockAfter,
+		ExpiredDelta:      e.ExpiredDelta,
+		Notes:             e.Notes,
+		CreatedByUserId:   toUUIDPtr(e.CreatedByUserID),
+		CreatedByName:     e.CreatedByName,
+		CreatedAt:         e.CreatedAt,
+		RunningIncrease:   e.RunningIncrease,
+		RunningDecrease:   e.RunningDecrease,
+		RunningNet:        e.RunningNet,
+	}
+}
+
+func ToGeneratedStockLedgerEntries(entries []models.StockLedgerEntry) []generated.StockLedgerEntry {
+	result := make([]generated.StockLedgerEntry, len(entries))
+	for i := range entries {
+		result[i] = ToGeneratedStockLedgerEntry(&entries[i])
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package mapper
 import (
 	"backend/internal/generated"
 	"backend/internal/models"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,22 +14,33 @@ func ToGeneratedPatientCheckup(c *models.PatientCheckup) generated.PatientChecku
 	patientID, _ := uuid.Parse(c.PatientID)
 
 	result := generated.PatientCheckup{
-		Id:               openapi_types.UUID(c.ID),
-		PatientId:        openapi_types.UUID(patientID),
-		VisitDate:        c.VisitDate,
-		Status:           generated.PatientCheckupStatus(c.Status),
-		ChiefComplaint:   c.ChiefComplaint,
-		Symptoms:         c.Symptoms,
-		Diagnosis:        c.Diagnosis,
-		BloodPressure:    c.BloodPressure,
-		HeartRate:        c.HeartRate,
-		RespiratoryRate:  c.RespiratoryRate,
-		OxygenSaturation: c.OxygenSaturation,
-		TreatmentPlan:    c.TreatmentPlan,
-		Notes:            c.Notes,
-		DoctorName:       c.DoctorName,
-		CreatedAt:        c.CreatedAt,
-		UpdatedAt:        c.UpdatedAt,
+		Id:                     openapi_types.UUID(c.ID),
+		PatientId:              openapi_types.UUID(patientID),
+		VisitDate:              c.VisitDate,
+		Status:                 generated.PatientCheckupStatus(c.Status),
+		ChiefComplaint:         c.ChiefComplaint,
+		Symptoms:               c.Symptoms,
+		Diagnosis:              c.Diagnosis,
+		Diagnoses:              ToGeneratedPatientCheckupDiagnoses(c.Diagnoses),
+		BloodPressureSystolic:  c.BloodPressureSystolic,
+		BloodPressureDiastolic: c.BloodPressureDiastolic,
+		BloodPressure:          models.FormatBloodPressure(c.BloodPressureSystolic, c.BloodPressureDiastolic),
+		HeartRate:              c.HeartRate,
+		RespiratoryRate:        c.RespiratoryRate,
+		OxygenSaturation:       c.OxygenSaturation,
+		Fever:                  &c.Fever,
+		Hypoxia:                &c.Hypoxia,
+		TreatmentPlan:          c.TreatmentPlan,
+		Notes:                  c.Notes,
+		DoctorId:               toUUIDPtr(c.DoctorID),
+		DoctorName:             c.DoctorName,
+		CreatedAt:              c.CreatedAt,
+		UpdatedAt:              c.UpdatedAt,
+	}
+
+	if c.BloodPressureCategory != nil {
+		category := generated.PatientCheckupBloodPressureCategory(*c.BloodPressureCategory)
+		result.BloodPressureCategory = &category
 	}
 
 	if c.TemperatureC != nil {
@@ -51,6 +63,12 @@ func ToGeneratedPatientCheckup(c *models.PatientCheckup) generated.PatientChecku
 		medicines := ToGeneratedPatientCheckupMedicines(c.Medicines)
 		result.Medicines = &medicines
 	}
+	result.StartedAt = c.StartedAt
+	result.CompletedAt = c.CompletedAt
+	result.StatusChangedAt = c.StatusChangedAt
+	result.StatusChangedByUserId = toUUIDPtr(c.StatusChangedByUserID)
+	result.DispensedAt = c.DispensedAt
+	result.DispensedByUserId = toUUIDPtr(c.DispensedByUserID)
 
 	return result
 }
@@ -65,14 +83,16 @@ func ToGeneratedPatientCheckups(checkups []models.PatientCheckup) []generated.Pa
 
 func ToGeneratedPatientCheckupMedicine(m models.PatientCheckupMedicine) generated.PatientCheckupMedicine {
 	medicineID, _ := uuid.Parse(m.MedicineID)
+	dispensedQuantity := m.DispensedQuantity
 	return generated.PatientCheckupMedicine{
-		MedicineId:   openapi_types.UUID(medicineID),
-		MedicineName: m.MedicineName,
-		Quantity:     m.Quantity,
-		Dosage:       m.Dosage,
-		Frequency:    m.Frequency,
-		DurationDays: m.DurationDays,
-		Notes:        m.Notes,
+		MedicineId:        openapi_types.UUID(medicineID),
+		MedicineName:      m.MedicineName,
+		Quantity:          m.Quantity,
+		DispensedQuantity: &dispensedQuantity,
+		Dosage:            m.Dosage,
+		Frequency:         m.Frequency,
+		DurationDays:      m.DurationDays,
+		Notes:             m.Notes,
 	}
 }
 
@@ -106,19 +126,20 @@ func toModelPatientCheckupMedicines(medicines []generated.PatientCheckupMedicine
 
 func ToModelCreatePatientCheckup(req generated.CreatePatientCheckupRequest) *models.PatientCheckup {
 	checkup := &models.PatientCheckup{
-		PatientID:        uuid.UUID(req.PatientId).String(),
-		VisitDate:        req.VisitDate,
-		Status:           "scheduled",
-		ChiefComplaint:   req.ChiefComplaint,
-		Symptoms:         req.Symptoms,
-		Diagnosis:        req.Diagnosis,
-		BloodPressure:    req.BloodPressure,
-		HeartRate:        req.HeartRate,
-		RespiratoryRate:  req.RespiratoryRate,
-		OxygenSaturation: req.OxygenSaturation,
-		TreatmentPlan:    req.TreatmentPlan,
-		Notes:            req.Notes,
-		DoctorName:       req.DoctorName,
+		PatientID:              uuid.UUID(req.PatientId).String(),
+		VisitDate:              req.VisitDate,
+		Status:                 "scheduled",
+		ChiefComplaint:         req.ChiefComplaint,
+		Symptoms:               req.Symptoms,
+		Diagnosis:              req.Diagnosis,
+		BloodPressureSystolic:  req.BloodPressureSystolic,
+		BloodPressureDiastolic: req.BloodPressureDiastolic,
+		HeartRate:              req.HeartRate,
+		RespiratoryRate:        req.RespiratoryRate,
+		OxygenSaturation:       req.OxygenSaturation,
+		TreatmentPlan:          req.TreatmentPlan,
+		Notes:                  req.Notes,
+		DoctorName:             req.DoctorName,
 	}
 
 	if req.Status != nil {
@@ -136,6 +157,10 @@ func ToModelCreatePatientCheckup(req generated.CreatePatientCheckupRequest) *mod
 		v := float64(*req.WeightKg)
 		checkup.WeightKg = &v
 	}
+	if req.DoctorId != nil {
+		doctorID := req.DoctorId.String()
+		checkup.DoctorID = &doctorID
+	}
 	if req.FollowUpDate != nil {
 		t := req.FollowUpDate.Time
 		checkup.FollowUpDate = &t
@@ -143,26 +168,30 @@ func ToModelCreatePatientCheckup(req generated.CreatePatientCheckupRequest) *mod
 	if req.Medicines != nil {
 		checkup.Medicines = toModelPatientCheckupMedicines(*req.Medicines)
 	}
+	checkup.Diagnoses = toModelPatientCheckupDiagnoses(req.PrimaryDiagnosisCode, req.SecondaryDiagnosisCodes)
 
 	return checkup
 }
 
 func ToModelUpdatePatientCheckup(req generated.UpdatePatientCheckupRequest) *models.PatientCheckup {
 	checkup := &models.PatientCheckup{
-		VisitDate:        req.VisitDate,
-		Status:           string(req.Status),
-		ChiefComplaint:   req.ChiefComplaint,
-		Symptoms:         req.Symptoms,
-		Diagnosis:        req.Diagnosis,
-		BloodPressure:    req.BloodPressure,
-		HeartRate:        req.HeartRate,
-		RespiratoryRate:  req.RespiratoryRate,
-		OxygenSaturation: req.OxygenSaturation,
-		TreatmentPlan:    req.TreatmentPlan,
-		Notes:            req.Notes,
-		DoctorName:       req.DoctorName,
+		VisitDate:              req.VisitDate,
+		ChiefComplaint:         req.ChiefComplaint,
+		Symptoms:               req.Symptoms,
+		Diagnosis:              req.Diagnosis,
+		BloodPressureSystolic:  req.BloodPressureSystolic,
+		BloodPressureDiastolic: req.BloodPressureDiastolic,
+		HeartRate:              req.HeartRate,
+		RespiratoryRate:        req.RespiratoryRate,
+		OxygenSaturation:       req.OxygenSaturation,
+		TreatmentPlan:          req.TreatmentPlan,
+		Notes:                  req.Notes,
+		DoctorName:             req.DoctorName,
 	}
 
+	if req.Status != nil {
+		checkup.Status = string(*req.Status)
+	}
 	if req.TemperatureC != nil {
 		v := float64(*req.TemperatureC)
 		checkup.TemperatureC = &v
@@ -175,6 +204,10 @@ func ToModelUpdatePatientCheckup(req generated.UpdatePatientCheckupRequest) *mod
 		v := float64(*req.WeightKg)
 		checkup.WeightKg = &v
 	}
+	if req.DoctorId != nil {
+		doctorID := req.DoctorId.String()
+		checkup.DoctorID = &doctorID
+	}
 	if req.FollowUpDate != nil {
 		t := req.FollowUpDate.Time
 		checkup.FollowUpDate = &t
@@ -182,10 +215,81 @@ func ToModelUpdatePatientCheckup(req generated.UpdatePatientCheckupRequest) *mod
 	if req.Medicines != nil {
 		checkup.Medicines = toModelPatientCheckupMedicines(*req.Medicines)
 	}
+	checkup.Diagnoses = toModelPatientCheckupDiagnoses(req.PrimaryDiagnosisCode, req.SecondaryDiagnosisCodes)
 
 	return checkup
 }
 
+func ToGeneratedPatientCheckupDiagnoses(diagnoses []models.PatientCheckupDiagnosis) []generated.PatientCheckupDiagnosis {
+	result := make([]generated.PatientCheckupDiagnosis, len(diagnoses))
+	for i, d := range diagnoses {
+		result[i] = generated.PatientCheckupDiagnosis{
+			Code:   d.Code,
+			Type:   generated.PatientCheckupDiagnosisType(d.Type),
+			NameId: d.ICD10Code.NameID,
+			NameEn: d.ICD10Code.NameEN,
+		}
+	}
+	return result
+}
+
+// toModelPatientCheckupDiagnoses returns nil when the request carries no
+// diagnosis codes at all, which leaves stored diagnoses untouched on update.
+func toModelPatientCheckupDiagnoses(primary *string, secondary *[]string) []models.PatientCheckupDiagnosis {
+	if primary == nil && secondary == nil {
+		return nil
+	}
+
+	diagnoses := []models.PatientCheckupDiagnosis{}
+	if primary != nil && strings.TrimSpace(*primary) != "" {
+		diagnoses = append(diagnoses, models.PatientCheckupDiagnosis{
+			Code: *primary,
+			Type: models.DiagnosisTypePrimary,
+		})
+	}
+	if secondary != nil {
+		for _, code := range *secondary {
+			diagnoses = append(diagnoses, models.PatientCheckupDiagnosis{
+				Code: code,
+				Type: models.DiagnosisTypeSecondary,
+			})
+		}
+	}
+	return diagnoses
+}
+
+func ToGeneratedPatientCheckupTransitions(transitions []models.PatientCheckupTransition) []generated.PatientCheckupTransition {
+	result := make([]generated.PatientCheckupTransition, len(transitions))
+	for i, t := range transitions {
+		checkupID, _ := uuid.Parse(t.PatientCheckupID)
+		result[i] = generated.PatientCheckupTransition{
+			Id:               openapi_types.UUID(t.ID),
+			PatientCheckupId: openapi_types.UUID(checkupID),
+			ToStatus:         generated.PatientCheckupTransitionToStatus(t.ToStatus),
+			Reason:           t.Reason,
+			ChangedByUserId:  toUUIDPtr(t.ChangedByUserID),
+			CreatedAt:        t.CreatedAt,
+		}
+		if t.FromStatus != nil {
+			from := generated.PatientCheckupTransitionFromStatus(*t.FromStatus)
+			result[i].FromStatus = &from
+		}
+	}
+	return result
+}
+
+func toUUIDPtr(value *string) *openapi_types.UUID {
+	if value == nil {
+		return nil
+	}
+	parsed, err := uuid.Parse(*value)
+	if err != nil {
+		return nil
+	}
+	id := openapi_types.UUID(parsed)
+	return &id
+}
+
 func DatePtrToTimePtr(date *openapi_types.Date) *time.Time {
 	if date == nil {
 		return nil
@@ -0,0 +1,109 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedPatientCheckupRevision(r *models.PatientCheckupRevision) generated.PatientCheckupRevision {
+	checkupID, _ := uuid.Parse(r.PatientCheckupID)
+
+	changes := make([]generated.PatientCheckupRevisionChange, len(r.Changes))
+	for i, change := range r.Changes {
+		changes[i] = generated.PatientCheckupRevisionChange{
+			Field: change.Field,
+			From:  decodeChangeValue(change.From),
+			To:    decodeChangeValue(change.To),
+		}
+	}
+
+	return generated.PatientCheckupRevision{
+		Id:               openapi_types.UUID(r.ID),
+		PatientCheckupId: openapi_types.UUID(checkupID),
+		Revision:         r.Revision,
+		Reason:           r.Reason,
+		ChangedByUserId:  toUUIDPtr(r.ChangedByUserID),
+		Changes:          changes,
+		CreatedAt:        r.CreatedAt,
+	}
+}
+
+// ToGeneratedPatientCheckupRevisions maps a revision list, which is loaded
+// without snapshots.
+func ToGeneratedPatientCheckupRevisions(revisions []models.PatientCheckupRevision) []generated.PatientCheckupRevision {
+	result := make([]generated.PatientCheckupRevision, len(revisions))
+	for i := range revisions {
+		result[i] = ToGeneratedPatientCheckupRevision(&revisions[i])
+	}
+	return result
+}
+
+// ToGeneratedPatientCheckupRevisionWithSnapshot maps a revision together with
+// the record as it was at that revision.
+func ToGeneratedPatientCheckupRevisionWithSnapshot(r *models.PatientCheckupRevision) generated.PatientCheckupRevision {
+	result := ToGeneratedPatientCheckupRevision(r)
+	snapshot := ToGeneratedPatientCheckupSnapshot(r.Snapshot)
+	result.Snapshot = &snapshot
+	return result
+}
+
+func ToGeneratedPatientCheckupSnapshot(s models.PatientCheckupSnapshot) generated.PatientCheckupSnapshot {
+	symptoms := s.Symptoms
+	if symptoms == nil {
+		symptoms = []string{}
+	}
+
+	diagnoses := make([]generated.PatientCheckupSnapshotDiagnosis, len(s.Diagnoses))
+	for i, d := range s.Diagnoses {
+		diagnoses[i] = generated.PatientCheckupSnapshotDiagnosis{
+			Code: d.Code,
+			Type: generated.PatientCheckupSnapshotDiagnosisType(d.Type),
+		}
+	}
+
+	result := generated.PatientCheckupSnapshot{
+		VisitDate:              s.VisitDate,
+		Status:                 generated.PatientCheckupSnapshotStatus(s.Status),
+		ChiefComplaint:         s.ChiefComplaint,
+		Symptoms:               symptoms,
+		Diagnosis:              s.Diagnosis,
+		Diagnoses:              diagnoses,
+		TemperatureC:           toFloat32Ptr(s.TemperatureC),
+		BloodPressureSystolic:  s.BloodPressureSystolic,
+		BloodPressureDiastolic: s.BloodPressureDiastolic,
+		HeartRate:              s.HeartRate,
+		RespiratoryRate:        s.RespiratoryRate,
+		OxygenSaturation:       s.OxygenSaturation,
+		HeightCm:               toFloat32Ptr(s.HeightCm),
+		WeightKg:               toFloat32Ptr(s.WeightKg),
+		Medicines:              ToGeneratedPatientCheckupMedicines(s.Medicines),
+		TreatmentPlan:          s.TreatmentPlan,
+		Notes:                  s.Notes,
+		DoctorId:               toUUIDPtr(s.DoctorID),
+		DoctorName:             s.DoctorName,
+		StartedAt:              s.StartedAt,
+		CompletedAt:            s.CompletedAt,
+		DispensedAt:            s.DispensedAt,
+	}
+	if s.FollowUpDate != nil {
+		d := openapi_types.Date{Time: *s.FollowUpDate}
+		result.FollowUpDate = &d
+	}
+
+	return result
+}
+
+func decodeChangeValue(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+	return value
+}
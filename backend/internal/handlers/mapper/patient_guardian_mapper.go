@@ -0,0 +1,74 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedPatientGuardian(g *models.PatientGuardian) generated.PatientGuardian {
+	patientID, _ := uuid.Parse(g.PatientID)
+	return generated.PatientGuardian{
+		Id:                  openapi_types.UUID(g.ID),
+		PatientId:           openapi_types.UUID(patientID),
+		FullName:            g.FullName,
+		Relationship:        generated.PatientGuardianRelationship(g.Relationship),
+		PhoneNumber:         g.PhoneNumber,
+		Email:               castToEmail(g.Email),
+		Address:             g.Address,
+		IsPrimary:           g.IsPrimary,
+		CanBeNotified:       g.CanBeNotified,
+		CanConsentTreatment: g.CanConsentTreatment,
+		Notes:               g.Notes,
+		CreatedAt:           g.CreatedAt,
+		UpdatedAt:           g.UpdatedAt,
+	}
+}
+
+func ToGeneratedPatientGuardians(guardians []models.PatientGuardian) []generated.PatientGuardian {
+	result := make([]generated.PatientGuardian, len(guardians))
+	for i := range guardians {
+		result[i] = ToGeneratedPatientGuardian(&guardians[i])
+	}
+	return result
+}
+
+func ToModelCreatePatientGuardian(req generated.CreatePatientGuardianRequest) *models.PatientGuardian {
+	guardian := &models.PatientGuardian{
+		FullName:      req.FullName,
+		Relationship:  string(req.Relationship),
+		PhoneNumber:   req.PhoneNumber,
+		Email:         castEmailToString(req.Email),
+		Address:       req.Address,
+		CanBeNotified: true,
+		Notes:         req.Notes,
+	}
+
+	if req.IsPrimary != nil {
+		guardian.IsPrimary = *req.IsPrimary
+	}
+	if req.CanBeNotified != nil {
+		guardian.CanBeNotified = *req.CanBeNotified
+	}
+	if req.CanConsentTreatment != nil {
+		guardian.CanConsentTreatment = *req.CanConsentTreatment
+	}
+
+	return guardian
+}
+
+func ToModelUpdatePatientGuardian(req generated.UpdatePatientGuardianRequest) *models.PatientGuardian {
+	return &models.PatientGuardian{
+		FullName:            req.FullName,
+		Relationship:        string(req.Relationship),
+		PhoneNumber:         req.PhoneNumber,
+		Email:               castEmailToString(req.Email),
+		Address:             req.Address,
+		IsPrimary:           req.IsPrimary,
+		CanBeNotified:       req.CanBeNotified,
+		CanConsentTreatment: req.CanConsentTreatment,
+		Notes:               req.Notes,
+	}
+}
@@ -9,23 +9,30 @@ import (
 )
 
 func ToGeneratedPatient(patient *models.Patient) generated.Patient {
-	return generated.Patient{
-		Id:                    openapi_types.UUID(patient.ID),
-		FullName:              patient.FullName,
-		DateOfBirth:           parseDate(patient.DateOfBirth),
-		Gender:                generated.PatientGender(patient.Gender),
-		PatientType:           generated.PatientPatientType(patient.PatientType),
-		PhoneNumber:           patient.PhoneNumber,
-		Email:                 castToEmail(patient.Email),
-		Address:               patient.Address,
-		MedicalRecordNumber:   patient.MedicalRecordNumber,
-		EmergencyContactName:  patient.EmergencyContactName,
-		EmergencyContactPhone: patient.EmergencyContactPhone,
-		BloodType:             castToBloodType(patient.BloodType),
-		Allergies:             patient.Allergies,
-		CreatedAt:             &patient.CreatedAt,
-		UpdatedAt:             &patient.UpdatedAt,
+	result := generated.Patient{
+		Id:                  openapi_types.UUID(patient.ID),
+		FullName:            patient.FullName,
+		DateOfBirth:         parseDate(patient.DateOfBirth),
+		Gender:              generated.PatientGender(patient.Gender),
+		PatientType:         generated.PatientPatientType(patient.PatientType),
+		Hostel:              patient.Hostel,
+		PhoneNumber:         patient.PhoneNumber,
+		Email:               castToEmail(patient.Email),
+		Address:             patient.Address,
+		MedicalRecordNumber: patient.MedicalRecordNumber,
+		BloodType:           castToBloodType(patient.BloodType),
+		Allergies:           patient.Allergies,
+		AnonymizedAt:        patient.AnonymizedAt,
+		CreatedAt:           &patient.CreatedAt,
+		UpdatedAt:           &patient.UpdatedAt,
 	}
+
+	if guardian := patient.PrimaryGuardian(); guardian != nil {
+		result.EmergencyContactName = &guardian.FullName
+		result.EmergencyContactPhone = &guardian.PhoneNumber
+	}
+
+	return result
 }
 
 func ToGeneratedPatients(patients []models.Patient) []generated.Patient {
@@ -37,20 +44,34 @@ func ToGeneratedPatients(patients []models.Patient) []generated.Patient {
 }
 
 func ToModelPatient(req generated.CreatePatientRequest) *models.Patient {
-	return &models.Patient{
-		FullName:              req.FullName,
-		DateOfBirth:           req.DateOfBirth.Format("2006-01-02"),
-		Gender:                string(req.Gender),
-		PatientType:           string(req.PatientType),
-		PhoneNumber:           req.PhoneNumber,
-		Email:                 castEmailToString(req.Email),
-		Address:               req.Address,
-		MedicalRecordNumber:   req.MedicalRecordNumber,
-		EmergencyContactName:  req.EmergencyContactName,
-		EmergencyContactPhone: req.EmergencyContactPhone,
-		BloodType:             castRequestBloodTypeToString(req.BloodType),
-		Allergies:             req.Allergies,
+	patient := &models.Patient{
+		FullName:            req.FullName,
+		DateOfBirth:         req.DateOfBirth.Format("2006-01-02"),
+		Gender:              string(req.Gender),
+		PatientType:         string(req.PatientType),
+		Hostel:              req.Hostel,
+		PhoneNumber:         req.PhoneNumber,
+		Email:               castEmailToString(req.Email),
+		Address:             req.Address,
+		MedicalRecordNumber: req.MedicalRecordNumber,
+		BloodType:           castRequestBloodTypeToString(req.BloodType),
+		Allergies:           req.Allergies,
 	}
+
+	// Deprecated emergency contact fields map onto the primary guardian.
+	if req.EmergencyContactName != nil && *req.EmergencyContactName != "" {
+		guardian := models.PatientGuardian{
+			FullName:      *req.EmergencyContactName,
+			Relationship:  "other",
+			CanBeNotified: true,
+		}
+		if req.EmergencyContactPhone != nil {
+			guardian.PhoneNumber = *req.EmergencyContactPhone
+		}
+		patient.Guardians = []models.PatientGuardian{guardian}
+	}
+
+	return patient
 }
 
 func castToEmail(email *string) *openapi_types.Email {
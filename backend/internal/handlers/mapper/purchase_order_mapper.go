@@ -0,0 +1,115 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedPurchaseOrder(o *models.PurchaseOrder) generated.PurchaseOrder {
+	supplierID, _ := uuid.Parse(o.SupplierID)
+
+	lines := make([]generated.PurchaseOrderLine, len(o.Lines))
+	for i := range o.Lines {
+		lines[i] = ToGeneratedPurchaseOrderLine(&o.Lines[i])
+	}
+
+	return generated.PurchaseOrder{
+		Id:              openapi_types.UUID(o.ID),
+		Number:          o.Number,
+		SupplierId:      openapi_types.UUID(supplierID),
+		SupplierCode:    o.Supplier.Code,
+		SupplierName:    o.Supplier.Name,
+		Status:          generated.PurchaseOrderStatus(o.Status),
+		ExpectedDate:    toDatePtr(o.ExpectedDate),
+		Notes:           o.Notes,
+		CreatedByUserId: toUUIDPtr(o.CreatedByUserID),
+		SentAt:          o.SentAt,
+		ClosedAt:        o.ClosedAt,
+		CancelledAt:     o.CancelledAt,
+		Lines:           lines,
+		CreatedAt:       o.CreatedAt,
+		UpdatedAt:       o.UpdatedAt,
+	}
+}
+
+func ToGeneratedPurchaseOrders(orders []models.PurchaseOrder) []generated.PurchaseOrder {
+	result := make([]generated.PurchaseOrder, len(orders))
+	for i := range orders {
+		result[i] = ToGeneratedPurchaseOrder(&orders[i])
+	}
+	return result
+}
+
+func ToGeneratedPurchaseOrderLine(l *models.PurchaseOrderLine) generated.PurchaseOrderLine {
+	medicineID, _ := uuid.Parse(l.MedicineID)
+	return generated.PurchaseOrderLine{
+		Id:                  openapi_types.UUID(l.ID),
+		MedicineId:          openapi_types.UUID(medicineID),
+		MedicineCode:        l.Medicine.Code,
+		MedicineName:        l.Medicine.Name,
+		Unit:                l.Medicine.Unit,
+		QuantityOrdered:     l.QuantityOrdered,
+		QuantityReceived:    l.QuantityReceived,
+		QuantityOutstanding: l.Outstanding(),
+		UnitCost:            l.UnitCost,
+	}
+}
+
+func ToGeneratedGoodsReceipt(r *models.GoodsReceipt) generated.GoodsReceipt {
+	purchaseOrderID, _ := uuid.Parse(r.PurchaseOrderID)
+
+	var receivedByName *string
+	if r.ReceivedBy != nil {
+		receivedByName = &r.ReceivedBy.Name
+	}
+
+	lines := make([]generated.GoodsReceiptLine, len(r.Lines))
+	totalQuantity := 0
+	for i := range r.Lines {
+		lines[i] = ToGeneratedGoodsReceiptLine(&r.Lines[i])
+		totalQuantity += r.Lines[i].Quantity
+	}
+
+	return generated.GoodsReceipt{
+		Id:                openapi_types.UUID(r.ID),
+		PurchaseOrderId:   openapi_types.UUID(purchaseOrderID),
+		ReceivedAt:        r.ReceivedAt,
+		ReferenceDocument: r.ReferenceDocument,
+		Notes:             r.Notes,
+		ReceivedByUserId:  toUUIDPtr(r.ReceivedByUserID),
+		ReceivedByName:    receivedByName,
+		TotalQuantity:     totalQuantity,
+		Lines:             lines,
+		CreatedAt:         r.CreatedAt,
+	}
+}
+
+func ToGeneratedGoodsReceipts(receipts []models.GoodsReceipt) []generated.GoodsReceipt {
+	result := make([]generated.GoodsReceipt, len(receipts))
+	for i := range receipts {
+		result[i] = ToGeneratedGoodsReceipt(&receipts[i])
+	}
+	return result
+}
+
+func ToGeneratedGoodsReceiptLine(l *models.GoodsReceiptLine) generated.GoodsReceiptLine {
+	orderLineID, _ := uuid.Parse(l.PurchaseOrderLineID)
+	medicineID, _ := uuid.Parse(l.MedicineID)
+	batchID, _ := uuid.Parse(l.MedicineBatchID)
+	return generated.GoodsReceiptLine{
+		Id:                  openapi_types.UUID(l.ID),
+		PurchaseOrderLineId: openapi_types.UUID(orderLineID),
+		MedicineId:          openapi_types.UUID(medicineID),
+		MedicineCode:        l.Medicine.Code,
+		MedicineName:        l.Medicine.Name,
+		MedicineBatchId:     openapi_types.UUID(batchID),
+		BatchNumber:         l.MedicineBatch.BatchNumber,
+		ExpirationDate:      openapi_types.Date{Time: l.MedicineBatch.ExpirationDate},
+		Unit:                l.MedicineBatch.Unit,
+		Quantity:            l.Quantity,
+		UnitCost:            l.UnitCost,
+	}
+}
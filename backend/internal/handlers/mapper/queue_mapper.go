@@ -0,0 +1,73 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedQueueEntry(e *models.QueueEntry) generated.QueueEntry {
+	patientID, _ := uuid.Parse(e.PatientID)
+
+	result := generated.QueueEntry{
+		Id:                     openapi_types.UUID(e.ID),
+		QueueDate:              openapi_types.Date{Time: e.QueueDate},
+		QueueNumber:            e.QueueNumber,
+		PatientId:              openapi_types.UUID(patientID),
+		PatientName:            e.Patient.FullName,
+		Status:                 generated.QueueEntryStatus(e.Status),
+		ChiefComplaint:         e.ChiefComplaint,
+		TemperatureC:           toFloat32Ptr(e.TemperatureC),
+		BloodPressureSystolic:  e.BloodPressureSystolic,
+		BloodPressureDiastolic: e.BloodPressureDiastolic,
+		HeartRate:              e.HeartRate,
+		RespiratoryRate:        e.RespiratoryRate,
+		OxygenSaturation:       e.OxygenSaturation,
+		HeightCm:               toFloat32Ptr(e.HeightCm),
+		WeightKg:               toFloat32Ptr(e.WeightKg),
+		TriageNotes:            e.TriageNotes,
+		TriagedAt:              e.TriagedAt,
+		TriagedByUserId:        toUUIDPtr(e.TriagedByUserID),
+		CheckedInAt:            e.CheckedInAt,
+		QueuedAt:               e.QueuedAt,
+		SkipCount:              e.SkipCount,
+		CalledAt:               e.CalledAt,
+		CalledByUserId:         toUUIDPtr(e.CalledByUserID),
+		PatientCheckupId:       toUUIDPtr(e.PatientCheckupID),
+		CreatedAt:              e.CreatedAt,
+		UpdatedAt:              e.UpdatedAt,
+	}
+
+	if e.Priority != nil {
+		priority := generated.QueueEntryPriority(*e.Priority)
+		result.Priority = &priority
+	}
+
+	return result
+}
+
+func ToGeneratedQueueEntries(entries []models.QueueEntry) []generated.QueueEntry {
+	result := make([]generated.QueueEntry, len(entries))
+	for i := range entries {
+		result[i] = ToGeneratedQueueEntry(&entries[i])
+	}
+	return result
+}
+
+func toFloat32Ptr(value *float64) *float32 {
+	if value == nil {
+		return nil
+	}
+	v := float32(*value)
+	return &v
+}
+
+func Float32PtrToFloat64Ptr(value *float32) *float64 {
+	if value == nil {
+		return nil
+	}
+	v := float64(*value)
+	return &v
+}
@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedReorderSuggestions(suggestions []models.ReorderSuggestion) []generated.ReorderSuggestion {
+	result := make([]generated.ReorderSuggestion, len(suggestions))
+	for i, s := range suggestions {
+		medicineID, _ := uuid.Parse(s.MedicineID)
+		result[i] = generated.ReorderSuggestion{
+			MedicineId:         openapi_types.UUID(medicineID),
+			MedicineCode:       s.MedicineCode,
+			MedicineName:       s.MedicineName,
+			Unit:               s.Unit,
+			CurrentStock:       s.CurrentStock,
+			MinimumStock:       s.MinimumStock,
+			OnOrder:            s.OnOrder,
+			Basis:              generated.ReorderSuggestionBasis(s.Basis),
+			AverageDailyDemand: s.AverageDailyDemand,
+			DemandStdDev:       s.DemandStdDev,
+			SeasonalFactor:     s.SeasonalFactor,
+			LeadTimeDays:       s.LeadTimeDays,
+			LeadTimeDemand:     s.LeadTimeDemand,
+			SafetyStock:        s.SafetyStock,
+			ReorderPoint:       s.ReorderPoint,
+			OrderUpTo:          s.OrderUpTo,
+			DaysOfCover:        s.DaysOfCover,
+			SuggestedQuantity:  s.SuggestedQuantity,
+			UnitCost:           s.UnitCost,
+			EstimatedCost:      s.EstimatedCost,
+		}
+	}
+	return result
+}
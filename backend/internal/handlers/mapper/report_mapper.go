@@ -0,0 +1,163 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedDiagnosisReportRows(rows []models.DiagnosisReportRow) []generated.DiagnosisReportRow {
+	result := make([]generated.DiagnosisReportRow, len(rows))
+	for i, r := range rows {
+		result[i] = generated.DiagnosisReportRow{
+			Code:         r.Code,
+			NameId:       r.NameID,
+			NameEn:       r.NameEN,
+			CheckupCount: r.CheckupCount,
+			PatientCount: r.PatientCount,
+			PrimaryCount: r.PrimaryCount,
+		}
+	}
+	return result
+}
+
+func ToGeneratedMedicineUsageRows(rows []models.MedicineUsageRow) []generated.MedicineUsageRow {
+	result := make([]generated.MedicineUsageRow, len(rows))
+	for i, r := range rows {
+		medicineID, _ := uuid.Parse(r.MedicineID)
+		result[i] = generated.MedicineUsageRow{
+			Rank:             r.Rank,
+			MedicineId:       openapi_types.UUID(medicineID),
+			MedicineCode:     r.MedicineCode,
+			MedicineName:     r.MedicineName,
+			Unit:             r.Unit,
+			PeriodStart:      toDatePtr(r.PeriodStart),
+			Source:           r.Source,
+			Quantity:         r.Quantity,
+			Value:            r.Value,
+			UncostedQuantity: r.UncostedQuantity,
+		}
+	}
+	return result
+}
+
+func ToGeneratedOutstandingPurchaseOrderRows(rows []models.OutstandingPurchaseOrderRow) []generated.OutstandingPurchaseOrderRow {
+	result := make([]generated.OutstandingPurchaseOrderRow, len(rows))
+	for i, r := range rows {
+		purchaseOrderID, _ := uuid.Parse(r.PurchaseOrderID)
+		supplierID, _ := uuid.Parse(r.SupplierID)
+		medicineID, _ := uuid.Parse(r.MedicineID)
+		result[i] = generated.OutstandingPurchaseOrderRow{
+			PurchaseOrderId:     openapi_types.UUID(purchaseOrderID),
+			Number:              r.Number,
+			Status:              generated.OutstandingPurchaseOrderRowStatus(r.Status),
+			SupplierId:          openapi_types.UUID(supplierID),
+			SupplierCode:        r.SupplierCode,
+			SupplierName:        r.SupplierName,
+			SentAt:              r.SentAt,
+			ExpectedDate:        toDatePtr(r.ExpectedDate),
+			DaysOverdue:         r.DaysOverdue,
+			MedicineId:          openapi_types.UUID(medicineID),
+			MedicineCode:        r.MedicineCode,
+			MedicineName:        r.MedicineName,
+			Unit:                r.Unit,
+			QuantityOrdered:     r.QuantityOrdered,
+			QuantityReceived:    r.QuantityReceived,
+			QuantityOutstanding: r.QuantityOutstanding,
+			UnitCost:            r.UnitCost,
+			OutstandingValue:    r.OutstandingValue,
+		}
+	}
+	return result
+}
+
+func ToGeneratedSupplierLeadTimeRows(rows []models.SupplierLeadTimeRow) []generated.SupplierLeadTimeRow {
+	result := make([]generated.SupplierLeadTimeRow, len(rows))
+	for i, r := range rows {
+		supplierID, _ := uuid.Parse(r.SupplierID)
+		result[i] = generated.SupplierLeadTimeRow{
+			SupplierId:      openapi_types.UUID(supplierID),
+			SupplierCode:    r.SupplierCode,
+			SupplierName:    r.SupplierName,
+			OrderCount:      r.OrderCount,
+			ReceiptCount:    r.ReceiptCount,
+			AverageLeadDays: r.AverageLeadDays,
+			MinLeadDays:     r.MinLeadDays,
+			MaxLeadDays:     r.MaxLeadDays,
+			OnTimeReceipts:  r.OnTimeReceipts,
+			LateReceipts:    r.LateReceipts,
+		}
+	}
+	return result
+}
+
+func ToGeneratedInventoryValuation(v *models.InventoryValuation) generated.InventoryValuation {
+	rows := make([]generated.InventoryValuationRow, len(v.Rows))
+	for i, r := range v.Rows {
+		medicineID, _ := uuid.Parse(r.MedicineID)
+		rows[i] = generated.InventoryValuationRow{
+			MedicineId:       openapi_types.UUID(medicineID),
+			MedicineCode:     r.MedicineCode,
+			MedicineName:     r.MedicineName,
+			Unit:             r.Unit,
+			MedicineBatchId:  toUUIDPtr(r.MedicineBatchID),
+			BatchNumber:      r.BatchNumber,
+			ExpirationDate:   toDatePtr(r.ExpirationDate),
+			UnitCost:         r.UnitCost,
+			Quantity:         r.Quantity,
+			Value:            r.Value,
+			ExpiredQuantity:  r.ExpiredQuantity,
+			ExpiredValue:     r.ExpiredValue,
+			UncostedQuantity: r.UncostedQuantity,
+		}
+	}
+
+	return generated.InventoryValuation{
+		Summary: generated.InventoryValuationSummary{
+			AsOf:             openapi_types.Date{Time: v.Summary.AsOf},
+			MedicineCount:    v.Summary.MedicineCount,
+			Quantity:         v.Summary.Quantity,
+			Value:            v.Summary.Value,
+			ExpiredQuantity:  v.Summary.ExpiredQuantity,
+			ExpiredValue:     v.Summary.ExpiredValue,
+			UncostedQuantity: v.Summary.UncostedQuantity,
+		},
+		Rows: rows,
+	}
+}
+
+func ToGeneratedCostOfGoodsDispensed(c *models.CostOfGoodsDispensed) generated.CostOfGoodsDispensed {
+	rows := make([]generated.CostOfGoodsDispensedRow, len(c.Rows))
+	for i, r := range c.Rows {
+		rows[i] = generated.CostOfGoodsDispensedRow{
+			PeriodStart:      toDatePtr(r.PeriodStart),
+			PatientType:      r.PatientType,
+			PatientCheckupId: toUUIDPtr(r.PatientCheckupID),
+			PatientId:        toUUIDPtr(r.PatientID),
+			PatientName:      r.PatientName,
+			VisitDate:        r.VisitDate,
+			CheckupCount:     r.CheckupCount,
+			Quantity:         r.Quantity,
+			Cost:             r.Cost,
+			SellingValue:     r.SellingValue,
+			UncostedQuantity: r.UncostedQuantity,
+			UnpricedQuantity: r.UnpricedQuantity,
+		}
+	}
+
+	return generated.CostOfGoodsDispensed{
+		Summary: generated.CostOfGoodsDispensedSummary{
+			DateFrom:         openapi_types.Date{Time: c.Summary.DateFrom},
+			DateTo:           openapi_types.Date{Time: c.Summary.DateTo},
+			CheckupCount:     c.Summary.CheckupCount,
+			Quantity:         c.Summary.Quantity,
+			Cost:             c.Summary.Cost,
+			SellingValue:     c.Summary.SellingValue,
+			UncostedQuantity: c.Summary.UncostedQuantity,
+			UnpricedQuantity: c.Summary.UnpricedQuantity,
+		},
+		Rows: rows,
+	}
+}
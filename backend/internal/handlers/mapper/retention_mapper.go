@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedRetentionCandidates(candidates []models.RetentionCandidate) []generated.RetentionCandidate {
+	result := make([]generated.RetentionCandidate, len(candidates))
+	for i, c := range candidates {
+		patientID, _ := uuid.Parse(c.PatientID)
+		result[i] = generated.RetentionCandidate{
+			PatientId:      openapi_types.UUID(patientID),
+			FullName:       c.FullName,
+			PatientType:    generated.RetentionCandidatePatientType(c.PatientType),
+			RetentionYears: c.RetentionYears,
+			LastActivityAt: c.LastActivityAt,
+			EligibleSince:  c.EligibleSince,
+		}
+	}
+	return result
+}
+
+func ToGeneratedPatientErasureAudit(a *models.PatientErasureAudit) generated.PatientErasureAudit {
+	patientID, _ := uuid.Parse(a.PatientID)
+
+	result := generated.PatientErasureAudit{
+		Id:                   openapi_types.UUID(a.ID),
+		PatientId:            openapi_types.UUID(patientID),
+		PatientType:          generated.PatientErasureAuditPatientType(a.PatientType),
+		RetentionYears:       a.RetentionYears,
+		LastActivityAt:       a.LastActivityAt,
+		Trigger:              generated.PatientErasureAuditTrigger(a.Trigger),
+		ClearedFields:        a.ClearedFields,
+		GuardiansDeleted:     a.GuardiansDeleted,
+		AttachmentsDeleted:   a.AttachmentsDeleted,
+		CheckupsDeidentified: a.CheckupsDeidentified,
+		CreatedAt:            a.CreatedAt,
+	}
+
+	if a.PerformedByUserID != nil {
+		if parsed, err := uuid.Parse(*a.PerformedByUserID); err == nil {
+			userID := openapi_types.UUID(parsed)
+			result.PerformedByUserId = &userID
+		}
+	}
+
+	return result
+}
+
+func ToGeneratedPatientErasureAudits(audits []models.PatientErasureAudit) []generated.PatientErasureAudit {
+	result := make([]generated.PatientErasureAudit, len(audits))
+	for i := range audits {
+		result[i] = ToGeneratedPatientErasureAudit(&audits[i])
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedDoctorSchedules(schedules []models.DoctorSchedule) []generated.DoctorSchedule {
+	result := make([]generated.DoctorSchedule, len(schedules))
+	for i, s := range schedules {
+		doctorID, _ := uuid.Parse(s.DoctorID)
+		result[i] = generated.DoctorSchedule{
+			Id:          openapi_types.UUID(s.ID),
+			DoctorId:    openapi_types.UUID(doctorID),
+			Weekday:     s.Weekday,
+			StartTime:   s.StartTime,
+			EndTime:     s.EndTime,
+			SlotMinutes: s.SlotMinutes,
+		}
+	}
+	return result
+}
+
+func ToModelDoctorSchedules(entries []generated.DoctorScheduleEntry) []models.DoctorSchedule {
+	result := make([]models.DoctorSchedule, len(entries))
+	for i, e := range entries {
+		result[i] = models.DoctorSchedule{
+			Weekday:     e.Weekday,
+			StartTime:   e.StartTime,
+			EndTime:     e.EndTime,
+			SlotMinutes: e.SlotMinutes,
+		}
+	}
+	return result
+}
+
+func ToGeneratedClinicHoliday(h *models.ClinicHoliday) generated.ClinicHoliday {
+	return generated.ClinicHoliday{
+		Id:   openapi_types.UUID(h.ID),
+		Date: openapi_types.Date{Time: h.Date},
+		Name: h.Name,
+	}
+}
+
+func ToGeneratedClinicHolidays(holidays []models.ClinicHoliday) []generated.ClinicHoliday {
+	result := make([]generated.ClinicHoliday, len(holidays))
+	for i := range holidays {
+		result[i] = ToGeneratedClinicHoliday(&holidays[i])
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedStockAdjustment(a *models.StockAdjustment) generated.StockAdjustment {
+	medicineID, _ := uuid.Parse(a.MedicineID)
+	batchID, _ := uuid.Parse(a.MedicineBatchID)
+	return generated.StockAdjustment{
+		Id:                openapi_types.UUID(a.ID),
+		MedicineId:        openapi_types.UUID(medicineID),
+		MedicineCode:      a.Medicine.Code,
+		MedicineName:      a.Medicine.Name,
+		MedicineBatchId:   openapi_types.UUID(batchID),
+		BatchNumber:       a.MedicineBatch.BatchNumber,
+		Type:              generated.StockAdjustmentType(a.Type),
+		ReasonCode:        a.ReasonCode,
+		Justification:     a.Justification,
+		QuantityDelta:     a.QuantityDelta,
+		Status:            generated.StockAdjustmentStatus(a.Status),
+		RequestedByUserId: toUUIDPtr(a.RequestedByUserID),
+		ReviewedByUserId:  toUUIDPtr(a.ReviewedByUserID),
+		ReviewedAt:        a.ReviewedAt,
+		ReviewNotes:       a.ReviewNotes,
+		AppliedAt:         a.AppliedAt,
+		CreatedAt:         a.CreatedAt,
+		UpdatedAt:         a.UpdatedAt,
+	}
+}
+
+func ToGeneratedStockAdjustments(adjustments []models.StockAdjustment) []generated.StockAdjustment {
+	result := make([]generated.StockAdjustment, len(adjustments))
+	for i := range adjustments {
+		result[i] = ToGeneratedStockAdjustment(&adjustments[i])
+	}
+	return result
+}
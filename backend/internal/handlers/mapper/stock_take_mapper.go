@@ -0,0 +1,78 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedStockTake(s *models.StockTake) generated.StockTake {
+	return generated.StockTake{
+		Id:               openapi_types.UUID(s.ID),
+		Name:             s.Name,
+		Notes:            s.Notes,
+		Status:           generated.StockTakeStatus(s.Status),
+		FreezeDispensing: s.FreezeDispensing,
+		OpenedByUserId:   toUUIDPtr(s.OpenedByUserID),
+		ApprovedByUserId: toUUIDPtr(s.ApprovedByUserID),
+		ApprovedAt:       s.ApprovedAt,
+		CancelledAt:      s.CancelledAt,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+	}
+}
+
+func ToGeneratedStockTakes(stockTakes []models.StockTake) []generated.StockTake {
+	result := make([]generated.StockTake, len(stockTakes))
+	for i := range stockTakes {
+		result[i] = ToGeneratedStockTake(&stockTakes[i])
+	}
+	return result
+}
+
+func ToGeneratedStockTakeLine(l *models.StockTakeLine) generated.StockTakeLine {
+	medicineID, _ := uuid.Parse(l.MedicineID)
+	batchID, _ := uuid.Parse(l.MedicineBatchID)
+	return generated.StockTakeLine{
+		Id:                openapi_types.UUID(l.ID),
+		MedicineId:        openapi_types.UUID(medicineID),
+		MedicineCode:      l.Medicine.Code,
+		MedicineName:      l.Medicine.Name,
+		MedicineBatchId:   openapi_types.UUID(batchID),
+		BatchNumber:       l.MedicineBatch.BatchNumber,
+		ExpirationDate:    openapi_types.Date{Time: l.MedicineBatch.ExpirationDate},
+		Unit:              l.MedicineBatch.Unit,
+		SystemQuantity:    l.SystemQuantity,
+		CountedQuantity:   l.CountedQuantity,
+		Variance:          l.Variance(),
+		CountedByUserId:   toUUIDPtr(l.CountedByUserID),
+		CountedAt:         l.CountedAt,
+		Notes:             l.Notes,
+		StockAdjustmentId: toUUIDPtr(l.StockAdjustmentID),
+	}
+}
+
+func ToGeneratedStockTakeLines(lines []models.StockTakeLine) []generated.StockTakeLine {
+	result := make([]generated.StockTakeLine, len(lines))
+	for i := range lines {
+		result[i] = ToGeneratedStockTakeLine(&lines[i])
+	}
+	return result
+}
+
+func ToGeneratedStockTakeVarianceReport(summary *models.StockTakeVariance, lines []models.StockTakeLine) generated.StockTakeVarianceReport {
+	return generated.StockTakeVarianceReport{
+		Summary: generated.StockTakeVariance{
+			TotalLines:        summary.TotalLines,
+			CountedLines:      summary.CountedLines,
+			LinesWithVariance: summary.LinesWithVariance,
+			SurplusQuantity:   summary.SurplusQuantity,
+			ShortageQuantity:  summary.ShortageQuantity,
+			NetQuantity:       summary.NetQuantity,
+			NetValue:          summary.NetValue,
+		},
+		Lines: ToGeneratedStockTakeLines(lines),
+	}
+}
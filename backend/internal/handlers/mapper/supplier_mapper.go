@@ -0,0 +1,63 @@
+package mapper
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+func ToGeneratedSupplier(s *models.Supplier) generated.Supplier {
+	return generated.Supplier{
+		Id:          openapi_types.UUID(s.ID),
+		Name:        s.Name,
+		Code:        s.Code,
+		ContactName: s.ContactName,
+		Phone:       s.Phone,
+		Email:       s.Email,
+		Address:     s.Address,
+		Notes:       s.Notes,
+		IsActive:    s.IsActive,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func ToGeneratedSuppliers(suppliers []models.Supplier) []generated.Supplier {
+	result := make([]generated.Supplier, len(suppliers))
+	for i := range suppliers {
+		result[i] = ToGeneratedSupplier(&suppliers[i])
+	}
+	return result
+}
+
+func ToModelSupplier(req generated.CreateSupplierRequest) *models.Supplier {
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	return &models.Supplier{
+		Name:        req.Name,
+		Code:        req.Code,
+		ContactName: req.ContactName,
+		Phone:       req.Phone,
+		Email:       req.Email,
+		Address:     req.Address,
+		Notes:       req.Notes,
+		IsActive:    isActive,
+	}
+}
+
+func ToModelSupplierUpdate(req generated.UpdateSupplierRequest) *models.Supplier {
+	return &models.Supplier{
+		Name:        req.Name,
+		Code:        req.Code,
+		ContactName: req.ContactName,
+		Phone:       req.Phone,
+		Email:       req.Email,
+		Address:     req.Address,
+		Notes:       req.Notes,
+		IsActive:    req.IsActive,
+	}
+}
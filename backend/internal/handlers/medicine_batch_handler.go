@@ -5,6 +5,7 @@ import (
 	"backend/internal/handlers/mapper"
 	"backend/internal/repository"
 	"backend/internal/service"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -149,6 +150,12 @@ func (h *MedicineBatchHandler) DeleteMedicineBatch(
 			})
 			return
 		}
+		if errors.Is(err, service.ErrBatchInUse) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, generated.Error{
 			Message: "Failed to delete batch",
 		})
@@ -157,3 +164,42 @@ func (h *MedicineBatchHandler) DeleteMedicineBatch(
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *MedicineBatchHandler) ListMedicineBatchRecipients(
+	c *gin.Context,
+	id generated.IdParam,
+	params generated.ListMedicineBatchRecipientsParams,
+) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	recipients, total, err := h.service.ListBatchRecipients(c.Request.Context(), id, page, perPage)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Batch not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch batch recipients",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedBatchRecipients(recipients),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"backend/internal/handlers/mapper"
 	"backend/internal/repository"
 	"backend/internal/service"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -202,3 +203,56 @@ func (h *MedicineHandler) ListMedicineStockActivities(c *gin.Context, id generat
 		},
 	})
 }
+
+func (h *MedicineHandler) ListStockActivities(c *gin.Context, params generated.ListStockActivitiesParams) {
+	input := service.StockLedgerInput{
+		DateFrom:    mapper.DatePtrToTimePtr(params.DateFrom),
+		DateTo:      mapper.DatePtrToTimePtr(params.DateTo),
+		NewestFirst: params.Order == nil || *params.Order != generated.ListStockActivitiesParamsOrderAsc,
+	}
+	if params.Source != nil {
+		input.Filter.Source = string(*params.Source)
+	}
+	if params.ChangeType != nil {
+		input.Filter.ChangeType = string(*params.ChangeType)
+	}
+	if params.MedicineId != nil {
+		input.Filter.MedicineID = params.MedicineId.String()
+	}
+	if params.UserId != nil {
+		input.Filter.UserID = params.UserId.String()
+	}
+	if params.MedicineBatchId != nil {
+		input.Filter.BatchID = params.MedicineBatchId.String()
+	}
+	if params.PatientCheckupId != nil {
+		input.Filter.CheckupID = params.PatientCheckupId.String()
+	}
+	if params.Cursor != nil {
+		input.Cursor = *params.Cursor
+	}
+	if params.Limit != nil {
+		input.Limit = *params.Limit
+	}
+
+	entries, nextCursor, err := h.stockActivityService.ListLedger(c.Request.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLedgerCursor) || errors.Is(err, service.ErrInvalidLedgerFilter) {
+			c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch stock activities",
+		})
+		return
+	}
+
+	meta := generated.CursorMeta{HasMore: nextCursor != ""}
+	if nextCursor != "" {
+		meta.NextCursor = &nextCursor
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockLedgerEntries(entries),
+		"meta": meta,
+	})
+}
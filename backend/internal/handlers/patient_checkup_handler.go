@@ -3,8 +3,10 @@ package handlers
 import (
 	"backend/internal/generated"
 	"backend/internal/handlers/mapper"
+	"backend/internal/models"
 	"backend/internal/repository"
 	"backend/internal/service"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -54,6 +56,18 @@ func (h *PatientCheckupHandler) ListPatientCheckups(c *gin.Context, params gener
 		t := params.VisitDateTo.Time
 		filter.VisitDateTo = &t
 	}
+	filter.Fever = params.Fever
+	filter.Hypoxia = params.Hypoxia
+	if params.BloodPressureCategory != nil {
+		filter.BloodPressureCategory = string(*params.BloodPressureCategory)
+	}
+	filter.AbnormalVitals = params.AbnormalVitals
+	if params.DiagnosisCode != nil {
+		filter.DiagnosisCode = *params.DiagnosisCode
+	}
+	if params.DoctorId != nil {
+		filter.DoctorID = params.DoctorId.String()
+	}
 
 	checkups, total, err := h.service.ListCheckups(c.Request.Context(), page, perPage, filter)
 	if err != nil {
@@ -83,6 +97,10 @@ func (h *PatientCheckupHandler) CreatePatientCheckup(c *gin.Context) {
 		return
 	}
 
+	if !bindLegacyBloodPressure(c, req.BloodPressure, &req.BloodPressureSystolic, &req.BloodPressureDiastolic) {
+		return
+	}
+
 	checkup := mapper.ToModelCreatePatientCheckup(req)
 	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
 	var patientUpdate *service.PatientClinicalUpdate
@@ -98,6 +116,21 @@ func (h *PatientCheckupHandler) CreatePatientCheckup(c *gin.Context) {
 	}
 
 	if err := h.service.CreateCheckup(ctx, checkup, patientUpdate); err != nil {
+		if errors.Is(err, service.ErrImplausibleVitals) ||
+			errors.Is(err, service.ErrInvalidDiagnoses) ||
+			errors.Is(err, service.ErrUnknownDiagnosisCode) ||
+			errors.Is(err, service.ErrNotADoctor) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCheckupTransition) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "New checkups must start as scheduled",
+			})
+			return
+		}
 		if strings.Contains(err.Error(), "insufficient stock") {
 			c.JSON(http.StatusBadRequest, generated.Error{
 				Message: err.Error(),
@@ -144,27 +177,41 @@ func (h *PatientCheckupHandler) UpdatePatientCheckup(c *gin.Context, id generate
 		return
 	}
 
+	if !bindLegacyBloodPressure(c, req.BloodPressure, &req.BloodPressureSystolic, &req.BloodPressureDiastolic) {
+		return
+	}
+
 	checkup := mapper.ToModelUpdatePatientCheckup(req)
 	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
-	var patientUpdate *service.PatientClinicalUpdate
-	if req.PatientAllergies != nil || req.PatientBloodType != nil {
-		update := &service.PatientClinicalUpdate{
-			Allergies: req.PatientAllergies,
-		}
-		if req.PatientBloodType != nil {
-			bloodType := string(*req.PatientBloodType)
-			update.BloodType = &bloodType
-		}
-		patientUpdate = update
-	}
 
-	if err := h.service.UpdateCheckup(ctx, id, checkup, patientUpdate); err != nil {
+	if err := h.service.UpdateCheckup(ctx, id, checkup, toPatientClinicalUpdate(req), req.ChangeReason); err != nil {
+		if errors.Is(err, service.ErrImplausibleVitals) ||
+			errors.Is(err, service.ErrInvalidDiagnoses) ||
+			errors.Is(err, service.ErrUnknownDiagnosisCode) ||
+			errors.Is(err, service.ErrNotADoctor) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, generated.Error{
 				Message: "Patient checkup not found",
 			})
 			return
 		}
+		if errors.Is(err, service.ErrCheckupLocked) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "Checkup is locked; amend a completed checkup instead",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCheckupTransition) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "Status can only be changed through the transition endpoints",
+			})
+			return
+		}
 		if strings.Contains(err.Error(), "insufficient stock") {
 			c.JSON(http.StatusBadRequest, generated.Error{
 				Message: err.Error(),
@@ -191,6 +238,18 @@ func (h *PatientCheckupHandler) DeletePatientCheckup(c *gin.Context, id generate
 			})
 			return
 		}
+		if errors.Is(err, service.ErrCheckupLocked) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "Completed checkups cannot be deleted",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrCheckupHasHistory) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "Checkups with status changes or edits cannot be deleted; cancel the checkup instead",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, generated.Error{
 			Message: "Failed to delete patient checkup",
 		})
@@ -199,3 +258,257 @@ func (h *PatientCheckupHandler) DeletePatientCheckup(c *gin.Context, id generate
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *PatientCheckupHandler) StartPatientCheckup(c *gin.Context, id generated.IdParam) {
+	h.transition(c, id, models.CheckupStatusInProgress, nil)
+}
+
+func (h *PatientCheckupHandler) CompletePatientCheckup(c *gin.Context, id generated.IdParam) {
+	h.transition(c, id, models.CheckupStatusCompleted, nil)
+}
+
+func (h *PatientCheckupHandler) CancelPatientCheckup(c *gin.Context, id generated.IdParam) {
+	reason, ok := bindTransitionReason(c)
+	if !ok {
+		return
+	}
+	h.transition(c, id, models.CheckupStatusCancelled, reason)
+}
+
+func (h *PatientCheckupHandler) MarkPatientCheckupNoShow(c *gin.Context, id generated.IdParam) {
+	reason, ok := bindTransitionReason(c)
+	if !ok {
+		return
+	}
+	h.transition(c, id, models.CheckupStatusNoShow, reason)
+}
+
+func (h *PatientCheckupHandler) DispensePatientCheckup(c *gin.Context, id generated.IdParam) {
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+
+	checkup, err := h.service.DispenseCheckup(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrCheckupNotDispensable) ||
+			errors.Is(err, service.ErrNothingToDispense) ||
+			errors.Is(err, service.ErrDispensingFrozen) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "insufficient stock") {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to dispense medicines",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientCheckup(checkup),
+	})
+}
+
+func (h *PatientCheckupHandler) AmendPatientCheckup(c *gin.Context, id generated.IdParam) {
+	var req generated.AmendPatientCheckupRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(strings.TrimSpace(req.Reason)) < 3 {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	if !bindLegacyBloodPressure(c, req.Changes.BloodPressure, &req.Changes.BloodPressureSystolic, &req.Changes.BloodPressureDiastolic) {
+		return
+	}
+
+	checkup := mapper.ToModelUpdatePatientCheckup(req.Changes)
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+
+	if err := h.service.AmendCheckup(ctx, id, checkup, toPatientClinicalUpdate(req.Changes), strings.TrimSpace(req.Reason)); err != nil {
+		if errors.Is(err, service.ErrImplausibleVitals) ||
+			errors.Is(err, service.ErrInvalidDiagnoses) ||
+			errors.Is(err, service.ErrUnknownDiagnosisCode) ||
+			errors.Is(err, service.ErrNotADoctor) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrCheckupNotAmendable) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "Only completed checkups can be amended",
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "insufficient stock") {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to amend patient checkup",
+		})
+		return
+	}
+
+	updated, _ := h.service.GetCheckup(c.Request.Context(), id)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientCheckup(updated),
+	})
+}
+
+func (h *PatientCheckupHandler) ListPatientCheckupStatusHistory(c *gin.Context, id generated.IdParam) {
+	transitions, err := h.service.ListStatusHistory(c.Request.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch checkup status history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientCheckupTransitions(transitions),
+	})
+}
+
+func (h *PatientCheckupHandler) ListPatientCheckupRevisions(c *gin.Context, id generated.IdParam) {
+	revisions, err := h.service.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch checkup revisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientCheckupRevisions(revisions),
+	})
+}
+
+func (h *PatientCheckupHandler) GetPatientCheckupRevision(c *gin.Context, id generated.IdParam, revision generated.PatientCheckupRevisionParam) {
+	result, err := h.service.GetRevision(c.Request.Context(), id, revision)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup revision not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch checkup revision",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientCheckupRevisionWithSnapshot(result),
+	})
+}
+
+func (h *PatientCheckupHandler) transition(c *gin.Context, id generated.IdParam, toStatus string, reason *string) {
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+
+	checkup, err := h.service.TransitionCheckup(ctx, id, toStatus, reason)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient checkup not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCheckupTransition) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: "Checkup cannot move to " + toStatus + " from its current status",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to change checkup status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientCheckup(checkup),
+	})
+}
+
+// bindTransitionReason reads the optional transition body.
+func bindTransitionReason(c *gin.Context) (*string, bool) {
+	if c.Request.ContentLength == 0 {
+		return nil, true
+	}
+
+	var req generated.PatientCheckupTransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return nil, false
+	}
+	return req.Reason, true
+}
+
+// bindLegacyBloodPressure fills the systolic and diastolic values from the
+// deprecated "120/80" blood_pressure field when a client still sends it.
+func bindLegacyBloodPressure(c *gin.Context, value *string, systolic, diastolic **int) bool {
+	if *systolic != nil || *diastolic != nil || value == nil || strings.TrimSpace(*value) == "" {
+		return true
+	}
+
+	sys, dia, err := models.ParseBloodPressure(*value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: err.Error(),
+		})
+		return false
+	}
+	*systolic = &sys
+	*diastolic = &dia
+	return true
+}
+
+func toPatientClinicalUpdate(req generated.UpdatePatientCheckupRequest) *service.PatientClinicalUpdate {
+	if req.PatientAllergies == nil && req.PatientBloodType == nil {
+		return nil
+	}
+
+	update := &service.PatientClinicalUpdate{
+		Allergies: req.PatientAllergies,
+	}
+	if req.PatientBloodType != nil {
+		bloodType := string(*req.PatientBloodType)
+		update.BloodType = &bloodType
+	}
+	return update
+}
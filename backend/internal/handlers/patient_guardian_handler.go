@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PatientGuardianHandler struct {
+	service service.PatientGuardianService
+}
+
+func NewPatientGuardianHandler(service service.PatientGuardianService) *PatientGuardianHandler {
+	return &PatientGuardianHandler{service: service}
+}
+
+func (h *PatientGuardianHandler) ListPatientGuardians(c *gin.Context, id generated.IdParam) {
+	guardians, err := h.service.ListGuardians(c.Request.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch patient guardians",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientGuardians(guardians),
+	})
+}
+
+func (h *PatientGuardianHandler) CreatePatientGuardian(c *gin.Context, id generated.IdParam) {
+	var req generated.CreatePatientGuardianRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	guardian := mapper.ToModelCreatePatientGuardian(req)
+
+	if err := h.service.CreateGuardian(c.Request.Context(), id, guardian); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to create patient guardian",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedPatientGuardian(guardian),
+	})
+}
+
+func (h *PatientGuardianHandler) UpdatePatientGuardian(c *gin.Context, id generated.IdParam, guardianId generated.GuardianIdParam) {
+	var req generated.UpdatePatientGuardianRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	guardian := mapper.ToModelUpdatePatientGuardian(req)
+
+	if err := h.service.UpdateGuardian(c.Request.Context(), id, guardianId, guardian); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient guardian not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to update patient guardian",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientGuardian(guardian),
+	})
+}
+
+func (h *PatientGuardianHandler) DeletePatientGuardian(c *gin.Context, id generated.IdParam, guardianId generated.GuardianIdParam) {
+	if err := h.service.DeleteGuardian(c.Request.Context(), id, guardianId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Patient guardian not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to delete patient guardian",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
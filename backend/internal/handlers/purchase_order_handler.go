@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PurchaseOrderHandler struct {
+	service service.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service service.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+func (h *PurchaseOrderHandler) ListPurchaseOrders(c *gin.Context, params generated.ListPurchaseOrdersParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	filter := repository.PurchaseOrderFilter{}
+	if params.Status != nil {
+		filter.Status = string(*params.Status)
+	}
+	if params.SupplierId != nil {
+		filter.SupplierID = params.SupplierId.String()
+	}
+	if params.Search != nil {
+		filter.Search = *params.Search
+	}
+
+	orders, total, err := h.service.ListPurchaseOrders(c.Request.Context(), page, perPage, filter)
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to fetch purchase orders")
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrders(orders),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var req generated.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	order, err := h.service.CreatePurchaseOrder(ctx, purchaseOrderInput(req))
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to create purchase order")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context, id generated.IdParam) {
+	order, err := h.service.GetPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to fetch purchase order")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(c *gin.Context, id generated.IdParam) {
+	var req generated.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	order, err := h.service.UpdatePurchaseOrder(c.Request.Context(), id, purchaseOrderInput(req))
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to update purchase order")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func (h *PurchaseOrderHandler) SendPurchaseOrder(c *gin.Context, id generated.IdParam) {
+	order, err := h.service.SendPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to send purchase order")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func (h *PurchaseOrderHandler) ClosePurchaseOrder(c *gin.Context, id generated.IdParam) {
+	order, err := h.service.ClosePurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to close purchase order")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c *gin.Context, id generated.IdParam) {
+	order, err := h.service.CancelPurchaseOrder(c.Request.Context(), id)
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to cancel purchase order")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func (h *PurchaseOrderHandler) ListGoodsReceipts(c *gin.Context, id generated.IdParam) {
+	receipts, err := h.service.ListReceipts(c.Request.Context(), id)
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to fetch goods receipts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedGoodsReceipts(receipts),
+	})
+}
+
+func (h *PurchaseOrderHandler) ReceiveGoods(c *gin.Context, id generated.IdParam) {
+	var req generated.CreateGoodsReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	lines := make([]service.GoodsReceiptLineInput, len(req.Lines))
+	for i, line := range req.Lines {
+		lines[i] = service.GoodsReceiptLineInput{
+			PurchaseOrderLineID: line.PurchaseOrderLineId,
+			BatchNumber:         line.BatchNumber,
+			ExpirationDate:      line.ExpirationDate.Time,
+			Quantity:            line.Quantity,
+			UnitCost:            line.UnitCost,
+			SellingPrice:        line.SellingPrice,
+		}
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	receipt, err := h.service.ReceiveGoods(ctx, id, service.GoodsReceiptInput{
+		ReceivedAt:        req.ReceivedAt,
+		ReferenceDocument: req.ReferenceDocument,
+		Notes:             req.Notes,
+		Lines:             lines,
+	})
+	if err != nil {
+		respondPurchaseOrderError(c, err, "Failed to receive goods")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedGoodsReceipt(receipt),
+	})
+}
+
+func purchaseOrderInput(req generated.PurchaseOrderRequest) service.PurchaseOrderInput {
+	lines := make([]service.PurchaseOrderLineInput, len(req.Lines))
+	for i, line := range req.Lines {
+		lines[i] = service.PurchaseOrderLineInput{
+			MedicineID: line.MedicineId,
+			Quantity:   line.Quantity,
+			UnitCost:   line.UnitCost,
+		}
+	}
+
+	return service.PurchaseOrderInput{
+		SupplierID:   req.SupplierId,
+		ExpectedDate: mapper.DatePtrToTimePtr(req.ExpectedDate),
+		Notes:        req.Notes,
+		Lines:        lines,
+	}
+}
+
+func respondPurchaseOrderError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Purchase order not found"})
+	case errors.Is(err, service.ErrInvalidPurchaseOrder), errors.Is(err, service.ErrInvalidGoodsReceipt):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrPurchaseOrderState), errors.Is(err, service.ErrReceiptExceedsOrdered),
+		errors.Is(err, service.ErrReceiptCostMismatch):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
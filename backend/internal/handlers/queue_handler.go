@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/models"
+	"backend/internal/service"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// queueHeartbeatInterval keeps idle display streams from being closed by
+// proxies and lets screens notice a dropped connection.
+const queueHeartbeatInterval = 25 * time.Second
+
+type QueueHandler struct {
+	service service.QueueService
+}
+
+func NewQueueHandler(service service.QueueService) *QueueHandler {
+	return &QueueHandler{service: service}
+}
+
+func (h *QueueHandler) ListQueue(c *gin.Context, params generated.ListQueueParams) {
+	status := ""
+	if params.Status != nil {
+		status = string(*params.Status)
+	}
+
+	entries, err := h.service.ListQueue(c.Request.Context(), mapper.DatePtrToTimePtr(params.Date), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch queue",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedQueueEntries(entries),
+	})
+}
+
+func (h *QueueHandler) CheckInQueue(c *gin.Context) {
+	var req generated.QueueCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	entry, err := h.service.CheckIn(c.Request.Context(), uuid.UUID(req.PatientId).String(), req.ChiefComplaint)
+	if err != nil {
+		respondQueueError(c, err, "Failed to check in patient")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedQueueEntry(entry),
+	})
+}
+
+func (h *QueueHandler) TriageQueueEntry(c *gin.Context, id generated.IdParam) {
+	var req generated.QueueTriageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	input := service.QueueTriage{
+		Priority:               string(req.Priority),
+		TemperatureC:           mapper.Float32PtrToFloat64Ptr(req.TemperatureC),
+		BloodPressureSystolic:  req.BloodPressureSystolic,
+		BloodPressureDiastolic: req.BloodPressureDiastolic,
+		HeartRate:              req.HeartRate,
+		RespiratoryRate:        req.RespiratoryRate,
+		OxygenSaturation:       req.OxygenSaturation,
+		HeightCm:               mapper.Float32PtrToFloat64Ptr(req.HeightCm),
+		WeightKg:               mapper.Float32PtrToFloat64Ptr(req.WeightKg),
+		Notes:                  req.Notes,
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	entry, err := h.service.TriageQueueEntry(ctx, id, input)
+	if err != nil {
+		respondQueueError(c, err, "Failed to record triage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedQueueEntry(entry),
+	})
+}
+
+func (h *QueueHandler) CallNextInQueue(c *gin.Context) {
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	entry, err := h.service.CallNext(ctx)
+	if err != nil {
+		respondQueueError(c, err, "Failed to call next patient")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedQueueEntry(entry),
+	})
+}
+
+func (h *QueueHandler) SkipQueueEntry(c *gin.Context, id generated.IdParam) {
+	entry, err := h.service.SkipQueueEntry(c.Request.Context(), id)
+	if err != nil {
+		respondQueueError(c, err, "Failed to skip patient")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedQueueEntry(entry),
+	})
+}
+
+func (h *QueueHandler) MarkQueueEntryNoShow(c *gin.Context, id generated.IdParam) {
+	entry, err := h.service.MarkQueueNoShow(c.Request.Context(), id)
+	if err != nil {
+		respondQueueError(c, err, "Failed to mark patient as no-show")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedQueueEntry(entry),
+	})
+}
+
+// StreamQueue pushes queue changes to display screens as Server-Sent Events
+// until the client disconnects or the server shuts down.
+func (h *QueueHandler) StreamQueue(c *gin.Context, _ generated.StreamQueueParams) {
+	events, unsubscribe := h.service.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(queueHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if entry, ok := event.Payload.(*models.QueueEntry); ok {
+				c.SSEvent(event.Type, mapper.ToGeneratedQueueEntry(entry))
+			}
+			return true
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// respondQueueError maps queue errors to responses; anything unexpected
+// becomes a 500 with fallback as the message.
+func respondQueueError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Queue entry not found"})
+	case errors.Is(err, service.ErrAlreadyQueued),
+		errors.Is(err, service.ErrQueueEntryNotWaiting),
+		errors.Is(err, service.ErrQueueEmpty):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrImplausibleVitals):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Patient not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
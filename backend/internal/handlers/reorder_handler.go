@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReorderHandler struct {
+	service service.ReorderService
+}
+
+func NewReorderHandler(service service.ReorderService) *ReorderHandler {
+	return &ReorderHandler{service: service}
+}
+
+func (h *ReorderHandler) ListReorderSuggestions(c *gin.Context, params generated.ListReorderSuggestionsParams) {
+	input := service.ReorderInput{
+		LeadTimeDays: params.LeadTimeDays,
+	}
+	if params.SupplierId != nil {
+		input.SupplierID = params.SupplierId.String()
+	}
+	if params.Search != nil {
+		input.Search = *params.Search
+	}
+	if params.IncludeAll != nil {
+		input.IncludeAll = *params.IncludeAll
+	}
+
+	suggestions, err := h.service.Suggestions(c.Request.Context(), input)
+	if err != nil {
+		respondReorderError(c, err, "Failed to compute reorder suggestions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedReorderSuggestions(suggestions),
+	})
+}
+
+func (h *ReorderHandler) CreateReorderPurchaseOrder(c *gin.Context) {
+	var req generated.CreateReorderPurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	input := service.ReorderPurchaseOrderInput{
+		SupplierID:   req.SupplierId,
+		LeadTimeDays: req.LeadTimeDays,
+		ExpectedDate: mapper.DatePtrToTimePtr(req.ExpectedDate),
+		Notes:        req.Notes,
+	}
+	if req.MedicineIds != nil {
+		for _, id := range *req.MedicineIds {
+			input.MedicineIDs = append(input.MedicineIDs, id.String())
+		}
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	order, err := h.service.CreatePurchaseOrder(ctx, input)
+	if err != nil {
+		respondReorderError(c, err, "Failed to create purchase order")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedPurchaseOrder(order),
+	})
+}
+
+func respondReorderError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Supplier not found"})
+	case errors.Is(err, service.ErrInvalidReorderRequest), errors.Is(err, service.ErrInvalidPurchaseOrder):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrNothingToReorder):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"backend/internal/exports"
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type ReportHandler struct {
+	service service.ReportService
+}
+
+func NewReportHandler(service service.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+func (h *ReportHandler) GetDiagnosisReport(c *gin.Context, params generated.GetDiagnosisReportParams) {
+	filter := repository.DiagnosisReportFilter{
+		VisitDateFrom: mapper.DatePtrToTimePtr(params.VisitDateFrom),
+		VisitDateTo:   mapper.DatePtrToTimePtr(params.VisitDateTo),
+	}
+	if params.Code != nil {
+		filter.Code = *params.Code
+	}
+	if params.GroupBy != nil {
+		filter.ByCategory = *params.GroupBy == generated.GetDiagnosisReportParamsGroupByCategory
+	}
+	if params.PrimaryOnly != nil {
+		filter.PrimaryOnly = *params.PrimaryOnly
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+
+	rows, err := h.service.DiagnosisReport(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReportPeriod) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build diagnosis report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedDiagnosisReportRows(rows),
+	})
+}
+
+func (h *ReportHandler) GetMedicineUsageReport(c *gin.Context, params generated.GetMedicineUsageReportParams) {
+	input := medicineUsageInput(params.DateFrom, params.DateTo, params.Period, params.BySource, params.RankBy, params.Limit, params.MedicineId)
+
+	rows, err := h.service.MedicineUsageReport(c.Request.Context(), input)
+	if err != nil {
+		if isReportInputError(err) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build medicine usage report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedMedicineUsageRows(rows),
+	})
+}
+
+func (h *ReportHandler) ExportMedicineUsageReport(c *gin.Context, params generated.ExportMedicineUsageReportParams) {
+	format := exportFormat(params.Format)
+	input := medicineUsageInput(params.DateFrom, params.DateTo, params.Period, params.BySource, params.RankBy, params.Limit, params.MedicineId)
+
+	fileName := fmt.Sprintf("medicine-usage-%s.%s", time.Now().Format("20060102"), format)
+	err := streamExport(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportMedicineUsage(c.Request.Context(), input, format, w)
+	})
+	h.exportError(c, err, "Failed to export medicine usage report")
+}
+
+func (h *ReportHandler) ExportStockActivityLog(c *gin.Context, params generated.ExportStockActivityLogParams) {
+	format := exportFormat(params.Format)
+	input := service.StockActivityLogInput{
+		DateFrom: mapper.DatePtrToTimePtr(params.DateFrom),
+		DateTo:   mapper.DatePtrToTimePtr(params.DateTo),
+	}
+	if params.MedicineId != nil {
+		input.MedicineID = params.MedicineId.String()
+	}
+
+	fileName := fmt.Sprintf("stock-activities-%s.%s", time.Now().Format("20060102"), format)
+	err := streamExport(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportStockActivities(c.Request.Context(), input, format, w)
+	})
+	h.exportError(c, err, "Failed to export stock activities")
+}
+
+func (h *ReportHandler) GetOutstandingPurchaseOrderReport(c *gin.Context, params generated.GetOutstandingPurchaseOrderReportParams) {
+	var supplierID string
+	if params.SupplierId != nil {
+		supplierID = params.SupplierId.String()
+	}
+	overdueOnly := params.OverdueOnly != nil && *params.OverdueOnly
+
+	rows, err := h.service.OutstandingPurchaseOrders(c.Request.Context(), supplierID, overdueOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build outstanding purchase order report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedOutstandingPurchaseOrderRows(rows),
+	})
+}
+
+func (h *ReportHandler) GetSupplierLeadTimeReport(c *gin.Context, params generated.GetSupplierLeadTimeReportParams) {
+	input := service.SupplierLeadTimeInput{
+		DateFrom: mapper.DatePtrToTimePtr(params.DateFrom),
+		DateTo:   mapper.DatePtrToTimePtr(params.DateTo),
+	}
+	if params.SupplierId != nil {
+		input.SupplierID = params.SupplierId.String()
+	}
+
+	rows, err := h.service.SupplierLeadTimes(c.Request.Context(), input)
+	if err != nil {
+		if isReportInputError(err) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build supplier lead time report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedSupplierLeadTimeRows(rows),
+	})
+}
+
+func (h *ReportHandler) GetInventoryValuationReport(c *gin.Context, params generated.GetInventoryValuationReportParams) {
+	input := inventoryValuationInput(params.AsOf, params.ByBatch, params.MedicineId)
+
+	valuation, err := h.service.InventoryValuation(c.Request.Context(), input)
+	if err != nil {
+		if isReportInputError(err) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build inventory valuation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedInventoryValuation(valuation),
+	})
+}
+
+func (h *ReportHandler) ExportInventoryValuationReport(c *gin.Context, params generated.ExportInventoryValuationReportParams) {
+	format := exportFormat(params.Format)
+	input := inventoryValuationInput(params.AsOf, params.ByBatch, params.MedicineId)
+
+	fileName := fmt.Sprintf("inventory-valuation-%s.%s", time.Now().Format("20060102"), format)
+	err := streamExport(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportInventoryValuation(c.Request.Context(), input, format, w)
+	})
+	h.exportError(c, err, "Failed to export inventory valuation")
+}
+
+func (h *ReportHandler) GetCostOfGoodsDispensedReport(c *gin.Context, params generated.GetCostOfGoodsDispensedReportParams) {
+	input := costOfGoodsDispensedInput(params.DateFrom, params.DateTo, params.Period, params.GroupBy, params.MedicineId)
+
+	report, err := h.service.CostOfGoodsDispensed(c.Request.Context(), input)
+	if err != nil {
+		if isReportInputError(err) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build cost of goods dispensed report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedCostOfGoodsDispensed(report),
+	})
+}
+
+func (h *ReportHandler) ExportCostOfGoodsDispensedReport(c *gin.Context, params generated.ExportCostOfGoodsDispensedReportParams) {
+	format := exportFormat(params.Format)
+	input := costOfGoodsDispensedInput(params.DateFrom, params.DateTo, params.Period, params.GroupBy, params.MedicineId)
+
+	fileName := fmt.Sprintf("cost-of-goods-dispensed-%s.%s", time.Now().Format("20060102"), format)
+	err := streamExport(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportCostOfGoodsDispensed(c.Request.Context(), input, format, w)
+	})
+	h.exportError(c, err, "Failed to export cost of goods dispensed report")
+}
+
+func (h *ReportHandler) exportError(c *gin.Context, err error, message string) {
+	if err == nil {
+		return
+	}
+	if isReportInputError(err) {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, generated.Error{
+		Message: message,
+	})
+}
+
+func isReportInputError(err error) bool {
+	return errors.Is(err, service.ErrInvalidReportRange) ||
+		errors.Is(err, service.ErrInvalidUsageReport) ||
+		errors.Is(err, service.ErrInvalidCostReport) ||
+		errors.Is(err, service.ErrInvalidValuationDate) ||
+		errors.Is(err, exports.ErrUnknownFormat)
+}
+
+// medicineUsageInput collects the parameters shared by the medicine usage
+// report and its export.
+func medicineUsageInput[P, R ~string](
+	dateFrom, dateTo *openapi_types.Date,
+	period *P,
+	bySource *bool,
+	rankBy *R,
+	limit *int,
+	medicineID *openapi_types.UUID,
+) service.MedicineUsageInput {
+	input := service.MedicineUsageInput{
+		DateFrom: mapper.DatePtrToTimePtr(dateFrom),
+		DateTo:   mapper.DatePtrToTimePtr(dateTo),
+	}
+	if period != nil {
+		input.Period = string(*period)
+	}
+	if bySource != nil {
+		input.BySource = *bySource
+	}
+	if rankBy != nil {
+		input.RankBy = string(*rankBy)
+	}
+	if limit != nil {
+		input.Limit = *limit
+	}
+	if medicineID != nil {
+		input.MedicineID = medicineID.String()
+	}
+	return input
+}
+
+// inventoryValuationInput collects the parameters shared by the inventory
+// valuation and its export.
+func inventoryValuationInput(asOf *openapi_types.Date, byBatch *bool, medicineID *openapi_types.UUID) service.InventoryValuationInput {
+	input := service.InventoryValuationInput{
+		AsOf: mapper.DatePtrToTimePtr(asOf),
+	}
+	if byBatch != nil {
+		input.ByBatch = *byBatch
+	}
+	if medicineID != nil {
+		input.MedicineID = medicineID.String()
+	}
+	return input
+}
+
+// costOfGoodsDispensedInput collects the parameters shared by the cost of
+// goods dispensed report and its export.
+func costOfGoodsDispensedInput[P, G ~string](
+	dateFrom, dateTo *openapi_types.Date,
+	period *P,
+	groupBy *G,
+	medicineID *openapi_types.UUID,
+) service.CostOfGoodsDispensedInput {
+	input := service.CostOfGoodsDispensedInput{
+		DateFrom: mapper.DatePtrToTimePtr(dateFrom),
+		DateTo:   mapper.DatePtrToTimePtr(dateTo),
+	}
+	if period != nil {
+		input.Period = string(*period)
+	}
+	if groupBy != nil {
+		input.GroupBy = string(*groupBy)
+	}
+	if medicineID != nil {
+		input.MedicineID = medicineID.String()
+	}
+	return input
+}
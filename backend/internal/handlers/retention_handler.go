@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	service service.RetentionService
+}
+
+func NewRetentionHandler(service service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: service}
+}
+
+func (h *RetentionHandler) GetRetentionReport(c *gin.Context) {
+	candidates, err := h.service.DryRun(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to build retention report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedRetentionCandidates(candidates),
+	})
+}
+
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+
+	audits, err := h.service.Run(ctx, service.RetentionTriggerAPI)
+	if err != nil && len(audits) == 0 {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to run retention policy",
+		})
+		return
+	}
+
+	// Partial failures still report the erasures that were committed.
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientErasureAudits(audits),
+	})
+}
+
+func (h *RetentionHandler) ListPatientErasures(c *gin.Context, params generated.ListPatientErasuresParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	audits, total, err := h.service.ListErasures(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch erasure records",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedPatientErasureAudits(audits),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/models"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ScheduleHandler struct {
+	service service.ScheduleService
+}
+
+func NewScheduleHandler(service service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service: service}
+}
+
+func (h *ScheduleHandler) GetDoctorSchedule(c *gin.Context, id generated.IdParam) {
+	schedules, err := h.service.GetDoctorSchedule(c.Request.Context(), uuid.UUID(id).String())
+	if err != nil {
+		if errors.Is(err, service.ErrNotADoctor) {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Doctor not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch doctor schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedDoctorSchedules(schedules),
+	})
+}
+
+func (h *ScheduleHandler) ReplaceDoctorSchedule(c *gin.Context, id generated.IdParam) {
+	var req generated.ReplaceDoctorScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	schedules, err := h.service.ReplaceDoctorSchedule(c.Request.Context(), uuid.UUID(id).String(), mapper.ToModelDoctorSchedules(req.Entries))
+	if err != nil {
+		if errors.Is(err, service.ErrNotADoctor) {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Doctor not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to update doctor schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedDoctorSchedules(schedules),
+	})
+}
+
+func (h *ScheduleHandler) ListClinicHolidays(c *gin.Context, params generated.ListClinicHolidaysParams) {
+	holidays, err := h.service.ListHolidays(
+		c.Request.Context(),
+		mapper.DatePtrToTimePtr(params.DateFrom),
+		mapper.DatePtrToTimePtr(params.DateTo),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch clinic holidays",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedClinicHolidays(holidays),
+	})
+}
+
+func (h *ScheduleHandler) CreateClinicHoliday(c *gin.Context) {
+	var req generated.CreateClinicHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	holiday := &models.ClinicHoliday{
+		Date: req.Date.Time,
+		Name: req.Name,
+	}
+	if err := h.service.CreateHoliday(c.Request.Context(), holiday); err != nil {
+		if errors.Is(err, service.ErrHolidayExists) {
+			c.JSON(http.StatusConflict, generated.Error{
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to create clinic holiday",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedClinicHoliday(holiday),
+	})
+}
+
+func (h *ScheduleHandler) DeleteClinicHoliday(c *gin.Context, id generated.IdParam) {
+	if err := h.service.DeleteHoliday(c.Request.Context(), id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{
+				Message: "Clinic holiday not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to delete clinic holiday",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
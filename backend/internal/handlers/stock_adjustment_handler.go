@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StockAdjustmentHandler struct {
+	service service.StockAdjustmentService
+}
+
+func NewStockAdjustmentHandler(service service.StockAdjustmentService) *StockAdjustmentHandler {
+	return &StockAdjustmentHandler{service: service}
+}
+
+func (h *StockAdjustmentHandler) ListStockAdjustments(c *gin.Context, params generated.ListStockAdjustmentsParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	filter := repository.StockAdjustmentFilter{}
+	if params.Status != nil {
+		filter.Status = string(*params.Status)
+	}
+	if params.Type != nil {
+		filter.Type = string(*params.Type)
+	}
+	if params.MedicineId != nil {
+		filter.MedicineID = params.MedicineId.String()
+	}
+	if params.MedicineBatchId != nil {
+		filter.BatchID = params.MedicineBatchId.String()
+	}
+
+	adjustments, total, err := h.service.ListAdjustments(c.Request.Context(), page, perPage, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch stock adjustments",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockAdjustments(adjustments),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *StockAdjustmentHandler) CreateStockAdjustment(c *gin.Context) {
+	var req generated.CreateStockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	adjustment, err := h.service.CreateAdjustment(ctx, service.StockAdjustmentInput{
+		MedicineBatchID: req.MedicineBatchId,
+		Type:            string(req.Type),
+		ReasonCode:      req.ReasonCode,
+		Justification:   req.Justification,
+		QuantityDelta:   req.QuantityDelta,
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, generated.Error{Message: "Medicine batch not found"})
+			return
+		}
+		respondStockAdjustmentError(c, err, "Failed to create stock adjustment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedStockAdjustment(adjustment),
+	})
+}
+
+func (h *StockAdjustmentHandler) GetStockAdjustment(c *gin.Context, id generated.IdParam) {
+	adjustment, err := h.service.GetAdjustment(c.Request.Context(), id)
+	if err != nil {
+		respondStockAdjustmentError(c, err, "Failed to fetch stock adjustment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockAdjustment(adjustment),
+	})
+}
+
+func (h *StockAdjustmentHandler) ApproveStockAdjustment(c *gin.Context, id generated.IdParam) {
+	req, ok := bindReviewStockAdjustmentRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	adjustment, err := h.service.ApproveAdjustment(ctx, id, req.Notes)
+	if err != nil {
+		respondStockAdjustmentError(c, err, "Failed to approve stock adjustment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockAdjustment(adjustment),
+	})
+}
+
+func (h *StockAdjustmentHandler) RejectStockAdjustment(c *gin.Context, id generated.IdParam) {
+	req, ok := bindReviewStockAdjustmentRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	adjustment, err := h.service.RejectAdjustment(ctx, id, req.Notes)
+	if err != nil {
+		respondStockAdjustmentError(c, err, "Failed to reject stock adjustment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockAdjustment(adjustment),
+	})
+}
+
+func bindReviewStockAdjustmentRequest(c *gin.Context) (generated.ReviewStockAdjustmentRequest, bool) {
+	var req generated.ReviewStockAdjustmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, generated.Error{
+				Message: "Invalid request body",
+			})
+			return req, false
+		}
+	}
+	return req, true
+}
+
+func respondStockAdjustmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Stock adjustment not found"})
+	case errors.Is(err, service.ErrInvalidStockAdjustment):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrAdjustmentExceedsStock),
+		errors.Is(err, service.ErrAdjustmentNotPending):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
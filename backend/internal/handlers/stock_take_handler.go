@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"backend/internal/exports"
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/service"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StockTakeHandler struct {
+	service service.StockTakeService
+}
+
+func NewStockTakeHandler(service service.StockTakeService) *StockTakeHandler {
+	return &StockTakeHandler{service: service}
+}
+
+func (h *StockTakeHandler) ListStockTakes(c *gin.Context, params generated.ListStockTakesParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+	status := ""
+	if params.Status != nil {
+		status = string(*params.Status)
+	}
+
+	stockTakes, total, err := h.service.ListStockTakes(c.Request.Context(), page, perPage, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, generated.Error{
+			Message: "Failed to fetch stock takes",
+		})
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTakes(stockTakes),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *StockTakeHandler) OpenStockTake(c *gin.Context) {
+	var req generated.OpenStockTakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	input := service.OpenStockTakeInput{
+		Name:  req.Name,
+		Notes: req.Notes,
+	}
+	if req.FreezeDispensing != nil {
+		input.FreezeDispensing = *req.FreezeDispensing
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	stockTake, err := h.service.OpenStockTake(ctx, input)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to open stock take")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedStockTake(stockTake),
+	})
+}
+
+func (h *StockTakeHandler) GetStockTake(c *gin.Context, id generated.IdParam) {
+	stockTake, err := h.service.GetStockTake(c.Request.Context(), id)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to fetch stock take")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTake(stockTake),
+	})
+}
+
+func (h *StockTakeHandler) ListStockTakeLines(c *gin.Context, id generated.IdParam) {
+	lines, err := h.service.ListLines(c.Request.Context(), id)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to fetch stock take lines")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTakeLines(lines),
+	})
+}
+
+func (h *StockTakeHandler) RecordStockCounts(c *gin.Context, id generated.IdParam) {
+	var req generated.RecordStockCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	counts := make([]service.StockCountInput, len(req.Counts))
+	for i, count := range req.Counts {
+		counts[i] = service.StockCountInput{
+			MedicineBatchID: count.MedicineBatchId,
+			CountedQuantity: count.CountedQuantity,
+			Notes:           count.Notes,
+		}
+	}
+
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	lines, err := h.service.RecordCounts(ctx, id, counts)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to record stock counts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTakeLines(lines),
+	})
+}
+
+func (h *StockTakeHandler) ApproveStockTake(c *gin.Context, id generated.IdParam) {
+	ctx := service.WithActorUserID(c.Request.Context(), c.GetString("user_id"))
+	stockTake, err := h.service.ApproveStockTake(ctx, id)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to approve stock take")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTake(stockTake),
+	})
+}
+
+func (h *StockTakeHandler) CancelStockTake(c *gin.Context, id generated.IdParam) {
+	stockTake, err := h.service.CancelStockTake(c.Request.Context(), id)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to cancel stock take")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTake(stockTake),
+	})
+}
+
+func (h *StockTakeHandler) PrintStockTakeCountSheet(c *gin.Context, id generated.IdParam) {
+	stockTake, pdf, err := h.service.CountSheet(c.Request.Context(), id)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to print count sheet")
+		return
+	}
+
+	filename := fmt.Sprintf("count-sheet-%s.pdf", stockTake.CreatedAt.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (h *StockTakeHandler) GetStockTakeVariance(c *gin.Context, id generated.IdParam) {
+	summary, lines, err := h.service.VarianceReport(c.Request.Context(), id)
+	if err != nil {
+		respondStockTakeError(c, err, "Failed to build variance report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedStockTakeVarianceReport(summary, lines),
+	})
+}
+
+func (h *StockTakeHandler) ExportStockTakeVariance(c *gin.Context, id generated.IdParam, params generated.ExportStockTakeVarianceParams) {
+	format := exportFormat(params.Format)
+
+	fileName := fmt.Sprintf("stock-take-variance-%s.%s", time.Now().Format("20060102"), format)
+	err := streamExport(c, format, fileName, func(w io.Writer) error {
+		return h.service.ExportVariance(c.Request.Context(), id, format, w)
+	})
+	if err == nil {
+		return
+	}
+	if errors.Is(err, exports.ErrUnknownFormat) {
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+		return
+	}
+	respondStockTakeError(c, err, "Failed to export variance report")
+}
+
+func respondStockTakeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Stock take not found"})
+	case errors.Is(err, service.ErrInvalidStockTake),
+		errors.Is(err, service.ErrInvalidStockAdjustment):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, generated.Error{Message: "stock takes must be approved by a known user other than the one who opened them or counted them"})
+	case errors.Is(err, service.ErrStockTakeInProgress),
+		errors.Is(err, service.ErrStockTakeNotOpen),
+		errors.Is(err, service.ErrAdjustmentExceedsStock):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"backend/internal/generated"
+	"backend/internal/handlers/mapper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SupplierHandler struct {
+	service service.SupplierService
+}
+
+func NewSupplierHandler(service service.SupplierService) *SupplierHandler {
+	return &SupplierHandler{service: service}
+}
+
+func (h *SupplierHandler) ListSuppliers(c *gin.Context, params generated.ListSuppliersParams) {
+	page := 1
+	perPage := 10
+	if params.Page != nil {
+		page = *params.Page
+	}
+	if params.PerPage != nil {
+		perPage = *params.PerPage
+	}
+
+	filter := repository.SupplierFilter{IsActive: params.IsActive}
+	if params.Search != nil {
+		filter.Search = *params.Search
+	}
+
+	suppliers, total, err := h.service.ListSuppliers(c.Request.Context(), page, perPage, filter)
+	if err != nil {
+		respondSupplierError(c, err, "Failed to fetch suppliers")
+		return
+	}
+
+	totalInt := int(total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedSuppliers(suppliers),
+		"meta": generated.Meta{
+			Page:    &page,
+			PerPage: &perPage,
+			Total:   &totalInt,
+		},
+	})
+}
+
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req generated.CreateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	supplier := mapper.ToModelSupplier(req)
+	if err := h.service.CreateSupplier(c.Request.Context(), supplier); err != nil {
+		respondSupplierError(c, err, "Failed to create supplier")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": mapper.ToGeneratedSupplier(supplier),
+	})
+}
+
+func (h *SupplierHandler) GetSupplier(c *gin.Context, id generated.IdParam) {
+	supplier, err := h.service.GetSupplier(c.Request.Context(), id)
+	if err != nil {
+		respondSupplierError(c, err, "Failed to fetch supplier")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedSupplier(supplier),
+	})
+}
+
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context, id generated.IdParam) {
+	var req generated.UpdateSupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, generated.Error{
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	supplier := mapper.ToModelSupplierUpdate(req)
+	if err := h.service.UpdateSupplier(c.Request.Context(), id, supplier); err != nil {
+		respondSupplierError(c, err, "Failed to update supplier")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapper.ToGeneratedSupplier(supplier),
+	})
+}
+
+func respondSupplierError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, generated.Error{Message: "Supplier not found"})
+	case errors.Is(err, service.ErrInvalidSupplier):
+		c.JSON(http.StatusBadRequest, generated.Error{Message: err.Error()})
+	case errors.Is(err, service.ErrSupplierCodeTaken):
+		c.JSON(http.StatusConflict, generated.Error{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, generated.Error{Message: fallback})
+	}
+}
@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// NewBatchExpiryJob marks expired batches and raises near-expiry alerts
+func NewBatchExpiryJob(batchExpiryService service.BatchExpiryService, interval time.Duration) Job {
+	return Job{
+		Name:     "batch-expiry",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			run, err := batchExpiryService.Run(ctx)
+			if run.ExpiredBatches > 0 {
+				log.Printf("Batch expiry: expired %d batch(es) holding %d unit(s)", run.ExpiredBatches, run.ExpiredQuantity)
+			}
+			if len(run.Alerts) > 0 {
+				log.Printf("Batch expiry: raised %d near-expiry alert(s)", len(run.Alerts))
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// NewFollowUpReminderJob reminds patients of upcoming follow-up visits
+func NewFollowUpReminderJob(followUpService service.FollowUpService, interval time.Duration) Job {
+	return Job{
+		Name:     "follow-up-reminders",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			sent, err := followUpService.SendReminders(ctx)
+			if sent > 0 {
+				log.Printf("Follow-up reminders: sent %d reminder(s)", sent)
+			}
+			return err
+		},
+	}
+}
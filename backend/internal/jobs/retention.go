@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"backend/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// NewRetentionJob anonymizes patients whose retention period has elapsed
+func NewRetentionJob(retentionService service.RetentionService, interval time.Duration) Job {
+	return Job{
+		Name:     "patient-retention",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			audits, err := retentionService.Run(ctx, service.RetentionTriggerScheduler)
+			if len(audits) > 0 {
+				log.Printf("Retention: anonymized %d patient(s)", len(audits))
+			}
+			return err
+		},
+	}
+}
@@ -21,7 +21,10 @@ type bodyLogWriter struct {
 }
 
 func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	// Event streams stay open for hours; keeping their body would grow without bound.
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		w.body.Write(b)
+	}
 	return w.ResponseWriter.Write(b)
 }
 
@@ -28,6 +28,9 @@ func OpenAPISecurityMiddleware() gin.HandlerFunc {
 
 		// Protected endpoint - validate JWT
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			authHeader = eventStreamAuthorization(c)
+		}
 		if authHeader == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, generated.Error{
 				Message: "authorization required",
@@ -76,6 +79,25 @@ func OpenAPISecurityMiddleware() gin.HandlerFunc {
 	}
 }
 
+// eventStreamAuthorization reads the token of an event stream request from
+// the access_token query parameter, because browsers cannot set headers on an
+// EventSource. The parameter is removed so it does not end up in access logs.
+func eventStreamAuthorization(c *gin.Context) string {
+	if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		return ""
+	}
+
+	query := c.Request.URL.Query()
+	token := query.Get("access_token")
+	if token == "" {
+		return ""
+	}
+
+	query.Del("access_token")
+	c.Request.URL.RawQuery = query.Encode()
+	return "Bearer " + token
+}
+
 // getRouteSecurityInfo retrieves security info from generated.RouteSecurity
 func getRouteSecurityInfo(path, method string) generated.RouteSecurityInfo {
 	// Check exact path match
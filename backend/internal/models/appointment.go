@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+const (
+	AppointmentStatusBooked    = "booked"
+	AppointmentStatusCheckedIn = "checked_in"
+	AppointmentStatusCancelled = "cancelled"
+	AppointmentStatusNoShow    = "no_show"
+)
+
+// Appointment reserves one slot of a doctor's working hours for a patient.
+// Checking in on arrival turns it into an in-progress PatientCheckup.
+type Appointment struct {
+	BaseUUID
+
+	PatientID string  `gorm:"type:uuid;not null;index" json:"patient_id"`
+	Patient   Patient `gorm:"foreignKey:PatientID" json:"patient"`
+	DoctorID  string  `gorm:"type:uuid;not null;index:idx_appointments_doctor_start" json:"doctor_id"`
+	Doctor    User    `gorm:"foreignKey:DoctorID" json:"doctor"`
+
+	StartAt time.Time `gorm:"not null;index:idx_appointments_doctor_start" json:"start_at"`
+	EndAt   time.Time `gorm:"not null" json:"end_at"`
+	Status  string    `gorm:"type:varchar(20);not null;default:'booked';index" json:"status"` // booked, checked_in, cancelled, no_show
+	Reason  *string   `gorm:"type:text" json:"reason,omitempty"`
+	Notes   *string   `gorm:"type:text" json:"notes,omitempty"`
+
+	PatientCheckupID   *string    `gorm:"type:uuid;index" json:"patient_checkup_id,omitempty"`
+	CheckedInAt        *time.Time `json:"checked_in_at,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason *string    `gorm:"type:text" json:"cancellation_reason,omitempty"`
+	CreatedByUserID    *string    `gorm:"type:uuid" json:"created_by_user_id,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (Appointment) TableName() string {
+	return "appointments"
+}
+
+// IsActive reports whether the appointment still occupies its slot.
+func (a *Appointment) IsActive() bool {
+	return a.Status == AppointmentStatusBooked || a.Status == AppointmentStatusCheckedIn
+}
+
+// DoctorSchedule is one block of weekly working hours. A doctor may have
+// several blocks on the same weekday, e.g. a morning and an afternoon session.
+type DoctorSchedule struct {
+	BaseUUID
+
+	DoctorID    string `gorm:"type:uuid;not null;index" json:"doctor_id"`
+	Doctor      User   `gorm:"foreignKey:DoctorID;constraint:OnDelete:CASCADE" json:"-"`
+	Weekday     int    `gorm:"not null;check:weekday >= 0 AND weekday <= 6" json:"weekday"` // 0 = Sunday
+	StartTime   string `gorm:"type:varchar(5);not null" json:"start_time"`                  // HH:MM, clinic time zone
+	EndTime     string `gorm:"type:varchar(5);not null" json:"end_time"`
+	SlotMinutes int    `gorm:"not null;check:slot_minutes > 0" json:"slot_minutes"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (DoctorSchedule) TableName() string {
+	return "doctor_schedules"
+}
+
+// ClinicHoliday closes the clinic for a whole day; no slots are offered.
+type ClinicHoliday struct {
+	BaseUUID
+
+	Date time.Time `gorm:"type:date;not null;uniqueIndex" json:"date"`
+	Name string    `gorm:"type:varchar(255);not null" json:"name"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (ClinicHoliday) TableName() string {
+	return "clinic_holidays"
+}
+
+// AvailabilitySlot is one bookable slot in a doctor's calendar.
+type AvailabilitySlot struct {
+	DoctorID   string    `json:"doctor_id"`
+	DoctorName string    `json:"doctor_name"`
+	StartAt    time.Time `json:"start_at"`
+	EndAt      time.Time `json:"end_at"`
+	Available  bool      `json:"available"`
+}
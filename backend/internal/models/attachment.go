@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	BaseUUID
+
+	OwnerType string `gorm:"type:varchar(30);not null;index:idx_attachment_owner" json:"owner_type"` // patient, patient_checkup
+	OwnerID   string `gorm:"type:uuid;not null;index:idx_attachment_owner" json:"owner_id"`
+	PatientID string `gorm:"type:uuid;not null;index" json:"patient_id"`
+
+	Category    string  `gorm:"type:varchar(30);not null;default:'document'" json:"category"` // document, lab_result, photo, other
+	FileName    string  `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType string  `gorm:"type:varchar(100);not null" json:"content_type"`
+	SizeBytes   int64   `gorm:"not null;check:size_bytes >= 0" json:"size_bytes"`
+	Checksum    string  `gorm:"type:varchar(64);not null" json:"checksum"` // sha256 hex
+	StorageKey  string  `gorm:"type:varchar(500);not null;uniqueIndex" json:"-"`
+	Description *string `gorm:"type:text" json:"description,omitempty"`
+
+	UploadedByUserID *string `gorm:"type:uuid;index" json:"uploaded_by_user_id,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// BatchExpiryAlert records that a batch came within HorizonDays of its
+// expiration date. A batch is alerted at most once per horizon.
+type BatchExpiryAlert struct {
+	BaseUUID
+
+	MedicineID      string        `gorm:"type:uuid;not null;index" json:"medicine_id"`
+	Medicine        Medicine      `gorm:"foreignKey:MedicineID" json:"medicine"`
+	MedicineBatchID string        `gorm:"type:uuid;not null;uniqueIndex:uq_batch_expiry_alert" json:"medicine_batch_id"`
+	MedicineBatch   MedicineBatch `gorm:"foreignKey:MedicineBatchID;constraint:OnDelete:CASCADE" json:"medicine_batch"`
+	HorizonDays     int           `gorm:"not null;uniqueIndex:uq_batch_expiry_alert" json:"horizon_days"`
+
+	// DaysLeft and Quantity are taken when the alert is raised.
+	ExpirationDate time.Time `gorm:"type:date;not null" json:"expiration_date"`
+	DaysLeft       int       `gorm:"not null" json:"days_left"`
+	Quantity       int       `gorm:"not null" json:"quantity"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (BatchExpiryAlert) TableName() string {
+	return "batch_expiry_alerts"
+}
+
+// BatchExpiryRun summarizes one pass of the expiry job.
+type BatchExpiryRun struct {
+	ExpiredBatches  int
+	ExpiredQuantity int
+	Alerts          []BatchExpiryAlert
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	CheckupDocumentSummary      = "summary"
+	CheckupDocumentPrescription = "prescription"
+	CheckupDocumentSickNote     = "sick_note"
+)
+
+// CheckupDocument records a printed document of a checkup. The PDF itself is
+// rendered on demand; the record backs its verification QR code.
+type CheckupDocument struct {
+	BaseUUID
+
+	PatientCheckupID string         `gorm:"type:uuid;not null;index" json:"patient_checkup_id"`
+	PatientCheckup   PatientCheckup `gorm:"foreignKey:PatientCheckupID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Type             string     `gorm:"type:varchar(20);not null" json:"type"` // summary, prescription, sick_note
+	VerificationCode string     `gorm:"type:varchar(32);not null;uniqueIndex" json:"verification_code"`
+	RestDays         *int       `gorm:"check:rest_days > 0" json:"rest_days,omitempty"` // sick notes only
+	RestFrom         *time.Time `gorm:"type:date" json:"rest_from,omitempty"`
+	RestTo           *time.Time `gorm:"type:date" json:"rest_to,omitempty"`
+	IssuedByUserID   *string    `gorm:"type:uuid;index" json:"issued_by_user_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (CheckupDocument) TableName() string {
+	return "checkup_documents"
+}
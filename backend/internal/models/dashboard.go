@@ -32,9 +32,11 @@ type RecentCheckup struct {
 }
 
 type CheckupStatusSummary struct {
-	Scheduled int64 `json:"scheduled"`
-	Completed int64 `json:"completed"`
-	Cancelled int64 `json:"cancelled"`
+	Scheduled  int64 `json:"scheduled"`
+	InProgress int64 `json:"in_progress"`
+	Completed  int64 `json:"completed"`
+	Cancelled  int64 `json:"cancelled"`
+	NoShow     int64 `json:"no_show"`
 }
 
 type PatientTypeStat struct {
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	DisposalMethodIncineration         = "incineration"
+	DisposalMethodEncapsulation        = "encapsulation"
+	DisposalMethodChemicalInactivation = "chemical_inactivation"
+	DisposalMethodReturnToSupplier     = "return_to_supplier"
+	DisposalMethodWasteContractor      = "waste_contractor"
+	DisposalMethodOther                = "other"
+
+	DisposalReasonExpired  = "expired"
+	DisposalReasonDamaged  = "damaged"
+	DisposalReasonRecalled = "recalled"
+)
+
+// Disposal records the destruction of whole batches, as presented to
+// inspectors. Number is sequential per year of DisposedAt.
+type Disposal struct {
+	BaseUUID
+
+	Number            string    `gorm:"type:varchar(30);not null;uniqueIndex" json:"number"`
+	Method            string    `gorm:"type:varchar(30);not null;index" json:"method"`
+	WitnessName       string    `gorm:"type:varchar(255);not null" json:"witness_name"`
+	WitnessPosition   *string   `gorm:"type:varchar(100)" json:"witness_position,omitempty"`
+	ReferenceDocument string    `gorm:"type:varchar(100);not null" json:"reference_document"`
+	Notes             *string   `gorm:"type:text" json:"notes,omitempty"`
+	DisposedAt        time.Time `gorm:"not null;index" json:"disposed_at"`
+
+	DisposedByUserID *string `gorm:"type:uuid;index" json:"disposed_by_user_id,omitempty"`
+	DisposedBy       *User   `gorm:"foreignKey:DisposedByUserID;constraint:OnDelete:SET NULL" json:"-"`
+
+	Items []DisposalItem `gorm:"foreignKey:DisposalID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Disposal) TableName() string {
+	return "medicine_disposals"
+}
+
+// DisposalItem is one disposed batch. Quantity and UnitCost are what the
+// batch held when it was disposed of.
+type DisposalItem struct {
+	BaseUUID
+
+	DisposalID      string        `gorm:"type:uuid;not null;index" json:"disposal_id"`
+	MedicineID      string        `gorm:"type:uuid;not null;index" json:"medicine_id"`
+	Medicine        Medicine      `gorm:"foreignKey:MedicineID" json:"medicine"`
+	MedicineBatchID string        `gorm:"type:uuid;not null;index" json:"medicine_batch_id"`
+	MedicineBatch   MedicineBatch `gorm:"foreignKey:MedicineBatchID" json:"medicine_batch"`
+
+	Reason   string   `gorm:"type:varchar(20);not null" json:"reason"` // expired, damaged, recalled
+	Quantity int      `gorm:"not null;check:quantity > 0" json:"quantity"`
+	UnitCost *float64 `gorm:"type:decimal(15,2)" json:"unit_cost,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (DisposalItem) TableName() string {
+	return "medicine_disposal_items"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ICD10Code is an entry of the ICD-10 catalog, imported from a CSV file.
+type ICD10Code struct {
+	BaseUUID
+
+	Code     string `gorm:"type:varchar(10);not null;uniqueIndex" json:"code"` // e.g. J06.9
+	NameID   string `gorm:"type:text;not null" json:"name_id"`                 // Indonesian name
+	NameEN   string `gorm:"type:text;not null" json:"name_en"`                 // English name
+	IsActive bool   `gorm:"not null;default:true;index" json:"is_active"`      // inactive codes stay on old checkups but cannot be picked
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ICD10Code) TableName() string {
+	return "icd10_codes"
+}
+
+// NormalizeICD10Code uppercases a code and writes subcodes with a dot, so
+// "j069" and "J06.9" are stored and searched the same way.
+func NormalizeICD10Code(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) > 3 && !strings.Contains(code, ".") {
+		code = code[:3] + "." + code[3:]
+	}
+	return code
+}
@@ -7,7 +7,8 @@ type Medicine struct {
 
 	Name                   string  `gorm:"type:varchar(255);not null;index" json:"name"`
 	Code                   string  `gorm:"type:varchar(255);not null" json:"code"`
-	CurrentStock           int     `gorm:"not null;default:0;check:current_stock >= 0" json:"current_stock"`
+	CurrentStock           int     `gorm:"not null;default:0;check:current_stock >= 0" json:"current_stock"` // sellable, excludes expired batches
+	ExpiredStock           int     `gorm:"not null;default:0;check:expired_stock >= 0" json:"expired_stock"`
 	MinimumStock           int     `gorm:"not null;default:0;check:minimum_stock >= 0" json:"minimum_stock"`
 	DosageForm             string  `gorm:"type:varchar(50);not null" json:"dosage_form"` // tablet, capsule, syrup, injection, ointment
 	Strength               *string `gorm:"type:varchar(100)" json:"strength"`            // 500 mg, 250 mg/5 ml
@@ -9,11 +9,30 @@ type MedicineStockActivity struct {
 	MedicineBatchID  *string `gorm:"type:uuid;index" json:"medicine_batch_id,omitempty"`
 	PatientCheckupID *string `gorm:"type:uuid;index" json:"patient_checkup_id,omitempty"`
 
-	ChangeType      string  `gorm:"type:varchar(20);not null" json:"change_type"`  // increase,decrease
-	Source          string  `gorm:"type:varchar(30);not null;index" json:"source"` // admin,patient_checkup
-	QuantityDelta   int     `gorm:"not null" json:"quantity_delta"`
-	StockBefore     int     `gorm:"not null;check:stock_before >= 0" json:"stock_before"`
-	StockAfter      int     `gorm:"not null;check:stock_after >= 0" json:"stock_after"`
+	// StockAdjustmentID is set for adjustment activities and DisposalID for
+	// disposal activities; both carry a ReasonCode. GoodsReceiptID is set for
+	// purchase activities.
+	StockAdjustmentID *string `gorm:"type:uuid;index" json:"stock_adjustment_id,omitempty"`
+	DisposalID        *string `gorm:"type:uuid;index" json:"disposal_id,omitempty"`
+	GoodsReceiptID    *string `gorm:"type:uuid;index" json:"goods_receipt_id,omitempty"`
+	ReasonCode        *string `gorm:"type:varchar(40)" json:"reason_code,omitempty"`
+
+	// QuantityDelta moves the stock on hand, sellable and expired together,
+	// from StockBefore to StockAfter. ExpiredDelta is the part of the stock
+	// that went in or out of the expired stock; an expiring batch stays on
+	// hand and is logged with a zero QuantityDelta and a status ChangeType.
+	ChangeType    string `gorm:"type:varchar(20);not null" json:"change_type"`  // increase,decrease,status
+	Source        string `gorm:"type:varchar(30);not null;index" json:"source"` // admin,patient_checkup,adjustment,expiry,disposal,purchase
+	QuantityDelta int    `gorm:"not null" json:"quantity_delta"`
+	StockBefore   int    `gorm:"not null;check:stock_before >= 0" json:"stock_before"`
+	StockAfter    int    `gorm:"not null;check:stock_after >= 0" json:"stock_after"`
+	ExpiredDelta  int    `gorm:"not null;default:0" json:"expired_delta"`
+
+	// UnitCost and SellingPrice price the units a patient checkup dispensed
+	// or returned, as they stood when dispensed.
+	UnitCost     *float64 `gorm:"type:decimal(15,2)" json:"unit_cost,omitempty"`
+	SellingPrice *float64 `gorm:"type:decimal(15,2)" json:"selling_price,omitempty"`
+
 	Notes           *string `gorm:"type:text" json:"notes,omitempty"`
 	CreatedByUserID *string `gorm:"type:uuid;index" json:"created_by_user_id,omitempty"`
 
@@ -25,3 +44,34 @@ type MedicineStockActivity struct {
 func (MedicineStockActivity) TableName() string {
 	return "medicine_stock_activities"
 }
+
+// StockLedgerEntry is a stock activity as listed in the stock ledger, with
+// the names it refers to and totals running over the matching activities in
+// chronological order.
+type StockLedgerEntry struct {
+	ID                string    `json:"id"`
+	MedicineID        string    `json:"medicine_id"`
+	MedicineCode      string    `json:"medicine_code"`
+	MedicineName      string    `json:"medicine_name"`
+	MedicineBatchID   *string   `json:"medicine_batch_id"`
+	BatchNumber       *string   `json:"batch_number"`
+	PatientCheckupID  *string   `json:"patient_checkup_id"`
+	StockAdjustmentID *string   `json:"stock_adjustment_id"`
+	DisposalID        *string   `json:"disposal_id"`
+	GoodsReceiptID    *string   `json:"goods_receipt_id"`
+	ReasonCode        *string   `json:"reason_code"`
+	ChangeType        string    `json:"change_type"`
+	Source            string    `json:"source"`
+	QuantityDelta     int       `json:"quantity_delta"`
+	StockBefore       int       `json:"stock_before"`
+	StockAfter        int       `json:"stock_after"`
+	ExpiredDelta      int       `json:"expired_delta"`
+	Notes             *string   `json:"notes"`
+	CreatedByUserID   *string   `json:"created_by_user_id"`
+	CreatedByName     *string   `json:"created_by_name"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	RunningIncrease int64 `json:"running_increase"`
+	RunningDecrease int64 `json:"running_decrease"`
+	RunningNet      int64 `json:"running_net"`
+}
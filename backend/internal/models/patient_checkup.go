@@ -1,15 +1,28 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CheckupStatusScheduled  = "scheduled"
+	CheckupStatusInProgress = "in_progress"
+	CheckupStatusCompleted  = "completed"
+	CheckupStatusCancelled  = "cancelled"
+	CheckupStatusNoShow     = "no_show"
+)
 
 type PatientCheckupMedicine struct {
-	MedicineID   string  `json:"medicine_id"`
-	MedicineName string  `json:"medicine_name"`
-	Quantity     int     `json:"quantity"`
-	Dosage       string  `json:"dosage"`
-	Frequency    string  `json:"frequency"`
-	DurationDays int     `json:"duration_days"`
-	Notes        *string `json:"notes,omitempty"`
+	MedicineID        string  `json:"medicine_id"`
+	MedicineName      string  `json:"medicine_name"`
+	Quantity          int     `json:"quantity"`
+	DispensedQuantity int     `json:"dispensed_quantity"` // taken out of stock, never above Quantity
+	Dosage            string  `json:"dosage"`
+	Frequency         string  `json:"frequency"`
+	DurationDays      int     `json:"duration_days"`
+	Notes             *string `json:"notes,omitempty"`
 }
 
 type PatientCheckup struct {
@@ -19,23 +32,47 @@ type PatientCheckup struct {
 	Patient   Patient `gorm:"foreignKey:PatientID" json:"patient"`
 
 	VisitDate      time.Time `gorm:"not null;index" json:"visit_date"`
-	Status         string    `gorm:"type:varchar(20);not null;default:'scheduled';index" json:"status"` // scheduled, completed, cancelled
+	Status         string    `gorm:"type:varchar(20);not null;default:'scheduled';index" json:"status"` // scheduled, in_progress, completed, cancelled, no_show
 	ChiefComplaint string    `gorm:"type:text;not null" json:"chief_complaint"`
 	Symptoms       []string  `gorm:"type:jsonb;serializer:json;not null" json:"symptoms"`
 
-	Diagnosis        *string                  `gorm:"type:text" json:"diagnosis,omitempty"`
-	TemperatureC     *float64                 `gorm:"type:decimal(5,2)" json:"temperature_c,omitempty"`
-	BloodPressure    *string                  `gorm:"type:varchar(20)" json:"blood_pressure,omitempty"`
-	HeartRate        *int                     `gorm:"check:heart_rate >= 0" json:"heart_rate,omitempty"`
-	RespiratoryRate  *int                     `gorm:"check:respiratory_rate >= 0" json:"respiratory_rate,omitempty"`
-	OxygenSaturation *int                     `gorm:"check:oxygen_saturation >= 0 AND oxygen_saturation <= 100" json:"oxygen_saturation,omitempty"`
-	HeightCm         *float64                 `gorm:"type:decimal(6,2);check:height_cm >= 0" json:"height_cm,omitempty"`
-	WeightKg         *float64                 `gorm:"type:decimal(6,2);check:weight_kg >= 0" json:"weight_kg,omitempty"`
-	Medicines        []PatientCheckupMedicine `gorm:"type:jsonb;serializer:json" json:"medicines,omitempty"`
-	TreatmentPlan    *string                  `gorm:"type:text" json:"treatment_plan,omitempty"`
-	Notes            *string                  `gorm:"type:text" json:"notes,omitempty"`
-	DoctorName       *string                  `gorm:"type:varchar(255)" json:"doctor_name,omitempty"`
-	FollowUpDate     *time.Time               `gorm:"type:date" json:"follow_up_date,omitempty"`
+	Diagnosis              *string                  `gorm:"type:text" json:"diagnosis,omitempty"`
+	TemperatureC           *float64                 `gorm:"type:decimal(5,2)" json:"temperature_c,omitempty"`
+	BloodPressureSystolic  *int                     `gorm:"check:blood_pressure_systolic > 0" json:"blood_pressure_systolic,omitempty"`
+	BloodPressureDiastolic *int                     `gorm:"check:blood_pressure_diastolic > 0" json:"blood_pressure_diastolic,omitempty"`
+	HeartRate              *int                     `gorm:"check:heart_rate >= 0" json:"heart_rate,omitempty"`
+	RespiratoryRate        *int                     `gorm:"check:respiratory_rate >= 0" json:"respiratory_rate,omitempty"`
+	OxygenSaturation       *int                     `gorm:"check:oxygen_saturation >= 0 AND oxygen_saturation <= 100" json:"oxygen_saturation,omitempty"`
+	HeightCm               *float64                 `gorm:"type:decimal(6,2);check:height_cm >= 0" json:"height_cm,omitempty"`
+	WeightKg               *float64                 `gorm:"type:decimal(6,2);check:weight_kg >= 0" json:"weight_kg,omitempty"`
+	Medicines              []PatientCheckupMedicine `gorm:"type:jsonb;serializer:json" json:"medicines,omitempty"`
+	TreatmentPlan          *string                  `gorm:"type:text" json:"treatment_plan,omitempty"`
+	Notes                  *string                  `gorm:"type:text" json:"notes,omitempty"`
+	DoctorID               *string                  `gorm:"type:uuid;index" json:"doctor_id,omitempty"`
+	Doctor                 *User                    `gorm:"foreignKey:DoctorID;constraint:OnDelete:SET NULL" json:"-"`
+	DoctorName             *string                  `gorm:"type:varchar(255)" json:"doctor_name,omitempty"` // as printed; defaults to the doctor's account name
+	FollowUpDate           *time.Time               `gorm:"type:date;index" json:"follow_up_date,omitempty"`
+
+	// A follow-up is done once the patient comes back for a newer checkup.
+	FollowUpCompletedAt    *time.Time `json:"follow_up_completed_at,omitempty"`
+	FollowUpCheckupID      *string    `gorm:"type:uuid;index" json:"follow_up_checkup_id,omitempty"` // checkup that fulfilled the follow-up
+	FollowUpReminderSentAt *time.Time `json:"follow_up_reminder_sent_at,omitempty"`
+
+	// Coded diagnoses; Diagnosis above stays as the doctor's free-text note.
+	Diagnoses []PatientCheckupDiagnosis `gorm:"foreignKey:PatientCheckupID;constraint:OnDelete:CASCADE" json:"diagnoses,omitempty"`
+
+	// Abnormal vital flags, derived from the vitals whenever the checkup is saved.
+	Fever                 bool    `gorm:"not null;default:false;index" json:"fever"`
+	Hypoxia               bool    `gorm:"not null;default:false;index" json:"hypoxia"`
+	BloodPressureCategory *string `gorm:"type:varchar(30);index" json:"blood_pressure_category,omitempty"` // normal, elevated, hypertension_stage_1, hypertension_stage_2, hypertensive_crisis
+
+	StartedAt             *time.Time `json:"started_at,omitempty"`
+	CompletedAt           *time.Time `json:"completed_at,omitempty"`
+	StatusChangedAt       *time.Time `json:"status_changed_at,omitempty"`
+	StatusChangedByUserID *string    `gorm:"type:uuid" json:"status_changed_by_user_id,omitempty"`
+
+	DispensedAt       *time.Time `json:"dispensed_at,omitempty"`
+	DispensedByUserID *string    `gorm:"type:uuid" json:"dispensed_by_user_id,omitempty"`
 
 	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
@@ -45,3 +82,68 @@ type PatientCheckup struct {
 func (PatientCheckup) TableName() string {
 	return "patient_checkups"
 }
+
+// BeforeSave keeps the abnormal vital flags in line with the vitals.
+func (c *PatientCheckup) BeforeSave(tx *gorm.DB) error {
+	c.ApplyVitalFlags()
+	return nil
+}
+
+// ApplyVitalFlags derives Fever, Hypoxia and BloodPressureCategory from the
+// recorded vitals.
+func (c *PatientCheckup) ApplyVitalFlags() {
+	c.Fever = c.TemperatureC != nil && *c.TemperatureC >= FeverThresholdC
+	c.Hypoxia = c.OxygenSaturation != nil && *c.OxygenSaturation < HypoxiaThresholdO2
+
+	c.BloodPressureCategory = nil
+	if c.BloodPressureSystolic != nil && c.BloodPressureDiastolic != nil {
+		category := ClassifyBloodPressure(*c.BloodPressureSystolic, *c.BloodPressureDiastolic)
+		c.BloodPressureCategory = &category
+	}
+}
+
+// IsLocked reports whether the checkup reached a final status. Completed
+// checkups can then only change through an amendment.
+func (c *PatientCheckup) IsLocked() bool {
+	switch c.Status {
+	case CheckupStatusCompleted, CheckupStatusCancelled, CheckupStatusNoShow:
+		return true
+	}
+	return false
+}
+
+// PatientCheckupTransition records a single status change of a checkup.
+type PatientCheckupTransition struct {
+	BaseUUID
+
+	PatientCheckupID string  `gorm:"type:uuid;not null;index" json:"patient_checkup_id"`
+	FromStatus       *string `gorm:"type:varchar(20)" json:"from_status,omitempty"` // empty when the checkup was created
+	ToStatus         string  `gorm:"type:varchar(20);not null" json:"to_status"`
+	Reason           *string `gorm:"type:text" json:"reason,omitempty"`
+	ChangedByUserID  *string `gorm:"type:uuid;index" json:"changed_by_user_id,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (PatientCheckupTransition) TableName() string {
+	return "patient_checkup_transitions"
+}
+
+// PatientCheckupAmendment records why and by whom a completed checkup was changed.
+type PatientCheckupAmendment struct {
+	BaseUUID
+
+	PatientCheckupID string  `gorm:"type:uuid;not null;index" json:"patient_checkup_id"`
+	Reason           string  `gorm:"type:text;not null" json:"reason"`
+	AmendedByUserID  *string `gorm:"type:uuid;index" json:"amended_by_user_id,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (PatientCheckupAmendment) TableName() string {
+	return "patient_checkup_amendments"
+}
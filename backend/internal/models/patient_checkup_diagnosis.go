@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	DiagnosisTypePrimary   = "primary"
+	DiagnosisTypeSecondary = "secondary"
+)
+
+// PatientCheckupDiagnosis is a coded diagnosis of a checkup. A checkup has at
+// most one primary diagnosis and any number of secondary ones.
+type PatientCheckupDiagnosis struct {
+	BaseUUID
+
+	PatientCheckupID string    `gorm:"type:uuid;not null;uniqueIndex:uq_checkup_diagnosis_code;uniqueIndex:uq_checkup_primary_diagnosis,where:type = 'primary'" json:"patient_checkup_id"`
+	Code             string    `gorm:"type:varchar(10);not null;index;uniqueIndex:uq_checkup_diagnosis_code" json:"code"`
+	ICD10Code        ICD10Code `gorm:"foreignKey:Code;references:Code" json:"icd10_code"`
+	Type             string    `gorm:"type:varchar(10);not null" json:"type"` // primary, secondary
+	Position         int       `gorm:"not null;default:0" json:"position"`    // order within the checkup, primary first
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (PatientCheckupDiagnosis) TableName() string {
+	return "patient_checkup_diagnoses"
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// PatientCheckupMedicineAllocation records how many units of a dispensed
+// medicine came from which batch, so returns can restore the same batches and
+// recalls can find the patients who received a batch.
+type PatientCheckupMedicineAllocation struct {
+	BaseUUID
+
+	PatientCheckupID string `gorm:"type:uuid;not null;index" json:"patient_checkup_id"`
+	MedicineID       string `gorm:"type:uuid;not null;index" json:"medicine_id"`
+	MedicineBatchID  string `gorm:"type:uuid;not null;index" json:"medicine_batch_id"`
+
+	Quantity         int `gorm:"not null;check:quantity > 0" json:"quantity"`
+	ReturnedQuantity int `gorm:"not null;default:0;check:returned_quantity >= 0 AND returned_quantity <= quantity" json:"returned_quantity"`
+
+	// UnitCost and SellingPrice are the batch prices when dispensed; later
+	// changes to the batch do not reprice what was already given out.
+	UnitCost     *float64 `gorm:"type:decimal(15,2)" json:"unit_cost"`
+	SellingPrice *float64 `gorm:"type:decimal(15,2)" json:"selling_price"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (PatientCheckupMedicineAllocation) TableName() string {
+	return "patient_checkup_medicine_allocations"
+}
+
+// Outstanding is the quantity still held by the patient.
+func (a *PatientCheckupMedicineAllocation) Outstanding() int {
+	return a.Quantity - a.ReturnedQuantity
+}
+
+// BatchRecipient is a checkup that received units of a given batch.
+type BatchRecipient struct {
+	AllocationID     string    `json:"allocation_id"`
+	PatientCheckupID string    `json:"patient_checkup_id"`
+	PatientID        string    `json:"patient_id"`
+	PatientName      string    `json:"patient_name"`
+	VisitDate        time.Time `json:"visit_date"`
+	Quantity         int       `json:"quantity"`
+	ReturnedQuantity int       `json:"returned_quantity"`
+	DispensedAt      time.Time `json:"dispensed_at"`
+}
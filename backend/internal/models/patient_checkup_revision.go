@@ -0,0 +1,150 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PatientCheckupRevision is an immutable copy of a checkup taken after every
+// change, with the fields that changed compared to the previous revision.
+type PatientCheckupRevision struct {
+	BaseUUID
+
+	PatientCheckupID string                 `gorm:"type:uuid;not null;uniqueIndex:uq_checkup_revision" json:"patient_checkup_id"`
+	Revision         int                    `gorm:"not null;uniqueIndex:uq_checkup_revision" json:"revision"` // 1 for the first version of a checkup
+	Reason           string                 `gorm:"type:text;not null" json:"reason"`
+	ChangedByUserID  *string                `gorm:"type:uuid;index" json:"changed_by_user_id,omitempty"`
+	Snapshot         PatientCheckupSnapshot `gorm:"type:jsonb;serializer:json;not null" json:"snapshot"`
+	Changes          []CheckupFieldChange   `gorm:"type:jsonb;serializer:json;not null" json:"changes"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (PatientCheckupRevision) TableName() string {
+	return "patient_checkup_revisions"
+}
+
+// PatientCheckupSnapshot is the clinical record of a checkup at one revision.
+type PatientCheckupSnapshot struct {
+	VisitDate              time.Time                `json:"visit_date"`
+	Status                 string                   `json:"status"`
+	ChiefComplaint         string                   `json:"chief_complaint"`
+	Symptoms               []string                 `json:"symptoms"`
+	Diagnosis              *string                  `json:"diagnosis"`
+	Diagnoses              []SnapshotDiagnosis      `json:"diagnoses"`
+	TemperatureC           *float64                 `json:"temperature_c"`
+	BloodPressureSystolic  *int                     `json:"blood_pressure_systolic"`
+	BloodPressureDiastolic *int                     `json:"blood_pressure_diastolic"`
+	HeartRate              *int                     `json:"heart_rate"`
+	RespiratoryRate        *int                     `json:"respiratory_rate"`
+	OxygenSaturation       *int                     `json:"oxygen_saturation"`
+	HeightCm               *float64                 `json:"height_cm"`
+	WeightKg               *float64                 `json:"weight_kg"`
+	Medicines              []PatientCheckupMedicine `json:"medicines"`
+	TreatmentPlan          *string                  `json:"treatment_plan"`
+	Notes                  *string                  `json:"notes"`
+	DoctorID               *string                  `json:"doctor_id"`
+	DoctorName             *string                  `json:"doctor_name"`
+	FollowUpDate           *time.Time               `json:"follow_up_date"`
+	StartedAt              *time.Time               `json:"started_at"`
+	CompletedAt            *time.Time               `json:"completed_at"`
+	DispensedAt            *time.Time               `json:"dispensed_at"`
+}
+
+type SnapshotDiagnosis struct {
+	Code string `json:"code"`
+	Type string `json:"type"`
+}
+
+// CheckupFieldChange is one field that differs between two revisions. From
+// and To hold the JSON values as they appear in the snapshots.
+type CheckupFieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// Snapshot copies the clinical record; Diagnoses must be loaded.
+func (c *PatientCheckup) Snapshot() PatientCheckupSnapshot {
+	diagnoses := make([]SnapshotDiagnosis, len(c.Diagnoses))
+	for i, d := range c.Diagnoses {
+		diagnoses[i] = SnapshotDiagnosis{Code: d.Code, Type: d.Type}
+	}
+
+	return PatientCheckupSnapshot{
+		VisitDate:              c.VisitDate,
+		Status:                 c.Status,
+		ChiefComplaint:         c.ChiefComplaint,
+		Symptoms:               c.Symptoms,
+		Diagnosis:              c.Diagnosis,
+		Diagnoses:              diagnoses,
+		TemperatureC:           c.TemperatureC,
+		BloodPressureSystolic:  c.BloodPressureSystolic,
+		BloodPressureDiastolic: c.BloodPressureDiastolic,
+		HeartRate:              c.HeartRate,
+		RespiratoryRate:        c.RespiratoryRate,
+		OxygenSaturation:       c.OxygenSaturation,
+		HeightCm:               c.HeightCm,
+		WeightKg:               c.WeightKg,
+		Medicines:              c.Medicines,
+		TreatmentPlan:          c.TreatmentPlan,
+		Notes:                  c.Notes,
+		DoctorID:               c.DoctorID,
+		DoctorName:             c.DoctorName,
+		FollowUpDate:           c.FollowUpDate,
+		StartedAt:              c.StartedAt,
+		CompletedAt:            c.CompletedAt,
+		DispensedAt:            c.DispensedAt,
+	}
+}
+
+// DiffSnapshots lists the fields of after that differ from before, in field
+// order. A nil before compares against an empty record.
+func DiffSnapshots(before *PatientCheckupSnapshot, after PatientCheckupSnapshot) []CheckupFieldChange {
+	if before == nil {
+		before = &PatientCheckupSnapshot{}
+	}
+
+	changes := []CheckupFieldChange{}
+	beforeValue := reflect.ValueOf(*before)
+	afterValue := reflect.ValueOf(after)
+	for i := 0; i < afterValue.NumField(); i++ {
+		from := snapshotFieldJSON(beforeValue.Field(i))
+		to := snapshotFieldJSON(afterValue.Field(i))
+		if bytes.Equal(from, to) {
+			continue
+		}
+		field := strings.Split(afterValue.Type().Field(i).Tag.Get("json"), ",")[0]
+		changes = append(changes, CheckupFieldChange{Field: field, From: from, To: to})
+	}
+	return changes
+}
+
+// snapshotFieldJSON encodes a snapshot field so that empty lists and missing
+// values compare equal, and times compare by instant rather than zone.
+func snapshotFieldJSON(value reflect.Value) json.RawMessage {
+	switch v := value.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return json.RawMessage("null")
+		}
+		value = reflect.ValueOf(v.UTC())
+	case *time.Time:
+		if v != nil {
+			utc := v.UTC()
+			value = reflect.ValueOf(&utc)
+		}
+	}
+	if value.Kind() == reflect.Slice && value.Len() == 0 {
+		return json.RawMessage("null")
+	}
+
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return encoded
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// PatientErasureAudit records a single anonymization performed by the
+// retention policy. It deliberately stores no personal identifiers.
+type PatientErasureAudit struct {
+	BaseUUID
+
+	PatientID      string    `gorm:"type:uuid;not null;index" json:"patient_id"`
+	PatientType    string    `gorm:"type:varchar(50);not null" json:"patient_type"`
+	RetentionYears int       `gorm:"not null" json:"retention_years"`
+	LastActivityAt time.Time `gorm:"not null" json:"last_activity_at"`
+
+	Trigger              string   `gorm:"type:varchar(20);not null" json:"trigger"` // scheduler, cli, api
+	ClearedFields        []string `gorm:"type:jsonb;serializer:json;not null" json:"cleared_fields"`
+	GuardiansDeleted     int      `gorm:"not null;default:0" json:"guardians_deleted"`
+	AttachmentsDeleted   int      `gorm:"not null;default:0" json:"attachments_deleted"`
+	CheckupsDeidentified int      `gorm:"not null;default:0" json:"checkups_deidentified"`
+
+	PerformedByUserID *string `gorm:"type:uuid;index" json:"performed_by_user_id,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (PatientErasureAudit) TableName() string {
+	return "patient_erasure_audits"
+}
+
+// RetentionCandidate is a patient whose retention period has elapsed.
+type RetentionCandidate struct {
+	PatientID      string    `json:"patient_id"`
+	FullName       string    `json:"full_name"`
+	PatientType    string    `json:"patient_type"`
+	RetentionYears int       `json:"retention_years"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	EligibleSince  time.Time `json:"eligible_since"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type PatientGuardian struct {
+	BaseUUID
+
+	PatientID string `gorm:"type:uuid;not null;index" json:"patient_id"`
+
+	FullName            string  `gorm:"type:varchar(255);not null" json:"full_name"`
+	Relationship        string  `gorm:"type:varchar(30);not null" json:"relationship"` // father, mother, parent, sibling, relative, dorm_supervisor, other
+	PhoneNumber         string  `gorm:"type:varchar(20);not null" json:"phone_number"`
+	Email               *string `gorm:"type:varchar(255)" json:"email"`
+	Address             *string `gorm:"type:text" json:"address"`
+	IsPrimary           bool    `gorm:"not null;default:false" json:"is_primary"`
+	CanBeNotified       bool    `gorm:"not null;default:true" json:"can_be_notified"`
+	CanConsentTreatment bool    `gorm:"not null;default:false" json:"can_consent_treatment"`
+	Notes               *string `gorm:"type:text" json:"notes"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (PatientGuardian) TableName() string {
+	return "patient_guardians"
+}
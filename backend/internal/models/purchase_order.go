@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+const (
+	PurchaseOrderStatusDraft             = "draft"
+	PurchaseOrderStatusSent              = "sent"
+	PurchaseOrderStatusPartiallyReceived = "partially_received"
+	PurchaseOrderStatusClosed            = "closed"
+	PurchaseOrderStatusCancelled         = "cancelled"
+)
+
+// PurchaseOrder is an order of medicines from a supplier. Its lines can only
+// change while it is a draft; once sent, goods receipts fill the lines until
+// the order is closed, either because everything arrived or by hand.
+type PurchaseOrder struct {
+	BaseUUID
+
+	Number     string   `gorm:"type:varchar(30);not null;uniqueIndex" json:"number"`
+	SupplierID string   `gorm:"type:uuid;not null;index" json:"supplier_id"`
+	Supplier   Supplier `gorm:"foreignKey:SupplierID" json:"supplier"`
+	Status     string   `gorm:"type:varchar(20);not null;default:'draft';index" json:"status"` // draft, sent, partially_received, closed, cancelled
+
+	// ExpectedDate is when the supplier promised delivery.
+	ExpectedDate *time.Time `gorm:"type:date" json:"expected_date,omitempty"`
+	Notes        *string    `gorm:"type:text" json:"notes,omitempty"`
+
+	CreatedByUserID *string    `gorm:"type:uuid;index" json:"created_by_user_id,omitempty"`
+	SentAt          *time.Time `json:"sent_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+
+	Lines []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID;constraint:OnDelete:CASCADE" json:"lines,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (PurchaseOrder) TableName() string {
+	return "purchase_orders"
+}
+
+type PurchaseOrderLine struct {
+	BaseUUID
+
+	PurchaseOrderID  string   `gorm:"type:uuid;not null;uniqueIndex:uq_purchase_order_medicine" json:"purchase_order_id"`
+	MedicineID       string   `gorm:"type:uuid;not null;uniqueIndex:uq_purchase_order_medicine" json:"medicine_id"`
+	Medicine         Medicine `gorm:"foreignKey:MedicineID" json:"medicine"`
+	QuantityOrdered  int      `gorm:"not null;check:quantity_ordered > 0" json:"quantity_ordered"`
+	QuantityReceived int      `gorm:"not null;default:0;check:quantity_received >= 0" json:"quantity_received"`
+	UnitCost         *float64 `gorm:"type:decimal(15,2);check:unit_cost >= 0" json:"unit_cost"` // agreed price, if known
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (PurchaseOrderLine) TableName() string {
+	return "purchase_order_lines"
+}
+
+// Outstanding is the quantity still expected from the supplier.
+func (l *PurchaseOrderLine) Outstanding() int {
+	return max(l.QuantityOrdered-l.QuantityReceived, 0)
+}
+
+// GoodsReceipt records one delivery against a purchase order.
+type GoodsReceipt struct {
+	BaseUUID
+
+	PurchaseOrderID   string    `gorm:"type:uuid;not null;index" json:"purchase_order_id"`
+	ReceivedAt        time.Time `gorm:"not null;index" json:"received_at"`
+	ReferenceDocument *string   `gorm:"type:varchar(100)" json:"reference_document,omitempty"` // delivery note or invoice number
+	Notes             *string   `gorm:"type:text" json:"notes,omitempty"`
+
+	ReceivedByUserID *string `gorm:"type:uuid;index" json:"received_by_user_id,omitempty"`
+	ReceivedBy       *User   `gorm:"foreignKey:ReceivedByUserID;constraint:OnDelete:SET NULL" json:"-"`
+
+	Lines []GoodsReceiptLine `gorm:"foreignKey:GoodsReceiptID;constraint:OnDelete:CASCADE" json:"lines,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (GoodsReceipt) TableName() string {
+	return "goods_receipts"
+}
+
+// GoodsReceiptLine is the stock of one batch received and its cost of
+// record. The batch is created by the receipt, or topped up when the same
+// batch arrived before at the same unit cost.
+type GoodsReceiptLine struct {
+	BaseUUID
+
+	GoodsReceiptID      string        `gorm:"type:uuid;not null;index" json:"goods_receipt_id"`
+	PurchaseOrderLineID string        `gorm:"type:uuid;not null;index" json:"purchase_order_line_id"`
+	MedicineID          string        `gorm:"type:uuid;not null;index" json:"medicine_id"`
+	Medicine            Medicine      `gorm:"foreignKey:MedicineID" json:"medicine"`
+	MedicineBatchID     string        `gorm:"type:uuid;not null;index" json:"medicine_batch_id"`
+	MedicineBatch       MedicineBatch `gorm:"foreignKey:MedicineBatchID" json:"medicine_batch"`
+	Quantity            int           `gorm:"not null;check:quantity > 0" json:"quantity"`
+	UnitCost            *float64      `gorm:"type:decimal(15,2);check:unit_cost >= 0" json:"unit_cost"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (GoodsReceiptLine) TableName() string {
+	return "goods_receipt_lines"
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+const (
+	QueueStatusWaiting = "waiting"
+	QueueStatusCalled  = "called"
+	QueueStatusNoShow  = "no_show"
+
+	TriagePriorityEmergency = "emergency"
+	TriagePriorityUrgent    = "urgent"
+	TriagePriorityRoutine   = "routine"
+)
+
+// QueueEntry is a walk-in patient waiting to be seen on QueueDate. Triage
+// records vitals and a priority; when a doctor calls the entry it becomes an
+// in-progress PatientCheckup.
+type QueueEntry struct {
+	BaseUUID
+
+	QueueDate   time.Time `gorm:"type:date;not null;uniqueIndex:uq_queue_entries_date_number;index" json:"queue_date"`
+	QueueNumber int       `gorm:"not null;uniqueIndex:uq_queue_entries_date_number" json:"queue_number"`
+
+	PatientID string  `gorm:"type:uuid;not null;index" json:"patient_id"`
+	Patient   Patient `gorm:"foreignKey:PatientID" json:"patient"`
+
+	Status         string  `gorm:"type:varchar(20);not null;default:'waiting';index" json:"status"` // waiting, called, no_show
+	Priority       *string `gorm:"type:varchar(20)" json:"priority,omitempty"`                      // emergency, urgent, routine; empty until triaged
+	ChiefComplaint *string `gorm:"type:text" json:"chief_complaint,omitempty"`
+
+	TemperatureC           *float64   `gorm:"type:decimal(5,2)" json:"temperature_c,omitempty"`
+	BloodPressureSystolic  *int       `gorm:"check:blood_pressure_systolic > 0" json:"blood_pressure_systolic,omitempty"`
+	BloodPressureDiastolic *int       `gorm:"check:blood_pressure_diastolic > 0" json:"blood_pressure_diastolic,omitempty"`
+	HeartRate              *int       `gorm:"check:heart_rate >= 0" json:"heart_rate,omitempty"`
+	RespiratoryRate        *int       `gorm:"check:respiratory_rate >= 0" json:"respiratory_rate,omitempty"`
+	OxygenSaturation       *int       `gorm:"check:oxygen_saturation >= 0 AND oxygen_saturation <= 100" json:"oxygen_saturation,omitempty"`
+	HeightCm               *float64   `gorm:"type:decimal(6,2);check:height_cm >= 0" json:"height_cm,omitempty"`
+	WeightKg               *float64   `gorm:"type:decimal(6,2);check:weight_kg >= 0" json:"weight_kg,omitempty"`
+	TriageNotes            *string    `gorm:"type:text" json:"triage_notes,omitempty"`
+	TriagedAt              *time.Time `json:"triaged_at,omitempty"`
+	TriagedByUserID        *string    `gorm:"type:uuid" json:"triaged_by_user_id,omitempty"`
+
+	CheckedInAt time.Time `gorm:"not null" json:"checked_in_at"`
+	QueuedAt    time.Time `gorm:"not null" json:"queued_at"` // place in line; moved to the back when skipped
+	SkipCount   int       `gorm:"not null;default:0" json:"skip_count"`
+
+	CalledAt         *time.Time `json:"called_at,omitempty"`
+	CalledByUserID   *string    `gorm:"type:uuid" json:"called_by_user_id,omitempty"`
+	PatientCheckupID *string    `gorm:"type:uuid;index" json:"patient_checkup_id,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (QueueEntry) TableName() string {
+	return "queue_entries"
+}
+
+// QueueCounter hands out queue numbers; one row per clinic day.
+type QueueCounter struct {
+	QueueDate  time.Time `gorm:"type:date;primaryKey" json:"queue_date"`
+	LastNumber int       `gorm:"not null" json:"last_number"`
+}
+
+func (QueueCounter) TableName() string {
+	return "queue_counters"
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+const (
+	ReorderBasisForecast     = "forecast"
+	ReorderBasisMinimumStock = "minimum_stock" // no consumption on record yet
+)
+
+// ReorderCandidate is an active medicine with the stock it has and the stock
+// already ordered on open purchase orders.
+type ReorderCandidate struct {
+	MedicineID   string   `json:"medicine_id"`
+	MedicineCode string   `json:"medicine_code"`
+	MedicineName string   `json:"medicine_name"`
+	Unit         string   `json:"unit"`
+	CurrentStock int      `json:"current_stock"`
+	MinimumStock int      `json:"minimum_stock"`
+	OnOrder      int      `json:"on_order"`
+	UnitCost     *float64 `json:"unit_cost"` // of the most recently received batch
+}
+
+// DailyConsumption is what patient checkups took of a medicine on one clinic
+// day, net of returns.
+type DailyConsumption struct {
+	MedicineID string    `json:"medicine_id"`
+	Day        time.Time `json:"day"`
+	Quantity   int64     `json:"quantity"`
+}
+
+// ReorderSuggestion is the forecast for one medicine and what to order so
+// its stock lasts through the lead time and the review period after it.
+type ReorderSuggestion struct {
+	ReorderCandidate
+
+	Basis              string   `json:"basis"`                // forecast or minimum_stock
+	AverageDailyDemand float64  `json:"average_daily_demand"` // outside the seasons
+	DemandStdDev       float64  `json:"demand_std_dev"`       // of daily demand outside the seasons
+	SeasonalFactor     float64  `json:"seasonal_factor"`      // average over the lead time
+	LeadTimeDays       int      `json:"lead_time_days"`
+	LeadTimeDemand     float64  `json:"lead_time_demand"`
+	SafetyStock        int      `json:"safety_stock"`
+	ReorderPoint       int      `json:"reorder_point"`
+	OrderUpTo          int      `json:"order_up_to"`
+	DaysOfCover        *float64 `json:"days_of_cover"` // of stock and stock on order at the forecast demand
+	SuggestedQuantity  int      `json:"suggested_quantity"`
+	EstimatedCost      *float64 `json:"estimated_cost"`
+}
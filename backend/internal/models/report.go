@@ -0,0 +1,162 @@
+package models
+
+import "time"
+
+// DiagnosisReportRow counts checkups per diagnosis code, or per three-character
+// category when the report is grouped by category.
+type DiagnosisReportRow struct {
+	Code         string `json:"code"`
+	NameID       string `json:"name_id"`
+	NameEN       string `json:"name_en"`
+	CheckupCount int64  `json:"checkup_count"`
+	PatientCount int64  `json:"patient_count"`
+	PrimaryCount int64  `json:"primary_count"`
+}
+
+// MedicineUsageRow is the quantity of a medicine that left stock, in one
+// period and from one source when the report is broken down by them, valued
+// at the unit cost of the batches it came from.
+type MedicineUsageRow struct {
+	Rank             int        `json:"rank"` // position of the medicine by total usage
+	MedicineID       string     `json:"medicine_id"`
+	MedicineCode     string     `json:"medicine_code"`
+	MedicineName     string     `json:"medicine_name"`
+	Unit             string     `json:"unit"`
+	PeriodStart      *time.Time `json:"period_start"`
+	Source           *string    `json:"source"`
+	Quantity         int64      `json:"quantity"`
+	Value            float64    `json:"value"`
+	UncostedQuantity int64      `json:"uncosted_quantity"` // taken from batches without a unit cost
+}
+
+// StockActivityLogRow is a stock activity with the names it refers to, as
+// listed in the activity log export.
+type StockActivityLogRow struct {
+	ID               string     `json:"id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	MedicineCode     string     `json:"medicine_code"`
+	MedicineName     string     `json:"medicine_name"`
+	BatchNumber      *string    `json:"batch_number"`
+	ExpirationDate   *time.Time `json:"expiration_date"`
+	UnitCost         *float64   `json:"unit_cost"`
+	Source           string     `json:"source"`
+	ReasonCode       *string    `json:"reason_code"`
+	ChangeType       string     `json:"change_type"`
+	QuantityDelta    int        `json:"quantity_delta"`
+	StockBefore      int        `json:"stock_before"`
+	StockAfter       int        `json:"stock_after"`
+	ExpiredDelta     int        `json:"expired_delta"`
+	PatientCheckupID *string    `json:"patient_checkup_id"`
+	CreatedByName    *string    `json:"created_by_name"`
+	Notes            *string    `json:"notes"`
+}
+
+// OutstandingPurchaseOrderRow is an order line still waiting for delivery on
+// a sent or partially received purchase order.
+type OutstandingPurchaseOrderRow struct {
+	PurchaseOrderID     string     `json:"purchase_order_id"`
+	Number              string     `json:"number"`
+	Status              string     `json:"status"`
+	SupplierID          string     `json:"supplier_id"`
+	SupplierCode        string     `json:"supplier_code"`
+	SupplierName        string     `json:"supplier_name"`
+	SentAt              *time.Time `json:"sent_at"`
+	ExpectedDate        *time.Time `json:"expected_date"`
+	DaysOverdue         int        `json:"days_overdue"` // days past the expected date, 0 when not late
+	MedicineID          string     `json:"medicine_id"`
+	MedicineCode        string     `json:"medicine_code"`
+	MedicineName        string     `json:"medicine_name"`
+	Unit                string     `json:"unit"`
+	QuantityOrdered     int        `json:"quantity_ordered"`
+	QuantityReceived    int        `json:"quantity_received"`
+	QuantityOutstanding int        `json:"quantity_outstanding"`
+	UnitCost            *float64   `json:"unit_cost"`
+	OutstandingValue    *float64   `json:"outstanding_value"`
+}
+
+// SupplierLeadTimeRow sums up how long a supplier took to deliver, measured
+// per goods receipt from the moment its order was sent.
+type SupplierLeadTimeRow struct {
+	SupplierID      string  `json:"supplier_id"`
+	SupplierCode    string  `json:"supplier_code"`
+	SupplierName    string  `json:"supplier_name"`
+	OrderCount      int64   `json:"order_count"`
+	ReceiptCount    int64   `json:"receipt_count"`
+	AverageLeadDays float64 `json:"average_lead_days"`
+	MinLeadDays     float64 `json:"min_lead_days"`
+	MaxLeadDays     float64 `json:"max_lead_days"`
+	OnTimeReceipts  int64   `json:"on_time_receipts"` // on or before the expected date
+	LateReceipts    int64   `json:"late_receipts"`
+}
+
+// InventoryValuationRow is the stock of a medicine, or of one of its batches,
+// on a date and what it cost. Every goods receipt line is a cost layer; the
+// stock a batch held is the latest layers received into it, first in, first
+// out, and stock no receipt accounts for takes the batch's unit cost.
+type InventoryValuationRow struct {
+	MedicineID       string     `json:"medicine_id"`
+	MedicineCode     string     `json:"medicine_code"`
+	MedicineName     string     `json:"medicine_name"`
+	Unit             string     `json:"unit"`
+	MedicineBatchID  *string    `json:"medicine_batch_id"` // set when valued per batch
+	BatchNumber      *string    `json:"batch_number"`
+	ExpirationDate   *time.Time `json:"expiration_date"`
+	UnitCost         *float64   `json:"unit_cost"` // value over the costed quantity
+	Quantity         int64      `json:"quantity"`
+	Value            float64    `json:"value"`
+	ExpiredQuantity  int64      `json:"expired_quantity"` // part of the quantity in batches expired on the date
+	ExpiredValue     float64    `json:"expired_value"`
+	UncostedQuantity int64      `json:"uncosted_quantity"` // received or entered without a unit cost, not included in value
+}
+
+// InventoryValuationSummary totals the inventory valuation.
+type InventoryValuationSummary struct {
+	AsOf             time.Time `json:"as_of"`
+	MedicineCount    int       `json:"medicine_count"`
+	Quantity         int64     `json:"quantity"`
+	Value            float64   `json:"value"`
+	ExpiredQuantity  int64     `json:"expired_quantity"`
+	ExpiredValue     float64   `json:"expired_value"`
+	UncostedQuantity int64     `json:"uncosted_quantity"`
+}
+
+type InventoryValuation struct {
+	Summary InventoryValuationSummary `json:"summary"`
+	Rows    []InventoryValuationRow   `json:"rows"`
+}
+
+// CostOfGoodsDispensedRow is what the medicines dispensed to patients cost,
+// net of returns, per period, patient type or checkup. Each unit is costed at
+// the prices of the batch it was taken from when it was dispensed.
+type CostOfGoodsDispensedRow struct {
+	PeriodStart      *time.Time `json:"period_start"`
+	PatientType      *string    `json:"patient_type"`
+	PatientCheckupID *string    `json:"patient_checkup_id"` // set, with the patient and visit date, when grouped per checkup
+	PatientID        *string    `json:"patient_id"`
+	PatientName      *string    `json:"patient_name"`
+	VisitDate        *time.Time `json:"visit_date"`
+	CheckupCount     int64      `json:"checkup_count"`
+	Quantity         int64      `json:"quantity"`
+	Cost             float64    `json:"cost"`
+	SellingValue     float64    `json:"selling_value"`     // at the selling price of the batches
+	UncostedQuantity int64      `json:"uncosted_quantity"` // from batches without a unit cost, not included in cost
+	UnpricedQuantity int64      `json:"unpriced_quantity"` // from batches without a selling price, not included in selling value
+}
+
+// CostOfGoodsDispensedSummary totals the cost of goods dispensed over the
+// whole range.
+type CostOfGoodsDispensedSummary struct {
+	DateFrom         time.Time `json:"date_from"`
+	DateTo           time.Time `json:"date_to"`
+	CheckupCount     int64     `json:"checkup_count"`
+	Quantity         int64     `json:"quantity"`
+	Cost             float64   `json:"cost"`
+	SellingValue     float64   `json:"selling_value"`
+	UncostedQuantity int64     `json:"uncosted_quantity"`
+	UnpricedQuantity int64     `json:"unpriced_quantity"`
+}
+
+type CostOfGoodsDispensed struct {
+	Summary CostOfGoodsDispensedSummary `json:"summary"`
+	Rows    []CostOfGoodsDispensedRow   `json:"rows"`
+}
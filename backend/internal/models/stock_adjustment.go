@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	StockAdjustmentTypeDamage     = "damage"
+	StockAdjustmentTypeLoss       = "loss"
+	StockAdjustmentTypeFound      = "found"
+	StockAdjustmentTypeDonation   = "donation"
+	StockAdjustmentTypeCorrection = "correction"
+)
+
+const (
+	StockAdjustmentStatusPending  = "pending"
+	StockAdjustmentStatusApplied  = "applied"
+	StockAdjustmentStatusRejected = "rejected"
+)
+
+// StockAdjustment corrects the quantity of one batch outside of dispensing.
+// Small adjustments are applied when they are requested; larger ones stay
+// pending until a second admin approves or rejects them.
+type StockAdjustment struct {
+	BaseUUID
+
+	MedicineID      string        `gorm:"type:uuid;not null;index" json:"medicine_id"`
+	Medicine        Medicine      `gorm:"foreignKey:MedicineID" json:"medicine"`
+	MedicineBatchID string        `gorm:"type:uuid;not null;index" json:"medicine_batch_id"`
+	MedicineBatch   MedicineBatch `gorm:"foreignKey:MedicineBatchID" json:"medicine_batch"`
+
+	Type          string `gorm:"type:varchar(20);not null;index" json:"type"` // damage, loss, found, donation, correction
+	ReasonCode    string `gorm:"type:varchar(40);not null" json:"reason_code"`
+	Justification string `gorm:"type:text;not null" json:"justification"`
+	QuantityDelta int    `gorm:"not null" json:"quantity_delta"`
+
+	Status            string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"` // pending, applied, rejected
+	RequestedByUserID *string    `gorm:"type:uuid;index" json:"requested_by_user_id,omitempty"`
+	ReviewedByUserID  *string    `gorm:"type:uuid" json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNotes       *string    `gorm:"type:text" json:"review_notes,omitempty"`
+	AppliedAt         *time.Time `json:"applied_at,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (StockAdjustment) TableName() string {
+	return "stock_adjustments"
+}
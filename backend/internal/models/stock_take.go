@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+const (
+	StockTakeStatusOpen      = "open"
+	StockTakeStatusApproved  = "approved"
+	StockTakeStatusCancelled = "cancelled"
+)
+
+// StockTake is a physical count of the pharmacy shelves. Only one stock take
+// can be open at a time; while it is open with FreezeDispensing set, no
+// medicines can be dispensed.
+type StockTake struct {
+	BaseUUID
+
+	Name             string  `gorm:"type:varchar(100);not null" json:"name"`
+	Notes            *string `gorm:"type:text" json:"notes,omitempty"`
+	Status           string  `gorm:"type:varchar(20);not null;default:'open';index;uniqueIndex:uq_stock_take_open,where:status = 'open'" json:"status"` // open, approved, cancelled
+	FreezeDispensing bool    `gorm:"not null;default:false" json:"freeze_dispensing"`
+
+	OpenedByUserID   *string    `gorm:"type:uuid" json:"opened_by_user_id,omitempty"`
+	ApprovedByUserID *string    `gorm:"type:uuid" json:"approved_by_user_id,omitempty"`
+	ApprovedAt       *time.Time `json:"approved_at,omitempty"`
+	CancelledAt      *time.Time `json:"cancelled_at,omitempty"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (StockTake) TableName() string {
+	return "stock_takes"
+}
+
+// StockTakeLine is the count of one batch. SystemQuantity is the batch
+// quantity when the line was last counted, or when the stock take was opened
+// if it has not been counted yet.
+type StockTakeLine struct {
+	BaseUUID
+
+	StockTakeID     string        `gorm:"type:uuid;not null;uniqueIndex:uq_stock_take_batch" json:"stock_take_id"`
+	MedicineID      string        `gorm:"type:uuid;not null;index" json:"medicine_id"`
+	Medicine        Medicine      `gorm:"foreignKey:MedicineID" json:"medicine"`
+	MedicineBatchID string        `gorm:"type:uuid;not null;uniqueIndex:uq_stock_take_batch" json:"medicine_batch_id"`
+	MedicineBatch   MedicineBatch `gorm:"foreignKey:MedicineBatchID" json:"medicine_batch"`
+
+	SystemQuantity  int        `gorm:"not null" json:"system_quantity"`
+	CountedQuantity *int       `gorm:"check:counted_quantity >= 0" json:"counted_quantity,omitempty"`
+	CountedByUserID *string    `gorm:"type:uuid" json:"counted_by_user_id,omitempty"`
+	CountedAt       *time.Time `json:"counted_at,omitempty"`
+	Notes           *string    `gorm:"type:text" json:"notes,omitempty"`
+
+	// StockAdjustmentID is the correction posted for the variance when the
+	// stock take was approved.
+	StockAdjustmentID *string `gorm:"type:uuid" json:"stock_adjustment_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (StockTakeLine) TableName() string {
+	return "stock_take_lines"
+}
+
+// Variance is the counted quantity minus the system quantity, or nil while
+// the line has not been counted.
+func (l *StockTakeLine) Variance() *int {
+	if l.CountedQuantity == nil {
+		return nil
+	}
+	variance := *l.CountedQuantity - l.SystemQuantity
+	return &variance
+}
+
+// StockTakeVariance sums up the counted lines of a stock take. Values are
+// priced at the unit cost of each batch; batches without a cost are left out
+// of them.
+type StockTakeVariance struct {
+	TotalLines        int     `json:"total_lines"`
+	CountedLines      int     `json:"counted_lines"`
+	LinesWithVariance int     `json:"lines_with_variance"`
+	SurplusQuantity   int     `json:"surplus_quantity"`
+	ShortageQuantity  int     `json:"shortage_quantity"`
+	NetQuantity       int     `json:"net_quantity"`
+	NetValue          float64 `json:"net_value"`
+}
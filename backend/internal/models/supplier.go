@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	BaseUUID
+
+	Name        string  `gorm:"type:varchar(255);not null;index" json:"name"`
+	Code        string  `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	ContactName *string `gorm:"type:varchar(255)" json:"contact_name"`
+	Phone       *string `gorm:"type:varchar(50)" json:"phone"`
+	Email       *string `gorm:"type:varchar(255)" json:"email"`
+	Address     *string `gorm:"type:text" json:"address"`
+	Notes       *string `gorm:"type:text" json:"notes"`
+	IsActive    bool    `gorm:"not null;default:true" json:"is_active"`
+
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (Supplier) TableName() string {
+	return "suppliers"
+}
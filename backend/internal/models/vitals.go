@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Blood pressure categories of the 2017 ACC/AHA guideline. They are defined
+// for adults and for children from 13 years; for younger children they are
+// only indicative.
+const (
+	BloodPressureNormal   = "normal"
+	BloodPressureElevated = "elevated"
+	BloodPressureStage1   = "hypertension_stage_1"
+	BloodPressureStage2   = "hypertension_stage_2"
+	BloodPressureCrisis   = "hypertensive_crisis"
+)
+
+// HypertensiveCategories are the blood pressure categories flagged as abnormal.
+var HypertensiveCategories = []string{BloodPressureStage1, BloodPressureStage2, BloodPressureCrisis}
+
+const (
+	FeverThresholdC    = 38.0 // fever from this temperature up
+	HypoxiaThresholdO2 = 94   // hypoxia below this oxygen saturation
+)
+
+// ClassifyBloodPressure returns the category of a reading; the higher of the
+// systolic and diastolic category wins.
+func ClassifyBloodPressure(systolic, diastolic int) string {
+	switch {
+	case systolic > 180 || diastolic > 120:
+		return BloodPressureCrisis
+	case systolic >= 140 || diastolic >= 90:
+		return BloodPressureStage2
+	case systolic >= 130 || diastolic >= 80:
+		return BloodPressureStage1
+	case systolic >= 120:
+		return BloodPressureElevated
+	default:
+		return BloodPressureNormal
+	}
+}
+
+// FormatBloodPressure renders a reading as "systolic/diastolic", or nil when
+// either value is missing.
+func FormatBloodPressure(systolic, diastolic *int) *string {
+	if systolic == nil || diastolic == nil {
+		return nil
+	}
+	value := fmt.Sprintf("%d/%d", *systolic, *diastolic)
+	return &value
+}
+
+// ParseBloodPressure reads a "systolic/diastolic" reading such as "120/80".
+func ParseBloodPressure(value string) (systolic, diastolic int, err error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("blood pressure %q is not in systolic/diastolic form", value)
+	}
+
+	systolic, errSystolic := strconv.Atoi(strings.TrimSpace(parts[0]))
+	diastolic, errDiastolic := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if errSystolic != nil || errDiastolic != nil {
+		return 0, 0, fmt.Errorf("blood pressure %q is not in systolic/diastolic form", value)
+	}
+	return systolic, diastolic, nil
+}
@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier writes notifications to a logger instead of delivering them.
+// It is meant for development and testing.
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (l *LogNotifier) Send(ctx context.Context, message Message) error {
+	email := ""
+	if message.Email != nil {
+		email = *message.Email
+	}
+	l.logger.Printf("to=%q phone=%q email=%q subject=%q body=%q",
+		message.RecipientName, message.PhoneNumber, email, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"backend/internal/config"
+	"context"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Message is a notification for a single recipient. Channels pick the
+// contact they can deliver to.
+type Message struct {
+	RecipientName string
+	PhoneNumber   string
+	Email         *string
+	Subject       string
+	Body          string
+}
+
+// HasContact reports whether the message can be delivered at all.
+func (m Message) HasContact() bool {
+	return m.PhoneNumber != "" || (m.Email != nil && *m.Email != "")
+}
+
+// Notifier interface - all notification channels must satisfy this
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// New builds the notification channel selected in the configuration
+func New(cfg config.NotificationConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "log":
+		return NewLogNotifier(log.New(os.Stdout, "[notify] ", log.LstdFlags)), nil
+	}
+	return nil, fmt.Errorf("unsupported notification driver %q", cfg.Driver)
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AppointmentFilter struct {
+	DoctorID  string
+	PatientID string
+	Status    string
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	TimeZone  string // calendar days of DateFrom and DateTo are taken in this zone
+}
+
+type AppointmentRepository interface {
+	FindByID(ctx context.Context, id generated.IdParam) (*models.Appointment, error)
+	FindAll(ctx context.Context, page, perPage int, filter AppointmentFilter) ([]models.Appointment, int64, error)
+	FindActiveBetween(ctx context.Context, doctorIDs []string, from, to time.Time) ([]models.Appointment, error)
+}
+
+type appointmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAppointmentRepository(db *gorm.DB) AppointmentRepository {
+	return &appointmentRepository{db: db}
+}
+
+func (r *appointmentRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.Appointment, error) {
+	var appointment models.Appointment
+	err := r.db.WithContext(ctx).
+		Preload("Patient").
+		Preload("Doctor").
+		First(&appointment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+func (r *appointmentRepository) FindAll(ctx context.Context, page, perPage int, filter AppointmentFilter) ([]models.Appointment, int64, error) {
+	var appointments []models.Appointment
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.Appointment{})
+
+	if filter.DoctorID != "" {
+		query = query.Where("doctor_id = ?", filter.DoctorID)
+	}
+
+	if filter.PatientID != "" {
+		query = query.Where("patient_id = ?", filter.PatientID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	timeZone := filter.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
+	if filter.DateFrom != nil {
+		query = query.Where("DATE(start_at AT TIME ZONE ?) >= ?", timeZone, filter.DateFrom.Format("2006-01-02"))
+	}
+
+	if filter.DateTo != nil {
+		query = query.Where("DATE(start_at AT TIME ZONE ?) <= ?", timeZone, filter.DateTo.Format("2006-01-02"))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Patient").
+		Preload("Doctor").
+		Order("start_at ASC, created_at ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&appointments).Error
+
+	return appointments, total, err
+}
+
+// FindActiveBetween returns booked and checked-in appointments of the given
+// doctors that overlap [from, to).
+func (r *appointmentRepository) FindActiveBetween(ctx context.Context, doctorIDs []string, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	if len(doctorIDs) == 0 {
+		return appointments, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Where("doctor_id IN ? AND status IN ? AND start_at < ? AND end_at > ?",
+			doctorIDs,
+			[]string{models.AppointmentStatusBooked, models.AppointmentStatusCheckedIn},
+			to, from).
+		Order("start_at ASC").
+		Find(&appointments).Error
+
+	return appointments, err
+}
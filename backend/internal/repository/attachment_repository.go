@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *models.Attachment) error
+	FindByOwner(ctx context.Context, ownerType, ownerID, id string) (*models.Attachment, error)
+	FindAllByOwner(ctx context.Context, ownerType, ownerID string) ([]models.Attachment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *attachmentRepository) FindByOwner(ctx context.Context, ownerType, ownerID, id string) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND owner_type = ? AND owner_id = ?", id, ownerType, ownerID).
+		First(&attachment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) FindAllByOwner(ctx context.Context, ownerType, ownerID string) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.WithContext(ctx).
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at DESC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.Attachment{}, "id = ?", id).Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BatchExpiryAlertFilter struct {
+	MedicineID  string
+	HorizonDays int
+}
+
+type BatchExpiryRepository interface {
+	// FindNewlyExpired returns the batches past their expiration date that
+	// are not marked expired yet.
+	FindNewlyExpired(ctx context.Context, today time.Time) ([]models.MedicineBatch, error)
+	// FindExpiring returns the batches holding stock that expire between
+	// today and until, both inclusive.
+	FindExpiring(ctx context.Context, today, until time.Time) ([]models.MedicineBatch, error)
+	FindAlertRecipients(ctx context.Context) ([]models.User, error)
+	FindAlerts(ctx context.Context, page, perPage int, filter BatchExpiryAlertFilter) ([]models.BatchExpiryAlert, int64, error)
+}
+
+type batchExpiryRepository struct {
+	db *gorm.DB
+}
+
+func NewBatchExpiryRepository(db *gorm.DB) BatchExpiryRepository {
+	return &batchExpiryRepository{db: db}
+}
+
+func (r *batchExpiryRepository) FindNewlyExpired(ctx context.Context, today time.Time) ([]models.MedicineBatch, error) {
+	var batches []models.MedicineBatch
+	err := r.db.WithContext(ctx).
+		Where("status <> ? AND expiration_date < ?", "expired", today.Format("2006-01-02")).
+		Order("expiration_date ASC, id ASC").
+		Find(&batches).Error
+	return batches, err
+}
+
+func (r *batchExpiryRepository) FindExpiring(ctx context.Context, today, until time.Time) ([]models.MedicineBatch, error) {
+	var batches []models.MedicineBatch
+	err := r.db.WithContext(ctx).
+		Preload("Medicine").
+		Where("status = ? AND quantity > 0 AND expiration_date BETWEEN ? AND ?",
+			"active", today.Format("2006-01-02"), until.Format("2006-01-02")).
+		Order("expiration_date ASC, id ASC").
+		Find(&batches).Error
+	return batches, err
+}
+
+// FindAlertRecipients returns the active admins, who look after the stock.
+func (r *batchExpiryRepository) FindAlertRecipients(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("role = ? AND is_active = ?", "admin", true).
+		Order("name ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *batchExpiryRepository) FindAlerts(ctx context.Context, page, perPage int, filter BatchExpiryAlertFilter) ([]models.BatchExpiryAlert, int64, error) {
+	var alerts []models.BatchExpiryAlert
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.BatchExpiryAlert{})
+
+	if filter.MedicineID != "" {
+		query = query.Where("medicine_id = ?", filter.MedicineID)
+	}
+	if filter.HorizonDays > 0 {
+		query = query.Where("horizon_days = ?", filter.HorizonDays)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Medicine").
+		Preload("MedicineBatch").
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&alerts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return alerts, total, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type CheckupDocumentRepository interface {
+	Create(ctx context.Context, document *models.CheckupDocument) error
+	FindByCheckup(ctx context.Context, checkupID string) ([]models.CheckupDocument, error)
+	FindByVerificationCode(ctx context.Context, code string) (*models.CheckupDocument, error)
+	FindCheckup(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error)
+}
+
+type checkupDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckupDocumentRepository(db *gorm.DB) CheckupDocumentRepository {
+	return &checkupDocumentRepository{db: db}
+}
+
+func (r *checkupDocumentRepository) Create(ctx context.Context, document *models.CheckupDocument) error {
+	return r.db.WithContext(ctx).Omit("PatientCheckup").Create(document).Error
+}
+
+func (r *checkupDocumentRepository) FindByCheckup(ctx context.Context, checkupID string) ([]models.CheckupDocument, error) {
+	var documents []models.CheckupDocument
+	err := r.db.WithContext(ctx).
+		Where("patient_checkup_id = ?", checkupID).
+		Order("created_at DESC").
+		Find(&documents).Error
+	return documents, err
+}
+
+// FindByVerificationCode loads a document with its checkup and patient.
+func (r *checkupDocumentRepository) FindByVerificationCode(ctx context.Context, code string) (*models.CheckupDocument, error) {
+	var document models.CheckupDocument
+	err := r.db.WithContext(ctx).
+		Preload("PatientCheckup").
+		Preload("PatientCheckup.Patient").
+		Where("verification_code = ?", code).
+		First(&document).Error
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// FindCheckup loads everything printed on a document of the checkup.
+func (r *checkupDocumentRepository) FindCheckup(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error) {
+	var checkup models.PatientCheckup
+	err := r.db.WithContext(ctx).
+		Scopes(PreloadCheckupDiagnoses).
+		Preload("Patient").
+		First(&checkup, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkup, nil
+}
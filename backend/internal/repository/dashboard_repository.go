@@ -84,12 +84,16 @@ func (r *dashboardRepository) GetCheckupStatusSummary(ctx context.Context, since
 	var summary models.CheckupStatusSummary
 	for _, row := range rows {
 		switch row.Status {
-		case "scheduled":
+		case models.CheckupStatusScheduled:
 			summary.Scheduled = row.Count
-		case "completed":
+		case models.CheckupStatusInProgress:
+			summary.InProgress = row.Count
+		case models.CheckupStatusCompleted:
 			summary.Completed = row.Count
-		case "cancelled":
+		case models.CheckupStatusCancelled:
 			summary.Cancelled = row.Count
+		case models.CheckupStatusNoShow:
+			summary.NoShow = row.Count
 		}
 	}
 	return summary, err
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DisposalFilter struct {
+	Method   string
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+type DisposalRepository interface {
+	FindByID(ctx context.Context, id generated.IdParam) (*models.Disposal, error)
+	FindAll(ctx context.Context, page, perPage int, filter DisposalFilter) ([]models.Disposal, int64, error)
+}
+
+type disposalRepository struct {
+	db *gorm.DB
+}
+
+func NewDisposalRepository(db *gorm.DB) DisposalRepository {
+	return &disposalRepository{db: db}
+}
+
+func (r *disposalRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.Disposal, error) {
+	var disposal models.Disposal
+	err := r.db.WithContext(ctx).
+		Preload("DisposedBy").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Items.Medicine").
+		Preload("Items.MedicineBatch").
+		First(&disposal, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &disposal, nil
+}
+
+func (r *disposalRepository) FindAll(ctx context.Context, page, perPage int, filter DisposalFilter) ([]models.Disposal, int64, error) {
+	var disposals []models.Disposal
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.Disposal{})
+
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("disposed_at >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("disposed_at < ?", *filter.DateTo)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("DisposedBy").
+		Preload("Items").
+		Preload("Items.Medicine").
+		Preload("Items.MedicineBatch").
+		Order("disposed_at DESC, id DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&disposals).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return disposals, total, nil
+}
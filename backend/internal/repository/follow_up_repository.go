@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	FollowUpStatusDue     = "due"
+	FollowUpStatusOverdue = "overdue"
+)
+
+// FollowUpFilter selects open follow-ups. Today is the current clinic date;
+// follow-ups dated before it are overdue, those up to DueBy are due.
+type FollowUpFilter struct {
+	Today     time.Time
+	DueBy     time.Time
+	Status    string // due, overdue or empty for both
+	PatientID string
+}
+
+type FollowUpRepository interface {
+	FindOpen(ctx context.Context, page, perPage int, filter FollowUpFilter) ([]models.PatientCheckup, int64, error)
+	FindReminderCandidates(ctx context.Context, from, to time.Time) ([]models.PatientCheckup, error)
+}
+
+type followUpRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowUpRepository(db *gorm.DB) FollowUpRepository {
+	return &followUpRepository{db: db}
+}
+
+// openFollowUps keeps checkups with a follow-up the patient has not come back
+// for yet. Cancelled visits and erased patients have nothing to follow up.
+func openFollowUps(db *gorm.DB) *gorm.DB {
+	return db.
+		Joins("JOIN patients p ON p.id = patient_checkups.patient_id AND p.deleted_at IS NULL AND p.anonymized_at IS NULL").
+		Where("patient_checkups.follow_up_date IS NOT NULL").
+		Where("patient_checkups.follow_up_completed_at IS NULL").
+		Where("patient_checkups.deleted_at IS NULL").
+		Where("patient_checkups.status IN ?", []string{models.CheckupStatusInProgress, models.CheckupStatusCompleted})
+}
+
+func (r *followUpRepository) FindOpen(ctx context.Context, page, perPage int, filter FollowUpFilter) ([]models.PatientCheckup, int64, error) {
+	var checkups []models.PatientCheckup
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.PatientCheckup{}).Scopes(openFollowUps)
+
+	today := filter.Today.Format("2006-01-02")
+	switch filter.Status {
+	case FollowUpStatusOverdue:
+		query = query.Where("patient_checkups.follow_up_date < ?", today)
+	case FollowUpStatusDue:
+		query = query.Where("patient_checkups.follow_up_date BETWEEN ? AND ?", today, filter.DueBy.Format("2006-01-02"))
+	default:
+		query = query.Where("patient_checkups.follow_up_date <= ?", filter.DueBy.Format("2006-01-02"))
+	}
+
+	if filter.PatientID != "" {
+		query = query.Where("patient_checkups.patient_id = ?", filter.PatientID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Patient").
+		Order("patient_checkups.follow_up_date ASC, patient_checkups.visit_date ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&checkups).Error
+
+	return checkups, total, err
+}
+
+// FindReminderCandidates returns open follow-ups dated within [from, to]
+// whose reminder has not been sent, with the patient and guardians loaded.
+func (r *followUpRepository) FindReminderCandidates(ctx context.Context, from, to time.Time) ([]models.PatientCheckup, error) {
+	var checkups []models.PatientCheckup
+	err := r.db.WithContext(ctx).
+		Scopes(openFollowUps).
+		Where("patient_checkups.follow_up_date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Where("patient_checkups.follow_up_reminder_sent_at IS NULL").
+		Preload("Patient").
+		Preload("Patient.Guardians", "deleted_at IS NULL").
+		Order("patient_checkups.follow_up_date ASC").
+		Find(&checkups).Error
+	return checkups, err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICD10CodeFilter struct {
+	Search   string
+	IsActive *bool
+}
+
+type ICD10Repository interface {
+	FindByCode(ctx context.Context, code string) (*models.ICD10Code, error)
+	FindAll(ctx context.Context, page, perPage int, filter ICD10CodeFilter) ([]models.ICD10Code, int64, error)
+	Upsert(ctx context.Context, codes []models.ICD10Code) error
+}
+
+type icd10Repository struct {
+	db *gorm.DB
+}
+
+func NewICD10Repository(db *gorm.DB) ICD10Repository {
+	return &icd10Repository{db: db}
+}
+
+func (r *icd10Repository) FindByCode(ctx context.Context, code string) (*models.ICD10Code, error) {
+	var icd10Code models.ICD10Code
+	err := r.db.WithContext(ctx).Where("code = ?", models.NormalizeICD10Code(code)).First(&icd10Code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &icd10Code, nil
+}
+
+// FindAll searches codes and names in both languages. Codes matching the
+// search exactly or by prefix come before name matches.
+func (r *icd10Repository) FindAll(ctx context.Context, page, perPage int, filter ICD10CodeFilter) ([]models.ICD10Code, int64, error) {
+	var codes []models.ICD10Code
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.ICD10Code{})
+
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		query = query.Where(
+			"code ILIKE ? OR name_id ILIKE ? OR name_en ILIKE ?",
+			searchPattern, searchPattern, searchPattern,
+		)
+	}
+
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Search != "" {
+		query = query.Order(clause.Expr{
+			SQL:  "CASE WHEN code = ? THEN 0 WHEN code LIKE ? THEN 1 ELSE 2 END",
+			Vars: []any{models.NormalizeICD10Code(filter.Search), codePrefixPattern(filter.Search)},
+		})
+	}
+
+	err := query.
+		Order("code ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&codes).Error
+
+	return codes, total, err
+}
+
+// Upsert inserts new codes and refreshes the names of known ones. Codes
+// present in the import are (re)activated.
+func (r *icd10Repository) Upsert(ctx context.Context, codes []models.ICD10Code) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name_id", "name_en", "is_active", "updated_at"}),
+		}).
+		CreateInBatches(codes, 500).Error
+}
+
+// codePrefixPattern turns a code into a LIKE pattern matching the code and
+// its subcodes.
+func codePrefixPattern(code string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(models.NormalizeICD10Code(code))
+	return escaped + "%"
+}
@@ -22,6 +22,7 @@ type MedicineBatchRepository interface {
 	FindAll(ctx context.Context, page, perPage int, filter MedicineBatchFilter) ([]models.MedicineBatch, int64, error)
 	Update(ctx context.Context, batch *models.MedicineBatch) error
 	Delete(ctx context.Context, id generated.IdParam) error
+	FindRecipients(ctx context.Context, id generated.IdParam, page, perPage int) ([]models.BatchRecipient, int64, error)
 }
 
 type medicineBatchRepository struct {
@@ -77,10 +78,12 @@ func (r *medicineBatchRepository) FindAll(
 	}
 
 	if filter.Expired != nil {
+		// A batch can still be used on its expiration date.
+		today := time.Now().UTC().Format("2006-01-02")
 		if *filter.Expired {
-			query = query.Where("expiration_date < ?", time.Now())
+			query = query.Where("expiration_date < ?", today)
 		} else {
-			query = query.Where("expiration_date >= ?", time.Now())
+			query = query.Where("expiration_date >= ?", today)
 		}
 	}
 
@@ -104,3 +107,34 @@ func (r *medicineBatchRepository) Update(ctx context.Context, batch *models.Medi
 func (r *medicineBatchRepository) Delete(ctx context.Context, id generated.IdParam) error {
 	return r.db.WithContext(ctx).Delete(&models.MedicineBatch{}, id).Error
 }
+
+// FindRecipients lists the checkups that were dispensed units of the batch, for recalls.
+func (r *medicineBatchRepository) FindRecipients(
+	ctx context.Context,
+	id generated.IdParam,
+	page, perPage int,
+) ([]models.BatchRecipient, int64, error) {
+	var recipients []models.BatchRecipient
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).
+		Table("patient_checkup_medicine_allocations a").
+		Joins("JOIN patient_checkups c ON c.id = a.patient_checkup_id").
+		Joins("JOIN patients p ON p.id = c.patient_id").
+		Where("a.medicine_batch_id = ?", id)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Select(`a.id AS allocation_id, a.patient_checkup_id, c.patient_id, p.full_name AS patient_name,
+			c.visit_date, a.quantity, a.returned_quantity, a.created_at AS dispensed_at`).
+		Order("a.created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Scan(&recipients).Error
+
+	return recipients, total, err
+}
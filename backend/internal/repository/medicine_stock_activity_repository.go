@@ -3,6 +3,7 @@ package repository
 import (
 	"backend/internal/models"
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,9 +12,29 @@ type MedicineStockActivityFilter struct {
 	Source string
 }
 
+// StockLedgerFilter selects the activities of the stock ledger. From and To
+// bound created_at, To exclusive.
+type StockLedgerFilter struct {
+	From       *time.Time
+	To         *time.Time
+	Source     string
+	ChangeType string
+	MedicineID string
+	UserID     string
+	BatchID    string
+	CheckupID  string
+}
+
+// StockLedgerCursor is the last activity of the previous ledger page.
+type StockLedgerCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
 type MedicineStockActivityRepository interface {
 	Create(ctx context.Context, activity *models.MedicineStockActivity) error
 	FindAllByMedicineID(ctx context.Context, medicineID string, page, perPage int, filter MedicineStockActivityFilter) ([]models.MedicineStockActivity, int64, error)
+	FindLedger(ctx context.Context, filter StockLedgerFilter, after *StockLedgerCursor, limit int, newestFirst bool) ([]models.StockLedgerEntry, error)
 }
 
 type medicineStockActivityRepository struct {
@@ -58,3 +79,75 @@ func (r *medicineStockActivityRepository) FindAllByMedicineID(
 
 	return activities, total, nil
 }
+
+// runningOver accumulates in chronological order over the filtered
+// activities, so totals do not depend on the page or order requested.
+const runningOver = " OVER (ORDER BY a.created_at ASC, a.id ASC)"
+
+// FindLedger returns up to limit activities following after in the requested
+// order, with the running totals of every matching activity up to each row.
+func (r *medicineStockActivityRepository) FindLedger(
+	ctx context.Context,
+	filter StockLedgerFilter,
+	after *StockLedgerCursor,
+	limit int,
+	newestFirst bool,
+) ([]models.StockLedgerEntry, error) {
+	ledger := r.db.
+		Table("medicine_stock_activities a").
+		Select("a.id, a.medicine_id, a.medicine_batch_id, a.patient_checkup_id, a.stock_adjustment_id, a.disposal_id, a.goods_receipt_id, a.reason_code, " +
+			"a.change_type, a.source, a.quantity_delta, a.stock_before, a.stock_after, a.expired_delta, a.notes, a.created_by_user_id, a.created_at, " +
+			"SUM(GREATEST(a.quantity_delta, 0))" + runningOver + " AS running_increase, " +
+			"SUM(GREATEST(-a.quantity_delta, 0))" + runningOver + " AS running_decrease, " +
+			"SUM(a.quantity_delta)" + runningOver + " AS running_net").
+		Where("a.deleted_at IS NULL")
+
+	if filter.From != nil {
+		ledger = ledger.Where("a.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		ledger = ledger.Where("a.created_at < ?", *filter.To)
+	}
+	if filter.Source != "" {
+		ledger = ledger.Where("a.source = ?", filter.Source)
+	}
+	if filter.ChangeType != "" {
+		ledger = ledger.Where("a.change_type = ?", filter.ChangeType)
+	}
+	if filter.MedicineID != "" {
+		ledger = ledger.Where("a.medicine_id = ?", filter.MedicineID)
+	}
+	if filter.UserID != "" {
+		ledger = ledger.Where("a.created_by_user_id = ?", filter.UserID)
+	}
+	if filter.BatchID != "" {
+		ledger = ledger.Where("a.medicine_batch_id = ?", filter.BatchID)
+	}
+	if filter.CheckupID != "" {
+		ledger = ledger.Where("a.patient_checkup_id = ?", filter.CheckupID)
+	}
+
+	direction := "ASC"
+	comparison := ">"
+	if newestFirst {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	query := r.db.WithContext(ctx).
+		Table("(?) AS l", ledger).
+		Select("l.*, m.code AS medicine_code, m.name AS medicine_name, b.batch_number, u.name AS created_by_name").
+		Joins("JOIN medicines m ON m.id = l.medicine_id").
+		Joins("LEFT JOIN medicine_batches b ON b.id = l.medicine_batch_id").
+		Joins("LEFT JOIN users u ON u.id = l.created_by_user_id")
+	if after != nil {
+		query = query.Where("(l.created_at, l.id) "+comparison+" (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var entries []models.StockLedgerEntry
+	err := query.
+		Order("l.created_at " + direction + ", l.id " + direction).
+		Limit(limit).
+		Scan(&entries).Error
+	return entries, err
+}
@@ -16,12 +16,24 @@ type PatientCheckupFilter struct {
 	VisitDate     *time.Time
 	VisitDateFrom *time.Time
 	VisitDateTo   *time.Time
+
+	Fever                 *bool
+	Hypoxia               *bool
+	BloodPressureCategory string
+	// AbnormalVitals matches checkups with any vital flagged: fever, hypoxia
+	// or a hypertensive blood pressure category.
+	AbnormalVitals *bool
+	// DiagnosisCode matches checkups with a primary or secondary diagnosis
+	// starting with the code, so "J06" also finds "J06.9".
+	DiagnosisCode string
+	DoctorID      string
 }
 
 type PatientCheckupRepository interface {
 	Create(ctx context.Context, checkup *models.PatientCheckup) error
 	FindByID(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error)
 	FindAll(ctx context.Context, page, perPage int, filter PatientCheckupFilter) ([]models.PatientCheckup, int64, error)
+	Stream(ctx context.Context, filter PatientCheckupFilter, batchSize int, fn func([]models.PatientCheckup) error) error
 	Update(ctx context.Context, checkup *models.PatientCheckup) error
 	Delete(ctx context.Context, id generated.IdParam) error
 }
@@ -40,7 +52,9 @@ func (r *patientCheckupRepository) Create(ctx context.Context, checkup *models.P
 
 func (r *patientCheckupRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error) {
 	var checkup models.PatientCheckup
-	err := r.db.WithContext(ctx).First(&checkup, id).Error
+	err := r.db.WithContext(ctx).
+		Scopes(PreloadCheckupDiagnoses).
+		First(&checkup, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +66,77 @@ func (r *patientCheckupRepository) FindAll(ctx context.Context, page, perPage in
 	var total int64
 
 	offset := (page - 1) * perPage
-	query := r.db.WithContext(ctx).Model(&models.PatientCheckup{})
+	query := applyCheckupFilter(r.db.WithContext(ctx).Model(&models.PatientCheckup{}), filter)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Scopes(PreloadCheckupDiagnoses).
+		Order("visit_date DESC, created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&checkups).Error
+
+	return checkups, total, err
+}
+
+// Stream passes the checkups matching filter to fn in batches of batchSize,
+// oldest visit first, with the patient and coded diagnoses loaded. Batches
+// continue from the last row read, so memory use does not grow with the
+// number of matching checkups.
+func (r *patientCheckupRepository) Stream(ctx context.Context, filter PatientCheckupFilter, batchSize int, fn func([]models.PatientCheckup) error) error {
+	var last *models.PatientCheckup
+	for {
+		query := applyCheckupFilter(r.db.WithContext(ctx).Model(&models.PatientCheckup{}), filter)
+		if last != nil {
+			query = query.Where("(visit_date, id) > (?, ?)", last.VisitDate, last.ID)
+		}
+
+		var batch []models.PatientCheckup
+		if err := query.
+			Preload("Patient").
+			Scopes(PreloadCheckupDiagnoses).
+			Order("visit_date ASC, id ASC").
+			Limit(batchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+func (r *patientCheckupRepository) Update(ctx context.Context, checkup *models.PatientCheckup) error {
+	return r.db.WithContext(ctx).Save(checkup).Error
+}
+
+func (r *patientCheckupRepository) Delete(ctx context.Context, id generated.IdParam) error {
+	return r.db.WithContext(ctx).Delete(&models.PatientCheckup{}, id).Error
+}
 
+// PreloadCheckupDiagnoses loads the coded diagnoses of checkups with their
+// catalog names, primary diagnosis first.
+func PreloadCheckupDiagnoses(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Diagnoses", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Diagnoses.ICD10Code")
+}
+
+// applyCheckupFilter narrows query to the checkups matching filter.
+func applyCheckupFilter(query *gorm.DB, filter PatientCheckupFilter) *gorm.DB {
 	if filter.Search != "" {
 		searchPattern := "%" + filter.Search + "%"
 		query = query.Where(
@@ -82,23 +165,37 @@ func (r *patientCheckupRepository) FindAll(ctx context.Context, page, perPage in
 		query = query.Where("DATE(visit_date) <= ?", filter.VisitDateTo.Format("2006-01-02"))
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if filter.Fever != nil {
+		query = query.Where("fever = ?", *filter.Fever)
 	}
 
-	err := query.
-		Order("visit_date DESC, created_at DESC").
-		Offset(offset).
-		Limit(perPage).
-		Find(&checkups).Error
+	if filter.Hypoxia != nil {
+		query = query.Where("hypoxia = ?", *filter.Hypoxia)
+	}
 
-	return checkups, total, err
-}
+	if filter.BloodPressureCategory != "" {
+		query = query.Where("blood_pressure_category = ?", filter.BloodPressureCategory)
+	}
 
-func (r *patientCheckupRepository) Update(ctx context.Context, checkup *models.PatientCheckup) error {
-	return r.db.WithContext(ctx).Save(checkup).Error
-}
+	if filter.AbnormalVitals != nil {
+		abnormal := "(fever OR hypoxia OR COALESCE(blood_pressure_category IN ?, FALSE))"
+		if *filter.AbnormalVitals {
+			query = query.Where(abnormal, models.HypertensiveCategories)
+		} else {
+			query = query.Where("NOT "+abnormal, models.HypertensiveCategories)
+		}
+	}
 
-func (r *patientCheckupRepository) Delete(ctx context.Context, id generated.IdParam) error {
-	return r.db.WithContext(ctx).Delete(&models.PatientCheckup{}, id).Error
+	if filter.DoctorID != "" {
+		query = query.Where("doctor_id = ?", filter.DoctorID)
+	}
+
+	if filter.DiagnosisCode != "" {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM patient_checkup_diagnoses d WHERE d.patient_checkup_id = patient_checkups.id AND d.code LIKE ?)",
+			codePrefixPattern(filter.DiagnosisCode),
+		)
+	}
+
+	return query
 }
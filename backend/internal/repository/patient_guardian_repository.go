@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type PatientGuardianRepository interface {
+	Create(ctx context.Context, guardian *models.PatientGuardian) error
+	FindByID(ctx context.Context, patientID string, id generated.GuardianIdParam) (*models.PatientGuardian, error)
+	FindAllByPatientID(ctx context.Context, patientID string) ([]models.PatientGuardian, error)
+	Update(ctx context.Context, guardian *models.PatientGuardian) error
+}
+
+type patientGuardianRepository struct {
+	db *gorm.DB
+}
+
+func NewPatientGuardianRepository(db *gorm.DB) PatientGuardianRepository {
+	return &patientGuardianRepository{db: db}
+}
+
+func (r *patientGuardianRepository) Create(ctx context.Context, guardian *models.PatientGuardian) error {
+	return r.db.WithContext(ctx).Create(guardian).Error
+}
+
+func (r *patientGuardianRepository) FindByID(ctx context.Context, patientID string, id generated.GuardianIdParam) (*models.PatientGuardian, error) {
+	var guardian models.PatientGuardian
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND patient_id = ?", id, patientID).
+		First(&guardian).Error
+	if err != nil {
+		return nil, err
+	}
+	return &guardian, nil
+}
+
+func (r *patientGuardianRepository) FindAllByPatientID(ctx context.Context, patientID string) ([]models.PatientGuardian, error) {
+	var guardians []models.PatientGuardian
+	err := r.db.WithContext(ctx).
+		Where("patient_id = ?", patientID).
+		Order("is_primary DESC, created_at ASC").
+		Find(&guardians).Error
+	return guardians, err
+}
+
+func (r *patientGuardianRepository) Update(ctx context.Context, guardian *models.PatientGuardian) error {
+	return r.db.WithContext(ctx).Save(guardian).Error
+}
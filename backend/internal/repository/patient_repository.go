@@ -36,7 +36,9 @@ func (r *patientRepository) Create(ctx context.Context, patient *models.Patient)
 
 func (r *patientRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.Patient, error) {
 	var patient models.Patient
-	err := r.db.WithContext(ctx).First(&patient, id).Error
+	err := r.db.WithContext(ctx).
+		Preload("Guardians", orderGuardians).
+		First(&patient, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +77,7 @@ func (r *patientRepository) FindAll(ctx context.Context, page, perPage int, filt
 	}
 
 	err := query.
+		Preload("Guardians", orderGuardians).
 		Offset(offset).
 		Limit(perPage).
 		Find(&patients).Error
@@ -82,9 +85,13 @@ func (r *patientRepository) FindAll(ctx context.Context, page, perPage int, filt
 }
 
 func (r *patientRepository) Update(ctx context.Context, patient *models.Patient) error {
-	return r.db.WithContext(ctx).Save(patient).Error
+	return r.db.WithContext(ctx).Omit("Guardians").Save(patient).Error
 }
 
 func (r *patientRepository) Delete(ctx context.Context, id generated.IdParam) error {
 	return r.db.WithContext(ctx).Delete(&models.Patient{}, id).Error
 }
+
+func orderGuardians(db *gorm.DB) *gorm.DB {
+	return db.Order("is_primary DESC, created_at ASC")
+}
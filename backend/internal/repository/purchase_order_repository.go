@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID string
+	Search     string // order number
+}
+
+type PurchaseOrderRepository interface {
+	FindByID(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error)
+	FindAll(ctx context.Context, page, perPage int, filter PurchaseOrderFilter) ([]models.PurchaseOrder, int64, error)
+	FindReceipts(ctx context.Context, purchaseOrderID generated.IdParam) ([]models.GoodsReceipt, error)
+	FindReceiptByID(ctx context.Context, id string) (*models.GoodsReceipt, error)
+}
+
+type purchaseOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(db *gorm.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+func (r *purchaseOrderRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := r.db.WithContext(ctx).
+		Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Lines.Medicine").
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *purchaseOrderRepository) FindAll(ctx context.Context, page, perPage int, filter PurchaseOrderFilter) ([]models.PurchaseOrder, int64, error) {
+	var orders []models.PurchaseOrder
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.PurchaseOrder{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SupplierID != "" {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+	if filter.Search != "" {
+		query = query.Where("number ILIKE ?", "%"+filter.Search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Lines.Medicine").
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// FindReceipts returns the goods receipts of a purchase order, oldest first.
+func (r *purchaseOrderRepository) FindReceipts(ctx context.Context, purchaseOrderID generated.IdParam) ([]models.GoodsReceipt, error) {
+	var receipts []models.GoodsReceipt
+	err := r.receiptQuery(ctx).
+		Where("purchase_order_id = ?", purchaseOrderID).
+		Order("received_at ASC, id ASC").
+		Find(&receipts).Error
+	return receipts, err
+}
+
+func (r *purchaseOrderRepository) FindReceiptByID(ctx context.Context, id string) (*models.GoodsReceipt, error) {
+	var receipt models.GoodsReceipt
+	if err := r.receiptQuery(ctx).First(&receipt, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (r *purchaseOrderRepository) receiptQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Preload("ReceivedBy").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Lines.Medicine").
+		Preload("Lines.MedicineBatch")
+}
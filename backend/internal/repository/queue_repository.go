@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QueueOrder puts waiting entries first, most urgent priority first and then
+// in order of arrival; untriaged entries wait behind routine ones. Called and
+// no-show entries follow in the order they left the queue.
+const QueueOrder = "CASE status WHEN 'waiting' THEN 0 ELSE 1 END, " +
+	"CASE WHEN status <> 'waiting' THEN 0 WHEN priority = 'emergency' THEN 0 WHEN priority = 'urgent' THEN 1 WHEN priority = 'routine' THEN 2 ELSE 3 END, " +
+	"CASE WHEN status = 'waiting' THEN queued_at ELSE COALESCE(called_at, updated_at) END, " +
+	"queue_number"
+
+type QueueRepository interface {
+	FindByID(ctx context.Context, id generated.IdParam) (*models.QueueEntry, error)
+	FindByDate(ctx context.Context, date time.Time, status string) ([]models.QueueEntry, error)
+}
+
+type queueRepository struct {
+	db *gorm.DB
+}
+
+func NewQueueRepository(db *gorm.DB) QueueRepository {
+	return &queueRepository{db: db}
+}
+
+func (r *queueRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.QueueEntry, error) {
+	var entry models.QueueEntry
+	err := r.db.WithContext(ctx).
+		Preload("Patient").
+		First(&entry, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *queueRepository) FindByDate(ctx context.Context, date time.Time, status string) ([]models.QueueEntry, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Patient").
+		Where("queue_date = ?", date.Format("2006-01-02"))
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var entries []models.QueueEntry
+	if err := query.Order(QueueOrder).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
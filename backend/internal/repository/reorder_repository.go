@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReorderCandidateFilter narrows the candidates. SupplierID keeps the
+// medicines whose latest delivery came from that supplier.
+type ReorderCandidateFilter struct {
+	Search      string
+	MedicineIDs []string
+	SupplierID  string
+}
+
+type ReorderRepository interface {
+	FindCandidates(ctx context.Context, filter ReorderCandidateFilter) ([]models.ReorderCandidate, error)
+	DailyConsumption(ctx context.Context, from, to time.Time, loc *time.Location, medicineIDs []string) ([]models.DailyConsumption, error)
+}
+
+type reorderRepository struct {
+	db *gorm.DB
+}
+
+func NewReorderRepository(db *gorm.DB) ReorderRepository {
+	return &reorderRepository{db: db}
+}
+
+// FindCandidates lists the active medicines by name. Stock on order counts
+// what is outstanding on draft, sent and partially received orders, so a
+// suggestion already turned into a draft is not suggested again.
+func (r *reorderRepository) FindCandidates(ctx context.Context, filter ReorderCandidateFilter) ([]models.ReorderCandidate, error) {
+	onOrder := r.db.
+		Table("purchase_order_lines l").
+		Select("l.medicine_id, SUM(l.quantity_ordered - l.quantity_received) AS quantity").
+		Joins("JOIN purchase_orders po ON po.id = l.purchase_order_id").
+		Where("po.status IN ? AND l.quantity_received < l.quantity_ordered", []string{
+			models.PurchaseOrderStatusDraft,
+			models.PurchaseOrderStatusSent,
+			models.PurchaseOrderStatusPartiallyReceived,
+		}).
+		Group("l.medicine_id")
+
+	query := r.db.WithContext(ctx).
+		Table("medicines m").
+		Select("m.id AS medicine_id, m.code AS medicine_code, m.name AS medicine_name, m.unit, "+
+			"m.current_stock, m.minimum_stock, COALESCE(o.quantity, 0) AS on_order, "+
+			"(SELECT b.unit_cost FROM medicine_batches b WHERE b.medicine_id = m.id AND b.unit_cost IS NOT NULL "+
+			"ORDER BY b.created_at DESC LIMIT 1) AS unit_cost").
+		Joins("LEFT JOIN (?) AS o ON o.medicine_id = m.id", onOrder).
+		Where("m.status = ? AND m.deleted_at IS NULL", "active")
+
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		query = query.Where("m.name ILIKE ? OR m.code ILIKE ?", searchPattern, searchPattern)
+	}
+	if len(filter.MedicineIDs) > 0 {
+		query = query.Where("m.id IN ?", filter.MedicineIDs)
+	}
+	if filter.SupplierID != "" {
+		query = query.Where("(SELECT po.supplier_id FROM goods_receipt_lines grl "+
+			"JOIN goods_receipts gr ON gr.id = grl.goods_receipt_id "+
+			"JOIN purchase_orders po ON po.id = gr.purchase_order_id "+
+			"WHERE grl.medicine_id = m.id ORDER BY gr.received_at DESC, grl.created_at DESC LIMIT 1) = ?", filter.SupplierID)
+	}
+
+	var candidates []models.ReorderCandidate
+	err := query.Order("m.name ASC, m.id ASC").Scan(&candidates).Error
+	return candidates, err
+}
+
+// DailyConsumption sums the patient checkup outflow per medicine and clinic
+// day. Days without any are left out.
+func (r *reorderRepository) DailyConsumption(ctx context.Context, from, to time.Time, loc *time.Location, medicineIDs []string) ([]models.DailyConsumption, error) {
+	query := r.db.WithContext(ctx).
+		Table("medicine_stock_activities a").
+		Select("a.medicine_id, (a.created_at AT TIME ZONE ?)::date AS day, SUM(-a.quantity_delta) AS quantity", loc.String()).
+		Where("a.deleted_at IS NULL AND a.source = ? AND a.created_at >= ? AND a.created_at < ?", "patient_checkup", from, to).
+		Group("a.medicine_id, day")
+	if len(medicineIDs) > 0 {
+		query = query.Where("a.medicine_id IN ?", medicineIDs)
+	}
+
+	var rows []models.DailyConsumption
+	err := query.Scan(&rows).Error
+	return rows, err
+}
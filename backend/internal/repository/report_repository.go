@@ -0,0 +1,439 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DiagnosisReportFilter selects the completed checkups counted in the
+// diagnosis report.
+type DiagnosisReportFilter struct {
+	VisitDateFrom *time.Time
+	VisitDateTo   *time.Time
+	Code          string // code prefix, e.g. "J" or "J06"
+	PrimaryOnly   bool
+	ByCategory    bool // group by the three-character category instead of the full code
+	Limit         int
+}
+
+const (
+	UsagePeriodTotal = "total"
+	UsagePeriodDay   = "day"
+	UsagePeriodWeek  = "week"
+	UsagePeriodMonth = "month"
+
+	UsageRankByQuantity = "quantity"
+	UsageRankByValue    = "value"
+)
+
+// MedicineUsageFilter selects the stock activities counted in the medicine
+// usage report. From and To bound created_at, To exclusive.
+type MedicineUsageFilter struct {
+	From       time.Time
+	To         time.Time
+	Location   *time.Location // time zone the periods are cut in
+	Period     string         // total, day, week or month
+	BySource   bool
+	RankBy     string // quantity or value
+	Limit      int    // number of medicines
+	MedicineID string
+}
+
+// StockActivityLogFilter selects the stock activities of the activity log
+// export. From and To bound created_at, To exclusive.
+type StockActivityLogFilter struct {
+	From       time.Time
+	To         time.Time
+	MedicineID string
+}
+
+// OutstandingPurchaseOrderFilter selects the order lines of the outstanding
+// purchase order report. Today is the clinic date lateness is measured on.
+type OutstandingPurchaseOrderFilter struct {
+	Today       time.Time
+	SupplierID  string
+	OverdueOnly bool
+}
+
+// SupplierLeadTimeFilter selects the goods receipts counted in the supplier
+// lead time report. From and To bound received_at, To exclusive.
+type SupplierLeadTimeFilter struct {
+	From       time.Time
+	To         time.Time
+	Location   *time.Location // time zone receipts are compared to expected dates in
+	SupplierID string
+}
+
+// InventoryValuationFilter selects the stock valued in the inventory
+// valuation. Stock is taken as it stood at Until, the end of the clinic date
+// AsOf; batches past their expiration date on AsOf count as expired.
+type InventoryValuationFilter struct {
+	AsOf       time.Time
+	Until      time.Time
+	ByBatch    bool
+	MedicineID string
+}
+
+const (
+	CostGroupByNone        = "none"
+	CostGroupByPatientType = "patient_type"
+	CostGroupByCheckup     = "checkup"
+)
+
+// CostOfGoodsDispensedFilter selects the dispensing activities counted in
+// the cost of goods dispensed. From and To bound created_at, To exclusive.
+type CostOfGoodsDispensedFilter struct {
+	From       time.Time
+	To         time.Time
+	Location   *time.Location // time zone the periods are cut in
+	Period     string         // total, day, week or month
+	GroupBy    string         // none, patient_type or checkup
+	MedicineID string
+}
+
+type ReportRepository interface {
+	DiagnosisReport(ctx context.Context, filter DiagnosisReportFilter) ([]models.DiagnosisReportRow, error)
+	MedicineUsage(ctx context.Context, filter MedicineUsageFilter) ([]models.MedicineUsageRow, error)
+	StreamStockActivities(ctx context.Context, filter StockActivityLogFilter, batchSize int, fn func([]models.StockActivityLogRow) error) error
+	OutstandingPurchaseOrders(ctx context.Context, filter OutstandingPurchaseOrderFilter) ([]models.OutstandingPurchaseOrderRow, error)
+	SupplierLeadTimes(ctx context.Context, filter SupplierLeadTimeFilter) ([]models.SupplierLeadTimeRow, error)
+	InventoryValuation(ctx context.Context, filter InventoryValuationFilter) ([]models.InventoryValuationRow, error)
+	CostOfGoodsDispensed(ctx context.Context, filter CostOfGoodsDispensedFilter) ([]models.CostOfGoodsDispensedRow, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// DiagnosisReport counts completed checkups and distinct patients per
+// diagnosis, most frequent first.
+func (r *reportRepository) DiagnosisReport(ctx context.Context, filter DiagnosisReportFilter) ([]models.DiagnosisReportRow, error) {
+	key := "d.code"
+	if filter.ByCategory {
+		key = "LEFT(d.code, 3)"
+	}
+
+	counts := r.db.WithContext(ctx).
+		Table("patient_checkup_diagnoses d").
+		Select(key+" AS code, "+
+			"COUNT(DISTINCT c.id) AS checkup_count, "+
+			"COUNT(DISTINCT c.patient_id) AS patient_count, "+
+			"COUNT(DISTINCT c.id) FILTER (WHERE d.type = ?) AS primary_count", models.DiagnosisTypePrimary).
+		Joins("JOIN patient_checkups c ON c.id = d.patient_checkup_id").
+		Where("c.deleted_at IS NULL AND c.status = ?", models.CheckupStatusCompleted).
+		Group(key)
+
+	if filter.VisitDateFrom != nil {
+		counts = counts.Where("DATE(c.visit_date) >= ?", filter.VisitDateFrom.Format("2006-01-02"))
+	}
+	if filter.VisitDateTo != nil {
+		counts = counts.Where("DATE(c.visit_date) <= ?", filter.VisitDateTo.Format("2006-01-02"))
+	}
+	if filter.Code != "" {
+		counts = counts.Where("d.code LIKE ?", codePrefixPattern(filter.Code))
+	}
+	if filter.PrimaryOnly {
+		counts = counts.Where("d.type = ?", models.DiagnosisTypePrimary)
+	}
+
+	var rows []models.DiagnosisReportRow
+	err := r.db.WithContext(ctx).
+		Table("(?) AS g", counts).
+		Select("g.code, COALESCE(i.name_id, '') AS name_id, COALESCE(i.name_en, '') AS name_en, g.checkup_count, g.patient_count, g.primary_count").
+		Joins("LEFT JOIN icd10_codes i ON i.code = g.code").
+		Order("g.checkup_count DESC, g.code ASC").
+		Limit(filter.Limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// usedQuantitySQL is what a stock activity took out of stock. Dispensing
+// counts net of medicines returned from the same checkups; of all other
+// sources only decreases count, as increases are restocks. Expiring batches
+// stay on hand and take nothing out until they are disposed of.
+const usedQuantitySQL = "CASE WHEN a.source = 'patient_checkup' THEN -a.quantity_delta WHEN a.quantity_delta < 0 THEN -a.quantity_delta ELSE 0 END"
+
+// MedicineUsage ranks the medicines by how much left stock in the period and
+// returns their usage per period and source, top medicine first. Usage is
+// valued at the unit cost of the batch each activity touched.
+func (r *reportRepository) MedicineUsage(ctx context.Context, filter MedicineUsageFilter) ([]models.MedicineUsageRow, error) {
+	period := "NULL::date"
+	var periodArgs []interface{}
+	if filter.Period != "" && filter.Period != UsagePeriodTotal {
+		period = "date_trunc(?, a.created_at AT TIME ZONE ?)::date"
+		periodArgs = []interface{}{filter.Period, filter.Location.String()}
+	}
+	source := "NULL::text"
+	if filter.BySource {
+		source = "a.source"
+	}
+
+	usage := r.db.WithContext(ctx).
+		Table("medicine_stock_activities a").
+		Select("a.medicine_id, "+period+" AS period_start, "+source+" AS source, "+usedQuantitySQL+" AS quantity, b.unit_cost", periodArgs...).
+		Joins("LEFT JOIN medicine_batches b ON b.id = a.medicine_batch_id").
+		Where("a.deleted_at IS NULL AND a.created_at >= ? AND a.created_at < ?", filter.From, filter.To)
+	if filter.MedicineID != "" {
+		usage = usage.Where("a.medicine_id = ?", filter.MedicineID)
+	}
+
+	rankBy := "SUM(u.quantity)"
+	if filter.RankBy == UsageRankByValue {
+		rankBy = "COALESCE(SUM(u.quantity * u.unit_cost), 0)"
+	}
+	ranked := r.db.
+		Table("(?) AS u", usage).
+		Select("u.medicine_id, ROW_NUMBER() OVER (ORDER BY " + rankBy + " DESC, SUM(u.quantity) DESC, u.medicine_id) AS rank").
+		Group("u.medicine_id").
+		Having("SUM(u.quantity) > 0").
+		Order("rank ASC").
+		Limit(filter.Limit)
+
+	var rows []models.MedicineUsageRow
+	err := r.db.WithContext(ctx).
+		Table("(?) AS u", usage).
+		Select("r.rank, m.id AS medicine_id, m.code AS medicine_code, m.name AS medicine_name, m.unit, "+
+			"u.period_start, u.source, SUM(u.quantity) AS quantity, "+
+			"COALESCE(SUM(u.quantity * u.unit_cost), 0) AS value, "+
+			"COALESCE(SUM(u.quantity) FILTER (WHERE u.unit_cost IS NULL), 0) AS uncosted_quantity").
+		Joins("JOIN (?) AS r ON r.medicine_id = u.medicine_id", ranked).
+		Joins("JOIN medicines m ON m.id = u.medicine_id").
+		Group("r.rank, m.id, m.code, m.name, m.unit, u.period_start, u.source").
+		Having("SUM(u.quantity) <> 0").
+		Order("r.rank ASC, u.period_start ASC, u.source ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// StreamStockActivities passes the stock activities matching filter to fn in
+// batches of batchSize, oldest first, reading each batch after the last row
+// of the previous one.
+func (r *reportRepository) StreamStockActivities(ctx context.Context, filter StockActivityLogFilter, batchSize int, fn func([]models.StockActivityLogRow) error) error {
+	var last *models.StockActivityLogRow
+	for {
+		query := r.db.WithContext(ctx).
+			Table("medicine_stock_activities a").
+			Select("a.id, a.created_at, m.code AS medicine_code, m.name AS medicine_name, "+
+				"b.batch_number, b.expiration_date, b.unit_cost, "+
+				"a.source, a.reason_code, a.change_type, a.quantity_delta, a.stock_before, a.stock_after, a.expired_delta, "+
+				"a.patient_checkup_id, u.name AS created_by_name, a.notes").
+			Joins("JOIN medicines m ON m.id = a.medicine_id").
+			Joins("LEFT JOIN medicine_batches b ON b.id = a.medicine_batch_id").
+			Joins("LEFT JOIN users u ON u.id = a.created_by_user_id").
+			Where("a.deleted_at IS NULL AND a.created_at >= ? AND a.created_at < ?", filter.From, filter.To)
+		if filter.MedicineID != "" {
+			query = query.Where("a.medicine_id = ?", filter.MedicineID)
+		}
+		if last != nil {
+			query = query.Where("(a.created_at, a.id) > (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		var batch []models.StockActivityLogRow
+		if err := query.
+			Order("a.created_at ASC, a.id ASC").
+			Limit(batchSize).
+			Scan(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+// OutstandingPurchaseOrders lists the order lines still expected from
+// suppliers, the longest overdue first.
+func (r *reportRepository) OutstandingPurchaseOrders(ctx context.Context, filter OutstandingPurchaseOrderFilter) ([]models.OutstandingPurchaseOrderRow, error) {
+	today := filter.Today.Format("2006-01-02")
+	query := r.db.WithContext(ctx).
+		Table("purchase_order_lines l").
+		Select("po.id AS purchase_order_id, po.number, po.status, s.id AS supplier_id, s.code AS supplier_code, s.name AS supplier_name, "+
+			"po.sent_at, po.expected_date, "+
+			"CASE WHEN po.expected_date < ?::date THEN ?::date - po.expected_date ELSE 0 END AS days_overdue, "+
+			"m.id AS medicine_id, m.code AS medicine_code, m.name AS medicine_name, m.unit, "+
+			"l.quantity_ordered, l.quantity_received, l.quantity_ordered - l.quantity_received AS quantity_outstanding, "+
+			"l.unit_cost, (l.quantity_ordered - l.quantity_received) * l.unit_cost AS outstanding_value", today, today).
+		Joins("JOIN purchase_orders po ON po.id = l.purchase_order_id").
+		Joins("JOIN suppliers s ON s.id = po.supplier_id").
+		Joins("JOIN medicines m ON m.id = l.medicine_id").
+		Where("po.status IN ? AND l.quantity_received < l.quantity_ordered",
+			[]string{models.PurchaseOrderStatusSent, models.PurchaseOrderStatusPartiallyReceived})
+
+	if filter.SupplierID != "" {
+		query = query.Where("po.supplier_id = ?", filter.SupplierID)
+	}
+	if filter.OverdueOnly {
+		query = query.Where("po.expected_date < ?::date", today)
+	}
+
+	var rows []models.OutstandingPurchaseOrderRow
+	err := query.
+		Order("days_overdue DESC, po.expected_date ASC NULLS LAST, po.number ASC, m.name ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// leadDaysSQL is the time from sending an order to a delivery on it, in days.
+const leadDaysSQL = "EXTRACT(EPOCH FROM g.received_at - po.sent_at) / 86400"
+
+// SupplierLeadTimes sums up the deliveries of each supplier in the range,
+// fastest supplier first.
+func (r *reportRepository) SupplierLeadTimes(ctx context.Context, filter SupplierLeadTimeFilter) ([]models.SupplierLeadTimeRow, error) {
+	receivedOn := "(g.received_at AT TIME ZONE ?)::date"
+	zone := filter.Location.String()
+	query := r.db.WithContext(ctx).
+		Table("goods_receipts g").
+		Select("s.id AS supplier_id, s.code AS supplier_code, s.name AS supplier_name, "+
+			"COUNT(DISTINCT po.id) AS order_count, COUNT(g.id) AS receipt_count, "+
+			"ROUND(AVG("+leadDaysSQL+")::numeric, 1) AS average_lead_days, "+
+			"ROUND(MIN("+leadDaysSQL+")::numeric, 1) AS min_lead_days, "+
+			"ROUND(MAX("+leadDaysSQL+")::numeric, 1) AS max_lead_days, "+
+			"COUNT(g.id) FILTER (WHERE "+receivedOn+" <= po.expected_date) AS on_time_receipts, "+
+			"COUNT(g.id) FILTER (WHERE "+receivedOn+" > po.expected_date) AS late_receipts", zone, zone).
+		Joins("JOIN purchase_orders po ON po.id = g.purchase_order_id").
+		Joins("JOIN suppliers s ON s.id = po.supplier_id").
+		Where("po.sent_at IS NOT NULL AND g.received_at >= ? AND g.received_at < ?", filter.From, filter.To).
+		Group("s.id, s.code, s.name")
+
+	if filter.SupplierID != "" {
+		query = query.Where("po.supplier_id = ?", filter.SupplierID)
+	}
+
+	var rows []models.SupplierLeadTimeRow
+	err := query.
+		Order("average_lead_days ASC, s.name ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// InventoryValuation values the stock held on a date per medicine, or per
+// batch, highest value first. The quantity of a batch on the date is its
+// quantity now less the changes logged against it since. It is valued first
+// in, first out over the goods receipt lines of the batch: the units held are
+// the last ones received, each at the cost it was received at. Units that no
+// receipt accounts for, such as batches entered by hand, take the batch's
+// unit cost.
+func (r *reportRepository) InventoryValuation(ctx context.Context, filter InventoryValuationFilter) ([]models.InventoryValuationRow, error) {
+	stock := r.db.WithContext(ctx).
+		Table("medicine_batches b").
+		Select("b.id AS medicine_batch_id, b.medicine_id, b.batch_number, b.expiration_date, b.unit_cost, "+
+			"b.expiration_date < ?::date AS expired, "+
+			"b.quantity - COALESCE((SELECT SUM(a.quantity_delta) FROM medicine_stock_activities a "+
+			"WHERE a.medicine_batch_id = b.id AND a.deleted_at IS NULL AND a.created_at >= ?), 0) AS quantity",
+			filter.AsOf.Format("2006-01-02"), filter.Until).
+		Where("b.created_at < ?", filter.Until)
+	if filter.MedicineID != "" {
+		stock = stock.Where("b.medicine_id = ?", filter.MedicineID)
+	}
+
+	// Each receipt line is a cost layer. received_since counts the units
+	// received into the batch from that layer on, so the batch quantity
+	// fills the layers newest first.
+	layers := r.db.WithContext(ctx).
+		Table("goods_receipt_lines l").
+		Select("l.quantity, l.unit_cost, "+
+			"SUM(l.quantity) OVER (ORDER BY l.created_at DESC, l.id DESC) AS received_since").
+		Where("l.medicine_batch_id = s.medicine_batch_id AND l.created_at < ?", filter.Until)
+	held := r.db.WithContext(ctx).
+		Table("(?) AS l", layers).
+		Select("SUM(h.quantity) AS quantity, " +
+			"SUM(h.quantity * l.unit_cost) AS value, " +
+			"SUM(h.quantity) FILTER (WHERE l.unit_cost IS NULL) AS uncosted_quantity").
+		Joins("CROSS JOIN LATERAL (SELECT GREATEST(LEAST(l.quantity, s.quantity - l.received_since + l.quantity), 0) AS quantity) AS h")
+	valued := r.db.WithContext(ctx).
+		Table("(?) AS s", stock).
+		Select("s.medicine_batch_id, s.medicine_id, s.batch_number, s.expiration_date, s.expired, s.quantity, "+
+			"COALESCE(f.value, 0) + (s.quantity - COALESCE(f.quantity, 0)) * COALESCE(s.unit_cost, 0) AS value, "+
+			"COALESCE(f.uncosted_quantity, 0) + CASE WHEN s.unit_cost IS NULL THEN s.quantity - COALESCE(f.quantity, 0) ELSE 0 END AS uncosted_quantity").
+		Joins("LEFT JOIN LATERAL (?) AS f ON true", held)
+
+	batchColumns := "NULL::uuid AS medicine_batch_id, NULL::text AS batch_number, NULL::date AS expiration_date"
+	group := "m.id, m.code, m.name, m.unit"
+	order := "value DESC, m.name ASC"
+	if filter.ByBatch {
+		batchColumns = "v.medicine_batch_id, v.batch_number, v.expiration_date"
+		group += ", v.medicine_batch_id, v.batch_number, v.expiration_date"
+		order = "m.name ASC, v.expiration_date ASC, v.batch_number ASC"
+	}
+
+	var rows []models.InventoryValuationRow
+	err := r.db.WithContext(ctx).
+		Table("(?) AS v", valued).
+		Select("m.id AS medicine_id, m.code AS medicine_code, m.name AS medicine_name, m.unit, " + batchColumns + ", " +
+			"ROUND(SUM(v.value) / NULLIF(SUM(v.quantity - v.uncosted_quantity), 0), 2) AS unit_cost, " +
+			"SUM(v.quantity) AS quantity, " +
+			"SUM(v.value) AS value, " +
+			"COALESCE(SUM(v.quantity) FILTER (WHERE v.expired), 0) AS expired_quantity, " +
+			"COALESCE(SUM(v.value) FILTER (WHERE v.expired), 0) AS expired_value, " +
+			"SUM(v.uncosted_quantity) AS uncosted_quantity").
+		Joins("JOIN medicines m ON m.id = v.medicine_id").
+		Where("v.quantity > 0").
+		Group(group).
+		Order(order).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CostOfGoodsDispensed costs the medicines dispensed from patient checkups
+// at the prices of the batches they were taken from, as logged when they were
+// dispensed. Returns are credited at the same prices in the period they
+// happen, so each row is net.
+func (r *reportRepository) CostOfGoodsDispensed(ctx context.Context, filter CostOfGoodsDispensedFilter) ([]models.CostOfGoodsDispensedRow, error) {
+	period := "NULL::date"
+	var periodArgs []interface{}
+	if filter.Period != "" && filter.Period != UsagePeriodTotal {
+		period = "date_trunc(?, a.created_at AT TIME ZONE ?)::date"
+		periodArgs = []interface{}{filter.Period, filter.Location.String()}
+	}
+	patientType := "NULL::text"
+	checkup := "NULL::uuid AS patient_checkup_id, NULL::uuid AS patient_id, NULL::text AS patient_name, NULL::timestamptz AS visit_date"
+	switch filter.GroupBy {
+	case CostGroupByPatientType:
+		patientType = "p.patient_type"
+	case CostGroupByCheckup:
+		patientType = "p.patient_type"
+		checkup = "a.patient_checkup_id, p.id AS patient_id, p.full_name AS patient_name, c.visit_date"
+	}
+
+	dispensed := r.db.WithContext(ctx).
+		Table("medicine_stock_activities a").
+		Select(period+" AS period_start, "+patientType+" AS patient_type, "+checkup+", "+
+			"a.patient_checkup_id AS checkup_id, -a.quantity_delta AS quantity, a.unit_cost, a.selling_price", periodArgs...).
+		Joins("LEFT JOIN patient_checkups c ON c.id = a.patient_checkup_id").
+		Joins("LEFT JOIN patients p ON p.id = c.patient_id").
+		Where("a.deleted_at IS NULL AND a.source = ? AND a.created_at >= ? AND a.created_at < ?", "patient_checkup", filter.From, filter.To)
+	if filter.MedicineID != "" {
+		dispensed = dispensed.Where("a.medicine_id = ?", filter.MedicineID)
+	}
+
+	var rows []models.CostOfGoodsDispensedRow
+	err := r.db.WithContext(ctx).
+		Table("(?) AS d", dispensed).
+		Select("d.period_start, d.patient_type, d.patient_checkup_id, d.patient_id, d.patient_name, d.visit_date, " +
+			"COUNT(DISTINCT d.checkup_id) AS checkup_count, " +
+			"SUM(d.quantity) AS quantity, " +
+			"COALESCE(SUM(d.quantity * d.unit_cost), 0) AS cost, " +
+			"COALESCE(SUM(d.quantity * d.selling_price), 0) AS selling_value, " +
+			"COALESCE(SUM(d.quantity) FILTER (WHERE d.unit_cost IS NULL), 0) AS uncosted_quantity, " +
+			"COALESCE(SUM(d.quantity) FILTER (WHERE d.selling_price IS NULL), 0) AS unpriced_quantity").
+		Group("d.period_start, d.patient_type, d.patient_checkup_id, d.patient_id, d.patient_name, d.visit_date").
+		Having("SUM(d.quantity) <> 0").
+		Order("d.period_start ASC, d.patient_type ASC, d.visit_date ASC, d.patient_checkup_id ASC").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RetentionRepository interface {
+	FindCandidates(ctx context.Context, patientType string, cutoff time.Time) ([]models.RetentionCandidate, error)
+	FindAllAudits(ctx context.Context, page, perPage int) ([]models.PatientErasureAudit, int64, error)
+}
+
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+// FindCandidates returns patients of the given type whose last activity, the
+// latest of their registration, their most recent visit and their latest
+// appointment that was not cancelled, is before cutoff. Appointments count
+// because they only turn into a checkup at check-in, so an upcoming booking
+// keeps the patient on file.
+func (r *retentionRepository) FindCandidates(ctx context.Context, patientType string, cutoff time.Time) ([]models.RetentionCandidate, error) {
+	var candidates []models.RetentionCandidate
+	lastActivity := "GREATEST(p.created_at, COALESCE(MAX(c.visit_date), p.created_at), " +
+		"COALESCE((SELECT MAX(a.start_at) FROM appointments a " +
+		"WHERE a.patient_id = p.id AND a.deleted_at IS NULL AND a.status <> '" + models.AppointmentStatusCancelled + "'), p.created_at))"
+
+	err := r.db.WithContext(ctx).
+		Table("patients p").
+		Select("p.id AS patient_id, p.full_name, p.patient_type, "+lastActivity+" AS last_activity_at").
+		Joins("LEFT JOIN patient_checkups c ON c.patient_id = p.id AND c.deleted_at IS NULL").
+		Where("p.deleted_at IS NULL AND p.anonymized_at IS NULL AND p.patient_type = ?", patientType).
+		Group("p.id").
+		Having(lastActivity+" < ?", cutoff).
+		Order("last_activity_at ASC").
+		Scan(&candidates).Error
+	return candidates, err
+}
+
+func (r *retentionRepository) FindAllAudits(ctx context.Context, page, perPage int) ([]models.PatientErasureAudit, int64, error) {
+	var audits []models.PatientErasureAudit
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.PatientErasureAudit{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&audits).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return audits, total, nil
+}
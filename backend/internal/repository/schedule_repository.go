@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ScheduleRepository interface {
+	FindSchedules(ctx context.Context, doctorID string) ([]models.DoctorSchedule, error)
+	FindHolidays(ctx context.Context, from, to *time.Time) ([]models.ClinicHoliday, error)
+	FindHolidayByID(ctx context.Context, id generated.IdParam) (*models.ClinicHoliday, error)
+	FindHolidayByDate(ctx context.Context, date time.Time) (*models.ClinicHoliday, error)
+	CreateHoliday(ctx context.Context, holiday *models.ClinicHoliday) error
+	DeleteHoliday(ctx context.Context, id generated.IdParam) error
+}
+
+type scheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+// FindSchedules returns the working hours of active doctors, or of a single
+// doctor when doctorID is set.
+func (r *scheduleRepository) FindSchedules(ctx context.Context, doctorID string) ([]models.DoctorSchedule, error) {
+	var schedules []models.DoctorSchedule
+
+	query := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = doctor_schedules.doctor_id AND users.role = 'doctor' AND users.is_active").
+		Preload("Doctor")
+
+	if doctorID != "" {
+		query = query.Where("doctor_schedules.doctor_id = ?", doctorID)
+	}
+
+	err := query.
+		Order("doctor_schedules.weekday ASC, doctor_schedules.start_time ASC").
+		Find(&schedules).Error
+
+	return schedules, err
+}
+
+func (r *scheduleRepository) FindHolidays(ctx context.Context, from, to *time.Time) ([]models.ClinicHoliday, error) {
+	var holidays []models.ClinicHoliday
+
+	query := r.db.WithContext(ctx).Model(&models.ClinicHoliday{})
+
+	if from != nil {
+		query = query.Where("date >= ?", from.Format("2006-01-02"))
+	}
+
+	if to != nil {
+		query = query.Where("date <= ?", to.Format("2006-01-02"))
+	}
+
+	err := query.Order("date ASC").Find(&holidays).Error
+	return holidays, err
+}
+
+func (r *scheduleRepository) FindHolidayByID(ctx context.Context, id generated.IdParam) (*models.ClinicHoliday, error) {
+	var holiday models.ClinicHoliday
+	if err := r.db.WithContext(ctx).First(&holiday, id).Error; err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+func (r *scheduleRepository) FindHolidayByDate(ctx context.Context, date time.Time) (*models.ClinicHoliday, error) {
+	var holiday models.ClinicHoliday
+	if err := r.db.WithContext(ctx).Where("date = ?", date.Format("2006-01-02")).First(&holiday).Error; err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+func (r *scheduleRepository) CreateHoliday(ctx context.Context, holiday *models.ClinicHoliday) error {
+	return r.db.WithContext(ctx).Create(holiday).Error
+}
+
+func (r *scheduleRepository) DeleteHoliday(ctx context.Context, id generated.IdParam) error {
+	return r.db.WithContext(ctx).Delete(&models.ClinicHoliday{}, id).Error
+}
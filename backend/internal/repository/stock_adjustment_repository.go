@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type StockAdjustmentFilter struct {
+	Status     string
+	Type       string
+	MedicineID string
+	BatchID    string
+}
+
+type StockAdjustmentRepository interface {
+	FindByID(ctx context.Context, id generated.IdParam) (*models.StockAdjustment, error)
+	FindAll(ctx context.Context, page, perPage int, filter StockAdjustmentFilter) ([]models.StockAdjustment, int64, error)
+}
+
+type stockAdjustmentRepository struct {
+	db *gorm.DB
+}
+
+func NewStockAdjustmentRepository(db *gorm.DB) StockAdjustmentRepository {
+	return &stockAdjustmentRepository{db: db}
+}
+
+func (r *stockAdjustmentRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.StockAdjustment, error) {
+	var adjustment models.StockAdjustment
+	err := r.db.WithContext(ctx).
+		Preload("Medicine").
+		Preload("MedicineBatch").
+		First(&adjustment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+func (r *stockAdjustmentRepository) FindAll(ctx context.Context, page, perPage int, filter StockAdjustmentFilter) ([]models.StockAdjustment, int64, error) {
+	var adjustments []models.StockAdjustment
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.StockAdjustment{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.MedicineID != "" {
+		query = query.Where("medicine_id = ?", filter.MedicineID)
+	}
+	if filter.BatchID != "" {
+		query = query.Where("medicine_batch_id = ?", filter.BatchID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Medicine").
+		Preload("MedicineBatch").
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&adjustments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return adjustments, total, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// StockTakeLineFilter narrows the lines of a stock take to the given
+// batches; an empty filter returns every line.
+type StockTakeLineFilter struct {
+	BatchIDs []string
+}
+
+type StockTakeRepository interface {
+	FindByID(ctx context.Context, id generated.IdParam) (*models.StockTake, error)
+	FindAll(ctx context.Context, page, perPage int, status string) ([]models.StockTake, int64, error)
+	FindLines(ctx context.Context, stockTakeID generated.IdParam, filter StockTakeLineFilter) ([]models.StockTakeLine, error)
+}
+
+type stockTakeRepository struct {
+	db *gorm.DB
+}
+
+func NewStockTakeRepository(db *gorm.DB) StockTakeRepository {
+	return &stockTakeRepository{db: db}
+}
+
+func (r *stockTakeRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.StockTake, error) {
+	var stockTake models.StockTake
+	if err := r.db.WithContext(ctx).First(&stockTake, id).Error; err != nil {
+		return nil, err
+	}
+	return &stockTake, nil
+}
+
+func (r *stockTakeRepository) FindAll(ctx context.Context, page, perPage int, status string) ([]models.StockTake, int64, error) {
+	var stockTakes []models.StockTake
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.StockTake{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&stockTakes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return stockTakes, total, nil
+}
+
+// FindLines returns the lines of a stock take in shelf order: by medicine
+// name, then by expiration date of the batch.
+func (r *stockTakeRepository) FindLines(ctx context.Context, stockTakeID generated.IdParam, filter StockTakeLineFilter) ([]models.StockTakeLine, error) {
+	query := r.db.WithContext(ctx).
+		Joins("JOIN medicines m ON m.id = stock_take_lines.medicine_id").
+		Joins("JOIN medicine_batches b ON b.id = stock_take_lines.medicine_batch_id").
+		Preload("Medicine").
+		Preload("MedicineBatch").
+		Where("stock_take_lines.stock_take_id = ?", stockTakeID)
+	if len(filter.BatchIDs) > 0 {
+		query = query.Where("stock_take_lines.medicine_batch_id IN ?", filter.BatchIDs)
+	}
+
+	var lines []models.StockTakeLine
+	err := query.
+		Order("m.name ASC, b.expiration_date ASC, b.batch_number ASC").
+		Find(&lines).Error
+	return lines, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type SupplierFilter struct {
+	Search   string
+	IsActive *bool
+}
+
+type SupplierRepository interface {
+	Create(ctx context.Context, supplier *models.Supplier) error
+	FindByID(ctx context.Context, id generated.IdParam) (*models.Supplier, error)
+	FindAll(ctx context.Context, page, perPage int, filter SupplierFilter) ([]models.Supplier, int64, error)
+	Update(ctx context.Context, supplier *models.Supplier) error
+}
+
+type supplierRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+func (r *supplierRepository) Create(ctx context.Context, supplier *models.Supplier) error {
+	return r.db.WithContext(ctx).Create(supplier).Error
+}
+
+func (r *supplierRepository) FindByID(ctx context.Context, id generated.IdParam) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.db.WithContext(ctx).First(&supplier, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *supplierRepository) FindAll(ctx context.Context, page, perPage int, filter SupplierFilter) ([]models.Supplier, int64, error) {
+	var suppliers []models.Supplier
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.WithContext(ctx).Model(&models.Supplier{})
+
+	// Search by name / code
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		query = query.Where("name ILIKE ? OR code ILIKE ?", searchPattern, searchPattern)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("name ASC, id ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&suppliers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return suppliers, total, nil
+}
+
+func (r *supplierRepository) Update(ctx context.Context, supplier *models.Supplier) error {
+	return r.db.WithContext(ctx).Save(supplier).Error
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAppointmentConflict   = errors.New("doctor already has an appointment in this slot")
+	ErrPatientDoubleBooked   = errors.New("patient already has an appointment at this time")
+	ErrSlotUnavailable       = errors.New("requested time is not a slot in the doctor's working hours")
+	ErrClinicClosed          = errors.New("clinic is closed on the requested date")
+	ErrAppointmentInPast     = errors.New("appointment time is in the past")
+	ErrAppointmentNotBooked  = errors.New("appointment is no longer booked")
+	ErrAppointmentNotToday   = errors.New("appointment can only be checked in on its own day")
+	ErrAppointmentNotStarted = errors.New("appointment has not started yet")
+	ErrAvailabilityRange     = errors.New("availability range must cover 1 to 31 days")
+)
+
+const maxAvailabilityDays = 31
+
+// AppointmentCheckIn carries what the front desk records on arrival. The
+// chief complaint falls back to the appointment reason.
+type AppointmentCheckIn struct {
+	ChiefComplaint *string
+	Symptoms       []string
+}
+
+type AppointmentService interface {
+	ListAppointments(ctx context.Context, page, perPage int, filter repository.AppointmentFilter) ([]models.Appointment, int64, error)
+	GetAppointment(ctx context.Context, id generated.IdParam) (*models.Appointment, error)
+	BookAppointment(ctx context.Context, appointment *models.Appointment) error
+	RescheduleAppointment(ctx context.Context, id generated.IdParam, startAt time.Time, doctorID *string) (*models.Appointment, error)
+	CancelAppointment(ctx context.Context, id generated.IdParam, reason *string) (*models.Appointment, error)
+	MarkAppointmentNoShow(ctx context.Context, id generated.IdParam) (*models.Appointment, error)
+	CheckInAppointment(ctx context.Context, id generated.IdParam, input AppointmentCheckIn) (*models.PatientCheckup, error)
+	GetAvailability(ctx context.Context, doctorID string, from, to time.Time) ([]models.AvailabilitySlot, error)
+}
+
+type appointmentService struct {
+	repo         repository.AppointmentRepository
+	scheduleRepo repository.ScheduleRepository
+	cache        cache.Cache
+	db           *gorm.DB
+	location     *time.Location
+}
+
+func NewAppointmentService(
+	repo repository.AppointmentRepository,
+	scheduleRepo repository.ScheduleRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	location *time.Location,
+) AppointmentService {
+	return &appointmentService{
+		repo:         repo,
+		scheduleRepo: scheduleRepo,
+		cache:        cache,
+		db:           db,
+		location:     location,
+	}
+}
+
+func (s *appointmentService) ListAppointments(ctx context.Context, page, perPage int, filter repository.AppointmentFilter) ([]models.Appointment, int64, error) {
+	filter.TimeZone = s.location.String()
+	return s.repo.FindAll(ctx, page, perPage, filter)
+}
+
+func (s *appointmentService) GetAppointment(ctx context.Context, id generated.IdParam) (*models.Appointment, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *appointmentService) BookAppointment(ctx context.Context, appointment *models.Appointment) error {
+	appointment.Status = models.AppointmentStatusBooked
+	appointment.CreatedByUserID = GetActorUserID(ctx)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.reserveSlot(tx, appointment); err != nil {
+			return err
+		}
+		return tx.Create(appointment).Error
+	})
+}
+
+// RescheduleAppointment moves a booked appointment to another slot, optionally
+// with another doctor. The old slot is released in the same transaction.
+func (s *appointmentService) RescheduleAppointment(
+	ctx context.Context,
+	id generated.IdParam,
+	startAt time.Time,
+	doctorID *string,
+) (*models.Appointment, error) {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		appointment, err := lockAppointment(tx, id)
+		if err != nil {
+			return err
+		}
+		if appointment.Status != models.AppointmentStatusBooked {
+			return ErrAppointmentNotBooked
+		}
+
+		appointment.StartAt = startAt
+		if doctorID != nil {
+			appointment.DoctorID = *doctorID
+		}
+		if err := s.reserveSlot(tx, appointment); err != nil {
+			return err
+		}
+
+		return tx.Model(appointment).Updates(map[string]any{
+			"doctor_id": appointment.DoctorID,
+			"start_at":  appointment.StartAt,
+			"end_at":    appointment.EndAt,
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *appointmentService) CancelAppointment(ctx context.Context, id generated.IdParam, reason *string) (*models.Appointment, error) {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		appointment, err := lockAppointment(tx, id)
+		if err != nil {
+			return err
+		}
+		if appointment.Status != models.AppointmentStatusBooked {
+			return ErrAppointmentNotBooked
+		}
+
+		return tx.Model(appointment).Updates(map[string]any{
+			"status":              models.AppointmentStatusCancelled,
+			"cancelled_at":        time.Now(),
+			"cancellation_reason": reason,
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *appointmentService) MarkAppointmentNoShow(ctx context.Context, id generated.IdParam) (*models.Appointment, error) {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		appointment, err := lockAppointment(tx, id)
+		if err != nil {
+			return err
+		}
+		if appointment.Status != models.AppointmentStatusBooked {
+			return ErrAppointmentNotBooked
+		}
+		if time.Now().Before(appointment.StartAt) {
+			return ErrAppointmentNotStarted
+		}
+
+		return tx.Model(appointment).Update("status", models.AppointmentStatusNoShow).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// CheckInAppointment turns a booked appointment into an in-progress checkup in
+// one step.
+func (s *appointmentService) CheckInAppointment(
+	ctx context.Context,
+	id generated.IdParam,
+	input AppointmentCheckIn,
+) (*models.PatientCheckup, error) {
+	var checkup *models.PatientCheckup
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		appointment, err := lockAppointment(tx, id)
+		if err != nil {
+			return err
+		}
+		if appointment.Status != models.AppointmentStatusBooked {
+			return ErrAppointmentNotBooked
+		}
+
+		now := time.Now()
+		if appointment.StartAt.In(s.location).Format("2006-01-02") != now.In(s.location).Format("2006-01-02") {
+			return ErrAppointmentNotToday
+		}
+
+		var doctorName string
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", appointment.DoctorID).
+			Select("name").
+			Scan(&doctorName).Error; err != nil {
+			return err
+		}
+
+		chiefComplaint := "Scheduled appointment"
+		if input.ChiefComplaint != nil && *input.ChiefComplaint != "" {
+			chiefComplaint = *input.ChiefComplaint
+		} else if appointment.Reason != nil && *appointment.Reason != "" {
+			chiefComplaint = *appointment.Reason
+		}
+
+		checkup = &models.PatientCheckup{
+			PatientID:      appointment.PatientID,
+			ChiefComplaint: chiefComplaint,
+			Symptoms:       input.Symptoms,
+			DoctorID:       &appointment.DoctorID,
+			DoctorName:     ptrString(doctorName),
+		}
+		reason := fmt.Sprintf("Checked in from appointment %s", appointment.ID)
+		if err := startCheckup(tx, checkup, GetActorUserID(ctx), reason); err != nil {
+			return err
+		}
+
+		return tx.Model(appointment).Updates(map[string]any{
+			"status":             models.AppointmentStatusCheckedIn,
+			"checked_in_at":      checkup.StartedAt,
+			"patient_checkup_id": checkup.ID.String(),
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	s.cache.DeletePattern(ctx, "patient_checkups:list:*")
+	return checkup, nil
+}
+
+// GetAvailability lists every slot of the doctor's working hours between the
+// from and to calendar days (inclusive, clinic time zone). Without a doctor it
+// covers all active doctors. Slots that are taken or already started are
+// returned with Available set to false; holidays yield no slots at all.
+func (s *appointmentService) GetAvailability(ctx context.Context, doctorID string, from, to time.Time) ([]models.AvailabilitySlot, error) {
+	rangeStart := s.localDay(from)
+	rangeEnd := s.localDay(to).AddDate(0, 0, 1)
+	days := 0
+	for d := rangeStart; d.Before(rangeEnd); d = d.AddDate(0, 0, 1) {
+		days++
+	}
+	if days < 1 || days > maxAvailabilityDays {
+		return nil, ErrAvailabilityRange
+	}
+
+	if doctorID != "" {
+		if err := requireDoctor(s.db.WithContext(ctx), doctorID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	schedules, err := s.scheduleRepo.FindSchedules(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := s.scheduleRepo.FindHolidays(ctx, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+	closed := make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		closed[h.Date.Format("2006-01-02")] = true
+	}
+
+	var doctorIDs []string
+	seen := make(map[string]bool)
+	for _, sch := range schedules {
+		if !seen[sch.DoctorID] {
+			seen[sch.DoctorID] = true
+			doctorIDs = append(doctorIDs, sch.DoctorID)
+		}
+	}
+
+	booked, err := s.repo.FindActiveBetween(ctx, doctorIDs, rangeStart, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	bookedByDoctor := make(map[string][]models.Appointment)
+	for _, a := range booked {
+		bookedByDoctor[a.DoctorID] = append(bookedByDoctor[a.DoctorID], a)
+	}
+
+	now := time.Now()
+	slots := []models.AvailabilitySlot{}
+	for day := rangeStart; day.Before(rangeEnd); day = day.AddDate(0, 0, 1) {
+		if closed[day.Format("2006-01-02")] {
+			continue
+		}
+
+		for _, sch := range schedules {
+			if sch.Weekday != int(day.Weekday()) {
+				continue
+			}
+
+			for _, slot := range s.scheduleSlots(day, sch) {
+				slot.Available = slot.StartAt.After(now) && !overlapsAppointment(bookedByDoctor[sch.DoctorID], slot.StartAt, slot.EndAt)
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		if !slots[i].StartAt.Equal(slots[j].StartAt) {
+			return slots[i].StartAt.Before(slots[j].StartAt)
+		}
+		return slots[i].DoctorName < slots[j].DoctorName
+	})
+
+	return slots, nil
+}
+
+// reserveSlot validates appointment.StartAt against the doctor's working
+// hours and clinic holidays, sets EndAt and makes sure neither the doctor nor
+// the patient is booked elsewhere at that time. It locks the doctor and the
+// patient so concurrent bookings for either are checked one after another.
+func (s *appointmentService) reserveSlot(tx *gorm.DB, appointment *models.Appointment) error {
+	if !appointment.StartAt.After(time.Now()) {
+		return ErrAppointmentInPast
+	}
+
+	if err := requireDoctor(tx, appointment.DoctorID, true); err != nil {
+		return err
+	}
+
+	var patientIDs []string
+	if err := tx.Raw(
+		"SELECT id FROM patients WHERE id = ? AND deleted_at IS NULL AND anonymized_at IS NULL FOR UPDATE",
+		appointment.PatientID,
+	).Scan(&patientIDs).Error; err != nil {
+		return err
+	}
+	if len(patientIDs) == 0 {
+		return fmt.Errorf("patient_id=%s: %w", appointment.PatientID, gorm.ErrRecordNotFound)
+	}
+
+	local := appointment.StartAt.In(s.location)
+
+	var holidays int64
+	if err := tx.Model(&models.ClinicHoliday{}).
+		Where("date = ?", local.Format("2006-01-02")).
+		Count(&holidays).Error; err != nil {
+		return err
+	}
+	if holidays > 0 {
+		return ErrClinicClosed
+	}
+
+	var schedules []models.DoctorSchedule
+	if err := tx.
+		Where("doctor_id = ? AND weekday = ?", appointment.DoctorID, int(local.Weekday())).
+		Find(&schedules).Error; err != nil {
+		return err
+	}
+
+	endAt, ok := s.slotEnd(local, schedules)
+	if !ok {
+		return ErrSlotUnavailable
+	}
+	appointment.StartAt = local
+	appointment.EndAt = endAt
+
+	conflicting := func(column, value string) (bool, error) {
+		query := tx.Model(&models.Appointment{}).
+			Where(column+" = ? AND status IN ? AND start_at < ? AND end_at > ?",
+				value,
+				[]string{models.AppointmentStatusBooked, models.AppointmentStatusCheckedIn},
+				appointment.EndAt, appointment.StartAt)
+		if appointment.ID != uuid.Nil {
+			query = query.Where("id <> ?", appointment.ID)
+		}
+
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	}
+
+	if taken, err := conflicting("doctor_id", appointment.DoctorID); err != nil {
+		return err
+	} else if taken {
+		return ErrAppointmentConflict
+	}
+
+	if taken, err := conflicting("patient_id", appointment.PatientID); err != nil {
+		return err
+	} else if taken {
+		return ErrPatientDoubleBooked
+	}
+
+	return nil
+}
+
+// slotEnd returns the end of the slot starting at local, which must line up
+// with the slot grid of one of the schedule blocks.
+func (s *appointmentService) slotEnd(local time.Time, schedules []models.DoctorSchedule) (time.Time, bool) {
+	if local.Second() != 0 || local.Nanosecond() != 0 {
+		return time.Time{}, false
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	for _, sch := range schedules {
+		start, errStart := parseClock(sch.StartTime)
+		end, errEnd := parseClock(sch.EndTime)
+		if errStart != nil || errEnd != nil || sch.SlotMinutes <= 0 {
+			continue
+		}
+		if minute >= start && minute+sch.SlotMinutes <= end && (minute-start)%sch.SlotMinutes == 0 {
+			return time.Date(local.Year(), local.Month(), local.Day(), 0, minute+sch.SlotMinutes, 0, 0, s.location), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// scheduleSlots lays the slot grid of one schedule block over day.
+func (s *appointmentService) scheduleSlots(day time.Time, sch models.DoctorSchedule) []models.AvailabilitySlot {
+	start, errStart := parseClock(sch.StartTime)
+	end, errEnd := parseClock(sch.EndTime)
+	if errStart != nil || errEnd != nil || sch.SlotMinutes <= 0 {
+		return nil
+	}
+
+	var slots []models.AvailabilitySlot
+	for minute := start; minute+sch.SlotMinutes <= end; minute += sch.SlotMinutes {
+		slots = append(slots, models.AvailabilitySlot{
+			DoctorID:   sch.DoctorID,
+			DoctorName: sch.Doctor.Name,
+			StartAt:    time.Date(day.Year(), day.Month(), day.Day(), 0, minute, 0, 0, s.location),
+			EndAt:      time.Date(day.Year(), day.Month(), day.Day(), 0, minute+sch.SlotMinutes, 0, 0, s.location),
+		})
+	}
+	return slots
+}
+
+// localDay interprets the calendar date of d as midnight in the clinic time zone.
+func (s *appointmentService) localDay(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, s.location)
+}
+
+func overlapsAppointment(appointments []models.Appointment, startAt, endAt time.Time) bool {
+	for _, a := range appointments {
+		if a.StartAt.Before(endAt) && a.EndAt.After(startAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func lockAppointment(tx *gorm.DB, id generated.IdParam) (*models.Appointment, error) {
+	var appointment models.Appointment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&appointment, id).Error; err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/storage"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AttachmentOwnerPatient        = "patient"
+	AttachmentOwnerPatientCheckup = "patient_checkup"
+)
+
+var (
+	ErrAttachmentTooLarge       = errors.New("attachment exceeds the maximum upload size")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+)
+
+// allowedAttachmentTypes maps sniffed MIME types to the file extension used in storage keys.
+var allowedAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+type AttachmentUploadInput struct {
+	OwnerType   string
+	OwnerID     string
+	FileName    string
+	Size        int64
+	Category    string
+	Description *string
+	Content     io.Reader
+}
+
+type AttachmentService interface {
+	Upload(ctx context.Context, input AttachmentUploadInput) (*models.Attachment, error)
+	List(ctx context.Context, ownerType, ownerID string) ([]models.Attachment, error)
+	Open(ctx context.Context, ownerType, ownerID, id string) (*models.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, ownerType, ownerID, id string) error
+}
+
+type attachmentService struct {
+	repo          repository.AttachmentRepository
+	patientRepo   repository.PatientRepository
+	checkupRepo   repository.PatientCheckupRepository
+	storage       storage.Storage
+	maxUploadSize int64
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	patientRepo repository.PatientRepository,
+	checkupRepo repository.PatientCheckupRepository,
+	storage storage.Storage,
+	maxUploadSize int64,
+) AttachmentService {
+	return &attachmentService{
+		repo:          repo,
+		patientRepo:   patientRepo,
+		checkupRepo:   checkupRepo,
+		storage:       storage,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (s *attachmentService) Upload(ctx context.Context, input AttachmentUploadInput) (*models.Attachment, error) {
+	patientID, err := s.resolvePatientID(ctx, input.OwnerType, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Size <= 0 || input.Size > s.maxUploadSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	// Trust the content, not the client: sniff the first bytes to determine the MIME type.
+	content := bufio.NewReaderSize(input.Content, 512)
+	head, err := content.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	category := input.Category
+	if category == "" {
+		category = "document"
+	}
+
+	attachment := &models.Attachment{
+		BaseUUID:         models.BaseUUID{ID: uuid.Must(uuid.NewV7())},
+		OwnerType:        input.OwnerType,
+		OwnerID:          input.OwnerID,
+		PatientID:        patientID,
+		Category:         category,
+		FileName:         filepath.Base(input.FileName),
+		ContentType:      contentType,
+		SizeBytes:        input.Size,
+		Description:      input.Description,
+		UploadedByUserID: GetActorUserID(ctx),
+	}
+	attachment.StorageKey = fmt.Sprintf("%s/%s/%s%s", input.OwnerType, input.OwnerID, attachment.ID, ext)
+
+	hash := sha256.New()
+	counter := &countingReader{reader: io.LimitReader(content, s.maxUploadSize+1)}
+	if err := s.storage.Put(ctx, attachment.StorageKey, io.TeeReader(counter, hash), input.Size, contentType); err != nil {
+		return nil, err
+	}
+	if counter.n != input.Size {
+		s.storage.Delete(ctx, attachment.StorageKey)
+		if counter.n > s.maxUploadSize {
+			return nil, ErrAttachmentTooLarge
+		}
+		return nil, fmt.Errorf("attachment size mismatch: declared %d bytes, received %d", input.Size, counter.n)
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		s.storage.Delete(ctx, attachment.StorageKey)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (s *attachmentService) List(ctx context.Context, ownerType, ownerID string) ([]models.Attachment, error) {
+	if _, err := s.resolvePatientID(ctx, ownerType, ownerID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAllByOwner(ctx, ownerType, ownerID)
+}
+
+func (s *attachmentService) Open(ctx context.Context, ownerType, ownerID, id string) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.FindByOwner(ctx, ownerType, ownerID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, reader, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, ownerType, ownerID, id string) error {
+	attachment, err := s.repo.FindByOwner(ctx, ownerType, ownerID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, attachment.ID.String()); err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, attachment.StorageKey)
+}
+
+// resolvePatientID checks that the owning record exists and returns the patient it belongs to.
+func (s *attachmentService) resolvePatientID(ctx context.Context, ownerType, ownerID string) (string, error) {
+	id, err := uuid.Parse(ownerID)
+	if err != nil {
+		return "", err
+	}
+
+	switch ownerType {
+	case AttachmentOwnerPatient:
+		patient, err := s.patientRepo.FindByID(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return patient.ID.String(), nil
+	case AttachmentOwnerPatientCheckup:
+		checkup, err := s.checkupRepo.FindByID(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return checkup.PatientID, nil
+	default:
+		return "", fmt.Errorf("unsupported attachment owner type %q", ownerType)
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BatchExpiryService interface {
+	// Run marks the batches past their expiration date as expired, which
+	// takes their quantity out of the sellable stock, and raises alerts for
+	// batches coming within one of the alert horizons.
+	Run(ctx context.Context) (*models.BatchExpiryRun, error)
+	ListAlerts(ctx context.Context, page, perPage int, filter repository.BatchExpiryAlertFilter) ([]models.BatchExpiryAlert, int64, error)
+}
+
+type batchExpiryService struct {
+	repo                 repository.BatchExpiryRepository
+	cache                cache.Cache
+	db                   *gorm.DB
+	stockActivityService MedicineStockActivityService
+	notifier             notify.Notifier
+	alertDays            []int
+}
+
+func NewBatchExpiryService(
+	repo repository.BatchExpiryRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	stockActivityService MedicineStockActivityService,
+	notifier notify.Notifier,
+	alertDays []int,
+) BatchExpiryService {
+	horizons := append([]int(nil), alertDays...)
+	sort.Ints(horizons)
+	return &batchExpiryService{
+		repo:                 repo,
+		cache:                cache,
+		db:                   db,
+		stockActivityService: stockActivityService,
+		notifier:             notifier,
+		alertDays:            horizons,
+	}
+}
+
+func (s *batchExpiryService) Run(ctx context.Context) (*models.BatchExpiryRun, error) {
+	today := utcToday()
+	run := &models.BatchExpiryRun{}
+
+	expired, err := s.repo.FindNewlyExpired(ctx, today)
+	if err != nil {
+		return run, err
+	}
+
+	var errs []error
+	for i := range expired {
+		quantity, changed, err := s.expireBatch(ctx, &expired[i], today)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("expire batch %s: %w", expired[i].ID, err))
+			continue
+		}
+		if changed {
+			run.ExpiredBatches++
+			run.ExpiredQuantity += quantity
+			invalidateBatchCache(ctx, s.cache, expired[i].MedicineID, expired[i].ID.String())
+		}
+	}
+
+	alerts, err := s.raiseAlerts(ctx, today)
+	run.Alerts = alerts
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(alerts) > 0 {
+		if err := s.notifyAlerts(ctx, alerts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return run, errors.Join(errs...)
+}
+
+func (s *batchExpiryService) ListAlerts(ctx context.Context, page, perPage int, filter repository.BatchExpiryAlertFilter) ([]models.BatchExpiryAlert, int64, error) {
+	return s.repo.FindAlerts(ctx, page, perPage, filter)
+}
+
+// expireBatch marks one batch expired and logs the quantity leaving the
+// sellable stock. It reports false when someone else got to the batch first.
+func (s *batchExpiryService) expireBatch(ctx context.Context, candidate *models.MedicineBatch, today time.Time) (int, bool, error) {
+	var quantity int
+	var changed bool
+	err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		quantity, changed = 0, false
+		if err := lockMedicineStock(tx, candidate.MedicineID); err != nil {
+			return err
+		}
+
+		var batch models.MedicineBatch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, "id = ?", candidate.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if batch.Status == "expired" || !batch.ExpirationDate.Before(today) {
+			return nil
+		}
+
+		if err := tx.Model(&batch).Update("status", "expired").Error; err != nil {
+			return err
+		}
+		change, err := recalculateMedicineStockTx(tx, batch.MedicineID)
+		if err != nil {
+			return err
+		}
+
+		note := fmt.Sprintf("Batch %s expired on %s", batch.BatchNumber, batch.ExpirationDate.Format("2006-01-02"))
+		batchID := batch.ID.String()
+		if err := s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
+			MedicineID:      batch.MedicineID,
+			MedicineBatchID: &batchID,
+			Source:          "expiry",
+			StockBefore:     change.Before,
+			StockAfter:      change.After,
+			ExpiredDelta:    change.ExpiredDelta,
+			Notes:           &note,
+		}); err != nil {
+			return err
+		}
+
+		quantity, changed = batch.Quantity, true
+		return nil
+	})
+	return quantity, changed, err
+}
+
+// raiseAlerts records an alert for every batch that reached a horizon it
+// was not alerted for yet. Only the narrowest horizon reached counts, so a
+// batch first seen 20 days before expiry is alerted for 30 days alone.
+func (s *batchExpiryService) raiseAlerts(ctx context.Context, today time.Time) ([]models.BatchExpiryAlert, error) {
+	if len(s.alertDays) == 0 {
+		return nil, nil
+	}
+
+	widest := s.alertDays[len(s.alertDays)-1]
+	batches, err := s.repo.FindExpiring(ctx, today, today.AddDate(0, 0, widest))
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []models.BatchExpiryAlert
+	for _, batch := range batches {
+		daysLeft := int(batch.ExpirationDate.Sub(today).Hours() / 24)
+		horizon := widest
+		for _, days := range s.alertDays {
+			if daysLeft <= days {
+				horizon = days
+				break
+			}
+		}
+
+		alert := models.BatchExpiryAlert{
+			MedicineID:      batch.MedicineID,
+			MedicineBatchID: batch.ID.String(),
+			HorizonDays:     horizon,
+			ExpirationDate:  batch.ExpirationDate,
+			DaysLeft:        daysLeft,
+			Quantity:        batch.Quantity,
+		}
+		result := s.db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Omit(clause.Associations).
+			Create(&alert)
+		if result.Error != nil {
+			return alerts, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		alert.Medicine = batch.Medicine
+		alert.MedicineBatch = batch
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// notifyAlerts sends the new alerts to every admin in a single message.
+func (s *batchExpiryService) notifyAlerts(ctx context.Context, alerts []models.BatchExpiryAlert) error {
+	recipients, err := s.repo.FindAlertRecipients(ctx)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(alerts))
+	for i, alert := range alerts {
+		lines[i] = fmt.Sprintf("- %s batch %s: %d %s, kedaluwarsa %s (%d hari lagi)",
+			alert.Medicine.Name, alert.MedicineBatch.BatchNumber, alert.Quantity, alert.MedicineBatch.Unit,
+			alert.ExpirationDate.Format("02-01-2006"), alert.DaysLeft)
+	}
+	body := "Batch obat berikut mendekati tanggal kedaluwarsa:\n" + strings.Join(lines, "\n")
+
+	var errs []error
+	for _, user := range recipients {
+		email := user.Email
+		message := notify.Message{
+			RecipientName: user.Name,
+			Email:         &email,
+			Subject:       "Obat mendekati kedaluwarsa",
+			Body:          body,
+		}
+		if !message.HasContact() {
+			log.Printf("Expiry alert: no contact for user %s", user.ID)
+			continue
+		}
+		if err := s.notifier.Send(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("alert user %s: %w", user.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
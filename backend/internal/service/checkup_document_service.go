@@ -0,0 +1,139 @@
+package service
+
+import (
+	"backend/internal/documents"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidDocumentType = errors.New("invalid document type")
+	ErrCheckupNotPrintable = errors.New("documents can only be printed for in-progress or completed checkups")
+	ErrNoPrescription      = errors.New("checkup has no prescribed medicines")
+	ErrInvalidRestDays     = errors.New("rest days must be between 1 and 30")
+)
+
+const maxSickNoteRestDays = 30
+
+// IssueDocumentInput describes the document to print. RestDays and RestFrom
+// only apply to sick notes; rest starts on the visit date by default.
+type IssueDocumentInput struct {
+	Type     string
+	RestDays *int
+	RestFrom *time.Time
+}
+
+type CheckupDocumentService interface {
+	IssueDocument(ctx context.Context, checkupID generated.IdParam, input IssueDocumentInput) (*models.CheckupDocument, []byte, error)
+	ListDocuments(ctx context.Context, checkupID generated.IdParam) ([]models.CheckupDocument, error)
+	VerifyDocument(ctx context.Context, code string) (*models.CheckupDocument, error)
+}
+
+type checkupDocumentService struct {
+	repo      repository.CheckupDocumentRepository
+	renderer  *documents.Renderer
+	verifyURL string
+	loc       *time.Location
+}
+
+func NewCheckupDocumentService(
+	repo repository.CheckupDocumentRepository,
+	renderer *documents.Renderer,
+	verifyURL string,
+	loc *time.Location,
+) CheckupDocumentService {
+	return &checkupDocumentService{
+		repo:      repo,
+		renderer:  renderer,
+		verifyURL: strings.TrimRight(verifyURL, "/"),
+		loc:       loc,
+	}
+}
+
+// IssueDocument records a new document of the checkup and renders its PDF.
+// Every print gets its own verification code.
+func (s *checkupDocumentService) IssueDocument(
+	ctx context.Context,
+	checkupID generated.IdParam,
+	input IssueDocumentInput,
+) (*models.CheckupDocument, []byte, error) {
+	checkup, err := s.repo.FindCheckup(ctx, checkupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if checkup.Status != models.CheckupStatusInProgress && checkup.Status != models.CheckupStatusCompleted {
+		return nil, nil, ErrCheckupNotPrintable
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		return nil, nil, err
+	}
+	document := &models.CheckupDocument{
+		PatientCheckupID: checkup.ID.String(),
+		Type:             input.Type,
+		VerificationCode: code,
+		IssuedByUserID:   GetActorUserID(ctx),
+		CreatedAt:        time.Now(),
+	}
+
+	switch input.Type {
+	case models.CheckupDocumentSummary:
+	case models.CheckupDocumentPrescription:
+		if len(checkup.Medicines) == 0 {
+			return nil, nil, ErrNoPrescription
+		}
+	case models.CheckupDocumentSickNote:
+		if input.RestDays == nil || *input.RestDays < 1 || *input.RestDays > maxSickNoteRestDays {
+			return nil, nil, ErrInvalidRestDays
+		}
+		visit := checkup.VisitDate.In(s.loc)
+		from := time.Date(visit.Year(), visit.Month(), visit.Day(), 0, 0, 0, 0, time.UTC)
+		if input.RestFrom != nil {
+			from = *input.RestFrom
+		}
+		to := from.AddDate(0, 0, *input.RestDays-1)
+		document.RestDays = input.RestDays
+		document.RestFrom = &from
+		document.RestTo = &to
+	default:
+		return nil, nil, ErrInvalidDocumentType
+	}
+
+	pdf, err := s.renderer.Render(document, checkup, s.verifyURL+"/"+code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.Create(ctx, document); err != nil {
+		return nil, nil, err
+	}
+	return document, pdf, nil
+}
+
+func (s *checkupDocumentService) ListDocuments(ctx context.Context, checkupID generated.IdParam) ([]models.CheckupDocument, error) {
+	if _, err := s.repo.FindCheckup(ctx, checkupID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByCheckup(ctx, checkupID.String())
+}
+
+func (s *checkupDocumentService) VerifyDocument(ctx context.Context, code string) (*models.CheckupDocument, error) {
+	return s.repo.FindByVerificationCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// newVerificationCode returns 16 random base32 characters, short enough to
+// type in from a printout.
+func newVerificationCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(buf), nil
+}
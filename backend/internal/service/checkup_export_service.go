@@ -0,0 +1,173 @@
+package service
+
+import (
+	"backend/internal/exports"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// checkupExportBatchSize is how many checkups are read per query while
+// exporting.
+const checkupExportBatchSize = 500
+
+var checkupExportColumns = []string{
+	"Visit date",
+	"Status",
+	"Medical record number",
+	"Patient",
+	"Patient type",
+	"Hostel",
+	"Chief complaint",
+	"Symptoms",
+	"Primary diagnosis",
+	"Secondary diagnoses",
+	"Diagnosis notes",
+	"Temperature (°C)",
+	"Blood pressure (mmHg)",
+	"Heart rate (bpm)",
+	"Respiratory rate (/min)",
+	"SpO2 (%)",
+	"Height (cm)",
+	"Weight (kg)",
+	"Medicines",
+	"Treatment plan",
+	"Doctor",
+	"Follow-up date",
+}
+
+type CheckupExportService interface {
+	ExportCheckups(ctx context.Context, filter repository.PatientCheckupFilter, format string, w io.Writer) error
+	ExportPatientHistory(ctx context.Context, patientID generated.IdParam, format string, w io.Writer) error
+}
+
+type checkupExportService struct {
+	repo        repository.PatientCheckupRepository
+	patientRepo repository.PatientRepository
+	loc         *time.Location
+}
+
+func NewCheckupExportService(
+	repo repository.PatientCheckupRepository,
+	patientRepo repository.PatientRepository,
+	loc *time.Location,
+) CheckupExportService {
+	return &checkupExportService{repo: repo, patientRepo: patientRepo, loc: loc}
+}
+
+// ExportCheckups writes every checkup matching filter to w, one row per
+// checkup. Nothing is written to w when the export fails before its first
+// row.
+func (s *checkupExportService) ExportCheckups(ctx context.Context, filter repository.PatientCheckupFilter, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+
+	writer, err := exports.New(format, w, "Checkups")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader(checkupExportColumns); err != nil {
+		return err
+	}
+
+	if err := s.repo.Stream(ctx, filter, checkupExportBatchSize, func(batch []models.PatientCheckup) error {
+		for i := range batch {
+			if err := writer.WriteRow(s.checkupExportRow(&batch[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (s *checkupExportService) ExportPatientHistory(ctx context.Context, patientID generated.IdParam, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+	if _, err := s.patientRepo.FindByID(ctx, patientID); err != nil {
+		return err
+	}
+
+	return s.ExportCheckups(ctx, repository.PatientCheckupFilter{PatientID: patientID.String()}, format, w)
+}
+
+func (s *checkupExportService) checkupExportRow(c *models.PatientCheckup) []any {
+	var primary string
+	var secondary []string
+	for _, d := range c.Diagnoses {
+		label := d.Code
+		if d.ICD10Code.NameID != "" {
+			label = fmt.Sprintf("%s %s", d.Code, d.ICD10Code.NameID)
+		}
+		if d.Type == models.DiagnosisTypePrimary {
+			primary = label
+		} else {
+			secondary = append(secondary, label)
+		}
+	}
+
+	var bloodPressure any
+	if c.BloodPressureSystolic != nil && c.BloodPressureDiastolic != nil {
+		bloodPressure = fmt.Sprintf("%d/%d", *c.BloodPressureSystolic, *c.BloodPressureDiastolic)
+	}
+
+	medicines := make([]string, len(c.Medicines))
+	for i, m := range c.Medicines {
+		medicines[i] = fmt.Sprintf("%s x%d (%s, %s, %d days)", m.MedicineName, m.Quantity, m.Dosage, m.Frequency, m.DurationDays)
+	}
+
+	var followUpDate any
+	if c.FollowUpDate != nil {
+		followUpDate = c.FollowUpDate.Format("2006-01-02")
+	}
+
+	return []any{
+		c.VisitDate.In(s.loc).Format("2006-01-02 15:04"),
+		c.Status,
+		exportString(c.Patient.MedicalRecordNumber),
+		c.Patient.FullName,
+		c.Patient.PatientType,
+		exportString(c.Patient.Hostel),
+		c.ChiefComplaint,
+		strings.Join(c.Symptoms, ", "),
+		primary,
+		strings.Join(secondary, "; "),
+		exportString(c.Diagnosis),
+		exportNumber(c.TemperatureC),
+		bloodPressure,
+		exportNumber(c.HeartRate),
+		exportNumber(c.RespiratoryRate),
+		exportNumber(c.OxygenSaturation),
+		exportNumber(c.HeightCm),
+		exportNumber(c.WeightKg),
+		strings.Join(medicines, "; "),
+		exportString(c.TreatmentPlan),
+		exportString(c.DoctorName),
+		followUpDate,
+	}
+}
+
+// exportString and exportNumber turn missing values into empty cells.
+func exportString(v *string) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func exportNumber[T int | float64](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
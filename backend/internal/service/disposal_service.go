@@ -0,0 +1,328 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/documents"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidDisposal    = errors.New("invalid disposal")
+	ErrBatchNotDisposable = errors.New("batch has no stock left to dispose of")
+)
+
+var disposalMethods = map[string]bool{
+	models.DisposalMethodIncineration:         true,
+	models.DisposalMethodEncapsulation:        true,
+	models.DisposalMethodChemicalInactivation: true,
+	models.DisposalMethodReturnToSupplier:     true,
+	models.DisposalMethodWasteContractor:      true,
+	models.DisposalMethodOther:                true,
+}
+
+var disposalReasons = map[string]bool{
+	models.DisposalReasonExpired:  true,
+	models.DisposalReasonDamaged:  true,
+	models.DisposalReasonRecalled: true,
+}
+
+// DisposalItemInput selects a batch to dispose of. Reason defaults to
+// expired for expired batches and is required for any other batch.
+type DisposalItemInput struct {
+	MedicineBatchID generated.IdParam
+	Reason          string
+}
+
+type DisposalInput struct {
+	Method            string
+	WitnessName       string
+	WitnessPosition   *string
+	ReferenceDocument string
+	Notes             *string
+	DisposedAt        *time.Time
+	Items             []DisposalItemInput
+}
+
+// DisposalListInput filters disposals by method and by the clinic-local date
+// they took place on.
+type DisposalListInput struct {
+	Method   string
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+type DisposalService interface {
+	CreateDisposal(ctx context.Context, input DisposalInput) (*models.Disposal, error)
+	GetDisposal(ctx context.Context, id generated.IdParam) (*models.Disposal, error)
+	ListDisposals(ctx context.Context, page, perPage int, input DisposalListInput) ([]models.Disposal, int64, error)
+	Certificate(ctx context.Context, id generated.IdParam) (*models.Disposal, []byte, error)
+}
+
+type disposalService struct {
+	repo                 repository.DisposalRepository
+	cache                cache.Cache
+	db                   *gorm.DB
+	stockActivityService MedicineStockActivityService
+	renderer             *documents.Renderer
+	loc                  *time.Location
+}
+
+func NewDisposalService(
+	repo repository.DisposalRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	stockActivityService MedicineStockActivityService,
+	renderer *documents.Renderer,
+	loc *time.Location,
+) DisposalService {
+	return &disposalService{
+		repo:                 repo,
+		cache:                cache,
+		db:                   db,
+		stockActivityService: stockActivityService,
+		renderer:             renderer,
+		loc:                  loc,
+	}
+}
+
+// CreateDisposal disposes of whole batches: each one is zeroed out with a
+// disposal stock activity and listed on the disposal record.
+func (s *disposalService) CreateDisposal(ctx context.Context, input DisposalInput) (*models.Disposal, error) {
+	input.WitnessName = strings.TrimSpace(input.WitnessName)
+	input.ReferenceDocument = strings.TrimSpace(input.ReferenceDocument)
+	if err := validateDisposal(input); err != nil {
+		return nil, err
+	}
+
+	disposedAt := time.Now()
+	if input.DisposedAt != nil {
+		disposedAt = *input.DisposedAt
+	}
+
+	var disposal *models.Disposal
+	var disposed []models.DisposalItem
+	err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		disposed = nil
+		batches, err := disposalBatches(tx, input.Items)
+		if err != nil {
+			return err
+		}
+
+		number, err := nextYearlyNumber(tx, models.Disposal{}.TableName(), "BAP", disposedAt.In(s.loc).Year())
+		if err != nil {
+			return err
+		}
+		disposal = &models.Disposal{
+			Number:            number,
+			Method:            input.Method,
+			WitnessName:       input.WitnessName,
+			WitnessPosition:   input.WitnessPosition,
+			ReferenceDocument: input.ReferenceDocument,
+			Notes:             input.Notes,
+			DisposedAt:        disposedAt,
+			DisposedByUserID:  GetActorUserID(ctx),
+		}
+		if err := tx.Omit(clause.Associations).Create(disposal).Error; err != nil {
+			return err
+		}
+
+		for _, selected := range batches {
+			item, err := s.disposeBatchTx(ctx, tx, disposal, selected)
+			if err != nil {
+				return err
+			}
+			disposed = append(disposed, *item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range disposed {
+		invalidateBatchCache(ctx, s.cache, item.MedicineID, item.MedicineBatchID)
+	}
+	return s.repo.FindByID(ctx, disposal.ID)
+}
+
+func (s *disposalService) GetDisposal(ctx context.Context, id generated.IdParam) (*models.Disposal, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *disposalService) ListDisposals(ctx context.Context, page, perPage int, input DisposalListInput) ([]models.Disposal, int64, error) {
+	if input.DateFrom != nil && input.DateTo != nil && input.DateFrom.After(*input.DateTo) {
+		return nil, 0, fmt.Errorf("%w: date_from must not be after date_to", ErrInvalidDisposal)
+	}
+
+	filter := repository.DisposalFilter{Method: input.Method}
+	if input.DateFrom != nil {
+		from := time.Date(input.DateFrom.Year(), input.DateFrom.Month(), input.DateFrom.Day(), 0, 0, 0, 0, s.loc)
+		filter.DateFrom = &from
+	}
+	if input.DateTo != nil {
+		to := time.Date(input.DateTo.Year(), input.DateTo.Month(), input.DateTo.Day()+1, 0, 0, 0, 0, s.loc)
+		filter.DateTo = &to
+	}
+	return s.repo.FindAll(ctx, page, perPage, filter)
+}
+
+// Certificate renders the disposal record handed to inspectors.
+func (s *disposalService) Certificate(ctx context.Context, id generated.IdParam) (*models.Disposal, []byte, error) {
+	disposal, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := s.renderer.RenderDisposalCertificate(disposal)
+	if err != nil {
+		return nil, nil, err
+	}
+	return disposal, pdf, nil
+}
+
+func validateDisposal(input DisposalInput) error {
+	if !disposalMethods[input.Method] {
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidDisposal, input.Method)
+	}
+	if input.WitnessName == "" {
+		return fmt.Errorf("%w: witness_name is required", ErrInvalidDisposal)
+	}
+	if input.ReferenceDocument == "" {
+		return fmt.Errorf("%w: reference_document is required", ErrInvalidDisposal)
+	}
+	if input.DisposedAt != nil && input.DisposedAt.After(time.Now()) {
+		return fmt.Errorf("%w: disposed_at must not be in the future", ErrInvalidDisposal)
+	}
+	if len(input.Items) == 0 {
+		return fmt.Errorf("%w: at least one batch is required", ErrInvalidDisposal)
+	}
+
+	seen := make(map[generated.IdParam]bool, len(input.Items))
+	for _, item := range input.Items {
+		if seen[item.MedicineBatchID] {
+			return fmt.Errorf("%w: batch %s is listed more than once", ErrInvalidDisposal, item.MedicineBatchID)
+		}
+		seen[item.MedicineBatchID] = true
+		if item.Reason != "" && !disposalReasons[item.Reason] {
+			return fmt.Errorf("%w: unknown reason %q", ErrInvalidDisposal, item.Reason)
+		}
+	}
+	return nil
+}
+
+type disposalBatch struct {
+	medicineID string
+	batchID    string
+	reason     string
+}
+
+// disposalBatches looks up the selected batches and orders them by medicine
+// so concurrent writers take the medicine locks in the same order.
+func disposalBatches(tx *gorm.DB, items []DisposalItemInput) ([]disposalBatch, error) {
+	batches := make([]disposalBatch, len(items))
+	for i, item := range items {
+		var batch models.MedicineBatch
+		if err := tx.Select("id", "medicine_id").First(&batch, "id = ?", item.MedicineBatchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: medicine batch %s not found", ErrInvalidDisposal, item.MedicineBatchID)
+			}
+			return nil, err
+		}
+		batches[i] = disposalBatch{medicineID: batch.MedicineID, batchID: batch.ID.String(), reason: item.Reason}
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		if batches[i].medicineID != batches[j].medicineID {
+			return batches[i].medicineID < batches[j].medicineID
+		}
+		return batches[i].batchID < batches[j].batchID
+	})
+	return batches, nil
+}
+
+// disposeBatchTx zeroes out one batch and records it on the disposal.
+func (s *disposalService) disposeBatchTx(
+	ctx context.Context,
+	tx *gorm.DB,
+	disposal *models.Disposal,
+	selected disposalBatch,
+) (*models.DisposalItem, error) {
+	if err := lockMedicineStock(tx, selected.medicineID); err != nil {
+		return nil, err
+	}
+
+	batchID, reason := selected.batchID, selected.reason
+	var batch models.MedicineBatch
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, "id = ?", batchID).Error; err != nil {
+		return nil, err
+	}
+	if batch.Quantity <= 0 {
+		return nil, fmt.Errorf("%w: batch %s", ErrBatchNotDisposable, batch.BatchNumber)
+	}
+
+	expired := batchStatus(batch.ExpirationDate, batch.Quantity) == "expired"
+	switch {
+	case reason == "" && expired:
+		reason = models.DisposalReasonExpired
+	case reason == "":
+		return nil, fmt.Errorf("%w: batch %s has not expired, a reason is required", ErrInvalidDisposal, batch.BatchNumber)
+	case reason == models.DisposalReasonExpired && !expired:
+		return nil, fmt.Errorf("%w: batch %s has not expired", ErrInvalidDisposal, batch.BatchNumber)
+	}
+
+	quantity := batch.Quantity
+	if err := tx.Model(&batch).Updates(map[string]any{
+		"quantity": 0,
+		"status":   batchStatus(batch.ExpirationDate, 0),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	item := &models.DisposalItem{
+		DisposalID:      disposal.ID.String(),
+		MedicineID:      batch.MedicineID,
+		MedicineBatchID: batchID,
+		Reason:          reason,
+		Quantity:        quantity,
+		UnitCost:        batch.UnitCost,
+	}
+	if err := tx.Omit(clause.Associations).Create(item).Error; err != nil {
+		return nil, err
+	}
+
+	// The units leave the stock on hand whether or not the batch had expired;
+	// for an expired batch they also leave the expired stock.
+	change, err := recalculateMedicineStockTx(tx, batch.MedicineID)
+	if err != nil {
+		return nil, err
+	}
+	disposalID := disposal.ID.String()
+	note := fmt.Sprintf("Disposed of under %s (%s)", disposal.Number, disposal.Method)
+	if err := s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
+		MedicineID:      batch.MedicineID,
+		MedicineBatchID: &batchID,
+		DisposalID:      &disposalID,
+		ReasonCode:      &reason,
+		Source:          "disposal",
+		QuantityDelta:   -quantity,
+		StockBefore:     change.Before,
+		StockAfter:      change.After,
+		ExpiredDelta:    change.ExpiredDelta,
+		Notes:           &note,
+		CreatedByUserID: GetActorUserID(ctx),
+	}); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// nextYearlyNumber returns the next number of a record numbered per year in
+// its number column, e.g. BAP/2026/0007. The table lock keeps concurrent
+// writers from taking the same number until the transaction ends.
+func nextYearlyNumber(tx *gorm.DB, table, kind string, year int) (string, error) {
+	if err := tx.Exec(fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", table)).Error; err != nil {
+		return "", err
+	}
+
+	prefix := fmt.Sprintf("%s/%d/", kind, year)
+	var count int64
+	if err := tx.Table(table).Where("number LIKE ?", prefix+"%").Count(&count).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", prefix, count+1), nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"backend/internal/models"
+	"backend/internal/notify"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidFollowUpFilter = errors.New("invalid follow-up filter")
+
+// maxFollowUpDueWithinDays bounds how far ahead the worklist looks.
+const maxFollowUpDueWithinDays = 90
+
+type FollowUpService interface {
+	ListFollowUps(ctx context.Context, page, perPage int, status string, dueWithinDays int, patientID string) ([]models.PatientCheckup, int64, error)
+	SendReminders(ctx context.Context) (int, error)
+	Today() time.Time
+}
+
+type followUpService struct {
+	repo               repository.FollowUpRepository
+	db                 *gorm.DB
+	notifier           notify.Notifier
+	loc                *time.Location
+	reminderDaysBefore int
+}
+
+func NewFollowUpService(
+	repo repository.FollowUpRepository,
+	db *gorm.DB,
+	notifier notify.Notifier,
+	loc *time.Location,
+	reminderDaysBefore int,
+) FollowUpService {
+	return &followUpService{
+		repo:               repo,
+		db:                 db,
+		notifier:           notifier,
+		loc:                loc,
+		reminderDaysBefore: reminderDaysBefore,
+	}
+}
+
+// Today returns the current date in the clinic time zone, as midnight UTC
+// so it compares directly with follow-up dates.
+func (s *followUpService) Today() time.Time {
+	now := time.Now().In(s.loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (s *followUpService) ListFollowUps(
+	ctx context.Context,
+	page, perPage int,
+	status string,
+	dueWithinDays int,
+	patientID string,
+) ([]models.PatientCheckup, int64, error) {
+	if status != "" && status != repository.FollowUpStatusDue && status != repository.FollowUpStatusOverdue {
+		return nil, 0, ErrInvalidFollowUpFilter
+	}
+	if dueWithinDays < 0 || dueWithinDays > maxFollowUpDueWithinDays {
+		return nil, 0, ErrInvalidFollowUpFilter
+	}
+
+	today := s.Today()
+	return s.repo.FindOpen(ctx, page, perPage, repository.FollowUpFilter{
+		Today:     today,
+		DueBy:     today.AddDate(0, 0, dueWithinDays),
+		Status:    status,
+		PatientID: patientID,
+	})
+}
+
+// SendReminders notifies patients whose follow-up is coming up. Each
+// follow-up is claimed before sending so a reminder goes out at most once,
+// and released again when the channel fails so the next run retries it.
+func (s *followUpService) SendReminders(ctx context.Context) (int, error) {
+	today := s.Today()
+	candidates, err := s.repo.FindReminderCandidates(ctx, today, today.AddDate(0, 0, s.reminderDaysBefore))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for i := range candidates {
+		checkup := &candidates[i]
+		message := followUpReminder(checkup)
+		if !message.HasContact() {
+			log.Printf("Follow-up reminder: no contact for checkup %s", checkup.ID)
+			continue
+		}
+
+		claim := s.db.WithContext(ctx).
+			Model(&models.PatientCheckup{}).
+			Where("id = ? AND follow_up_reminder_sent_at IS NULL", checkup.ID).
+			Update("follow_up_reminder_sent_at", time.Now())
+		if claim.Error != nil {
+			errs = append(errs, claim.Error)
+			continue
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+
+		if err := s.notifier.Send(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("remind checkup %s: %w", checkup.ID, err))
+			if err := s.db.WithContext(ctx).
+				Model(&models.PatientCheckup{}).
+				Where("id = ?", checkup.ID).
+				Update("follow_up_reminder_sent_at", nil).Error; err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// followUpReminder addresses the reminder to the patient. Students are
+// usually minors, so theirs goes to a guardian who agreed to be notified;
+// any patient without contact details falls back to such a guardian too.
+func followUpReminder(checkup *models.PatientCheckup) notify.Message {
+	patient := checkup.Patient
+	message := notify.Message{
+		RecipientName: patient.FullName,
+		PhoneNumber:   patient.PhoneNumber,
+		Email:         patient.Email,
+	}
+
+	guardian := notifiableGuardian(&patient)
+	if guardian != nil && (patient.PatientType == "student" || !message.HasContact()) {
+		message.RecipientName = guardian.FullName
+		message.PhoneNumber = guardian.PhoneNumber
+		message.Email = guardian.Email
+	}
+
+	date := checkup.FollowUpDate.Format("02-01-2006")
+	message.Subject = "Pengingat kontrol"
+	message.Body = fmt.Sprintf("Pengingat: %s dijadwalkan kontrol ke klinik pada %s.", patient.FullName, date)
+	return message
+}
+
+// notifiableGuardian prefers the primary guardian among those who can be
+// notified.
+func notifiableGuardian(patient *models.Patient) *models.PatientGuardian {
+	var fallback *models.PatientGuardian
+	for i := range patient.Guardians {
+		guardian := &patient.Guardians[i]
+		if !guardian.CanBeNotified {
+			continue
+		}
+		if guardian.IsPrimary {
+			return guardian
+		}
+		if fallback == nil {
+			fallback = guardian
+		}
+	}
+	return fallback
+}
+
+// completeFollowUps marks the open follow-ups of the patient of checkup as
+// done by it. Only follow-ups from earlier visits count as fulfilled.
+func completeFollowUps(tx *gorm.DB, checkup *models.PatientCheckup) error {
+	return tx.Model(&models.PatientCheckup{}).
+		Where("patient_id = ? AND id <> ?", checkup.PatientID, checkup.ID).
+		Where("follow_up_date IS NOT NULL AND follow_up_completed_at IS NULL AND deleted_at IS NULL").
+		Where("visit_date < ?", checkup.VisitDate).
+		Updates(map[string]any{
+			"follow_up_completed_at": time.Now(),
+			"follow_up_checkup_id":   checkup.ID.String(),
+		}).Error
+}
+
+// reopenFollowUps undoes completeFollowUps when the checkup that fulfilled
+// the follow-ups is cancelled, missed or deleted.
+func reopenFollowUps(tx *gorm.DB, checkupID string) error {
+	return tx.Model(&models.PatientCheckup{}).
+		Where("follow_up_checkup_id = ?", checkupID).
+		Updates(map[string]any{
+			"follow_up_completed_at": nil,
+			"follow_up_checkup_id":   nil,
+		}).Error
+}
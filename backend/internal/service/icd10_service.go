@@ -0,0 +1,267 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidICD10Import   = errors.New("invalid ICD-10 import file")
+	ErrInvalidDiagnoses     = errors.New("invalid diagnoses")
+	ErrUnknownDiagnosisCode = errors.New("unknown or inactive ICD-10 code")
+)
+
+type ICD10Service interface {
+	ListCodes(ctx context.Context, page, perPage int, filter repository.ICD10CodeFilter) ([]models.ICD10Code, int64, error)
+	GetCode(ctx context.Context, code string) (*models.ICD10Code, error)
+	ImportCSV(ctx context.Context, r io.Reader) (int, error)
+}
+
+type icd10Service struct {
+	repo  repository.ICD10Repository
+	cache cache.Cache
+}
+
+func NewICD10Service(repo repository.ICD10Repository, cache cache.Cache) ICD10Service {
+	return &icd10Service{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+func (s *icd10Service) ListCodes(ctx context.Context, page, perPage int, filter repository.ICD10CodeFilter) ([]models.ICD10Code, int64, error) {
+	cacheKey := fmt.Sprintf(
+		"icd10_codes:list:%d:%d:%s:%s",
+		page,
+		perPage,
+		filter.Search,
+		boolPtrToString(filter.IsActive),
+	)
+
+	var result struct {
+		Codes []models.ICD10Code
+		Total int64
+	}
+	if err := s.cache.Get(ctx, cacheKey, &result); err == nil {
+		return result.Codes, result.Total, nil
+	}
+
+	codes, total, err := s.repo.FindAll(ctx, page, perPage, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result.Codes = codes
+	result.Total = total
+	s.cache.Set(ctx, cacheKey, result, 10*time.Minute)
+
+	return codes, total, nil
+}
+
+func (s *icd10Service) GetCode(ctx context.Context, code string) (*models.ICD10Code, error) {
+	return s.repo.FindByCode(ctx, code)
+}
+
+// ImportCSV loads catalog entries from a CSV file with a header row naming
+// the code, name_id and name_en columns; an is_active column is optional.
+// Known codes get their names refreshed, so the same file can be imported
+// again after corrections.
+func (s *icd10Service) ImportCSV(ctx context.Context, r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidICD10Import, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		// Spreadsheet exports often start with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"code", "name_id", "name_en"} {
+		if _, ok := columns[required]; !ok {
+			return 0, fmt.Errorf("%w: missing %s column", ErrInvalidICD10Import, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	byCode := make(map[string]int)
+	var codes []models.ICD10Code
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidICD10Import, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		code := models.NormalizeICD10Code(field(record, "code"))
+		if code == "" {
+			continue
+		}
+		if len(code) > 10 {
+			return 0, fmt.Errorf("%w: line %d: code %q is too long", ErrInvalidICD10Import, line, code)
+		}
+
+		nameID := field(record, "name_id")
+		nameEN := field(record, "name_en")
+		if nameID == "" && nameEN == "" {
+			return 0, fmt.Errorf("%w: line %d: code %s has no name", ErrInvalidICD10Import, line, code)
+		}
+		if nameID == "" {
+			nameID = nameEN
+		}
+		if nameEN == "" {
+			nameEN = nameID
+		}
+
+		isActive := true
+		if value := field(record, "is_active"); value != "" {
+			isActive, err = strconv.ParseBool(value)
+			if err != nil {
+				return 0, fmt.Errorf("%w: line %d: is_active %q is not a boolean", ErrInvalidICD10Import, line, value)
+			}
+		}
+
+		entry := models.ICD10Code{Code: code, NameID: nameID, NameEN: nameEN, IsActive: isActive}
+		// A later line for the same code wins; one upsert batch must not
+		// touch the same row twice.
+		if i, ok := byCode[code]; ok {
+			codes[i] = entry
+			continue
+		}
+		byCode[code] = len(codes)
+		codes = append(codes, entry)
+	}
+
+	if len(codes) == 0 {
+		return 0, fmt.Errorf("%w: no codes found", ErrInvalidICD10Import)
+	}
+
+	if err := s.repo.Upsert(ctx, codes); err != nil {
+		return 0, err
+	}
+
+	s.cache.DeletePattern(ctx, "icd10_codes:list:*")
+	return len(codes), nil
+}
+
+// normalizeDiagnoses checks a coded diagnosis list as sent by a client and
+// puts the primary diagnosis first. Catalog lookups happen later, inside the
+// transaction that stores the list.
+func normalizeDiagnoses(diagnoses []models.PatientCheckupDiagnosis) ([]models.PatientCheckupDiagnosis, error) {
+	normalized := make([]models.PatientCheckupDiagnosis, 0, len(diagnoses))
+	seen := make(map[string]bool)
+	hasPrimary := false
+
+	for _, d := range diagnoses {
+		d.Code = models.NormalizeICD10Code(d.Code)
+		if d.Code == "" {
+			return nil, fmt.Errorf("%w: diagnosis code must not be empty", ErrInvalidDiagnoses)
+		}
+		if seen[d.Code] {
+			return nil, fmt.Errorf("%w: %s is listed more than once", ErrInvalidDiagnoses, d.Code)
+		}
+		seen[d.Code] = true
+
+		if d.Type == models.DiagnosisTypePrimary {
+			if hasPrimary {
+				return nil, fmt.Errorf("%w: only one primary diagnosis is allowed", ErrInvalidDiagnoses)
+			}
+			hasPrimary = true
+			normalized = append([]models.PatientCheckupDiagnosis{d}, normalized...)
+			continue
+		}
+		d.Type = models.DiagnosisTypeSecondary
+		normalized = append(normalized, d)
+	}
+
+	if len(normalized) > 0 && !hasPrimary {
+		return nil, fmt.Errorf("%w: secondary diagnoses need a primary diagnosis", ErrInvalidDiagnoses)
+	}
+
+	for i := range normalized {
+		normalized[i].Position = i
+	}
+	return normalized, nil
+}
+
+// replaceCheckupDiagnoses stores diagnoses as the coded diagnoses of checkup,
+// replacing earlier ones. Codes must be active in the catalog unless the
+// checkup already carried them, so amending an old checkup does not fail on a
+// code that was retired since.
+func replaceCheckupDiagnoses(tx *gorm.DB, checkup *models.PatientCheckup, diagnoses []models.PatientCheckupDiagnosis) error {
+	checkupID := checkup.ID.String()
+
+	var kept []string
+	if err := tx.Model(&models.PatientCheckupDiagnosis{}).
+		Where("patient_checkup_id = ?", checkupID).
+		Pluck("code", &kept).Error; err != nil {
+		return err
+	}
+	wasKept := make(map[string]bool, len(kept))
+	for _, code := range kept {
+		wasKept[code] = true
+	}
+
+	codes := make([]string, len(diagnoses))
+	for i, d := range diagnoses {
+		codes[i] = d.Code
+	}
+	var catalog []models.ICD10Code
+	if len(codes) > 0 {
+		if err := tx.Where("code IN ?", codes).Find(&catalog).Error; err != nil {
+			return err
+		}
+	}
+	byCode := make(map[string]models.ICD10Code, len(catalog))
+	for _, c := range catalog {
+		byCode[c.Code] = c
+	}
+
+	for i := range diagnoses {
+		entry, ok := byCode[diagnoses[i].Code]
+		if !ok || (!entry.IsActive && !wasKept[entry.Code]) {
+			return fmt.Errorf("%w: %s", ErrUnknownDiagnosisCode, diagnoses[i].Code)
+		}
+		diagnoses[i].PatientCheckupID = checkupID
+		diagnoses[i].ICD10Code = entry
+	}
+
+	if err := tx.Where("patient_checkup_id = ?", checkupID).
+		Delete(&models.PatientCheckupDiagnosis{}).Error; err != nil {
+		return err
+	}
+	if len(diagnoses) > 0 {
+		if err := tx.Omit("ICD10Code").Create(&diagnoses).Error; err != nil {
+			return err
+		}
+	}
+
+	checkup.Diagnoses = diagnoses
+	return nil
+}
@@ -6,18 +6,25 @@ import (
 	"backend/internal/models"
 	"backend/internal/repository"
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrBatchInUse is returned when deleting a batch that stock already moved
+// through: its history and recall lookups need it to stay.
+var ErrBatchInUse = errors.New("batch has stock movements and cannot be deleted; dispose of it or adjust it to zero instead")
+
 type MedicineBatchService interface {
 	CreateBatch(ctx context.Context, batch *models.MedicineBatch) error
 	GetBatch(ctx context.Context, id generated.IdParam) (*models.MedicineBatch, error)
 	ListBatches(ctx context.Context, page, perPage int, filter repository.MedicineBatchFilter) ([]models.MedicineBatch, int64, error)
 	UpdateBatch(ctx context.Context, id generated.IdParam, batch *models.MedicineBatch) error
 	DeleteBatch(ctx context.Context, id generated.IdParam) error
+	ListBatchRecipients(ctx context.Context, id generated.IdParam, page, perPage int) ([]models.BatchRecipient, int64, error)
 }
 
 type medicineBatchService struct {
@@ -42,21 +49,17 @@ func NewMedicineBatchService(
 }
 
 func (s *medicineBatchService) CreateBatch(ctx context.Context, batch *models.MedicineBatch) error {
-	now := time.Now().UTC()
-	if batch.ExpirationDate.Before(now) {
-		batch.Status = "expired"
-	} else if batch.Quantity <= 0 {
-		batch.Status = "depleted"
-	} else {
-		batch.Status = "active"
-	}
+	batch.Status = batchStatus(batch.ExpirationDate, batch.Quantity)
 
-	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		if err := lockMedicineStock(tx, batch.MedicineID); err != nil {
+			return err
+		}
 		if err := tx.Create(batch).Error; err != nil {
 			return err
 		}
 
-		before, after, err := recalculateMedicineStockTx(tx, batch.MedicineID)
+		change, err := recalculateMedicineStockTx(tx, batch.MedicineID)
 		if err != nil {
 			return err
 		}
@@ -67,9 +70,10 @@ func (s *medicineBatchService) CreateBatch(ctx context.Context, batch *models.Me
 			MedicineID:      batch.MedicineID,
 			MedicineBatchID: &batchID,
 			Source:          "admin",
-			QuantityDelta:   after - before,
-			StockBefore:     before,
-			StockAfter:      after,
+			QuantityDelta:   change.After - change.Before,
+			StockBefore:     change.Before,
+			StockAfter:      change.After,
+			ExpiredDelta:    change.ExpiredDelta,
 			Notes:           &note,
 			CreatedByUserID: GetActorUserID(ctx),
 		}); err != nil {
@@ -81,7 +85,7 @@ func (s *medicineBatchService) CreateBatch(ctx context.Context, batch *models.Me
 		return err
 	}
 
-	s.invalidateBatchCache(ctx, batch.MedicineID, batch.ID.String())
+	invalidateBatchCache(ctx, s.cache, batch.MedicineID, batch.ID.String())
 	return nil
 }
 
@@ -152,56 +156,59 @@ func (s *medicineBatchService) ListBatches(
 	return batches, total, nil
 }
 
+// UpdateBatch changes the descriptive fields of a batch. The quantity is left
+// alone: stock corrections go through a stock adjustment so they are reviewed
+// and logged with a reason.
 func (s *medicineBatchService) UpdateBatch(ctx context.Context, id generated.IdParam, batch *models.MedicineBatch) error {
 	existing, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	batch.ID = existing.ID
-	batch.MedicineID = existing.MedicineID
-	batch.CreatedAt = existing.CreatedAt
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		if err := lockMedicineStock(tx, existing.MedicineID); err != nil {
+			return err
+		}
 
-	if batch.ExpirationDate.Before(time.Now().UTC()) {
-		batch.Status = "expired"
-	} else if batch.Quantity <= 0 {
-		batch.Status = "depleted"
-	} else {
-		batch.Status = "active"
-	}
+		// Re-read under lock: the quantity may have moved since the lookup above.
+		var current models.MedicineBatch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", id).Error; err != nil {
+			return err
+		}
 
-	oldQuantity := existing.Quantity
-	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Save(batch).Error; err != nil {
+		if err := tx.Model(&current).Updates(map[string]any{
+			"batch_number":    batch.BatchNumber,
+			"expiration_date": batch.ExpirationDate,
+			"unit":            batch.Unit,
+			"status":          batchStatus(batch.ExpirationDate, current.Quantity),
+		}).Error; err != nil {
 			return err
 		}
 
-		before, after, err := recalculateMedicineStockTx(tx, batch.MedicineID)
+		// A new expiration date can move the batch in or out of the expired
+		// stock, which is logged even though the quantity stays the same.
+		change, err := recalculateMedicineStockTx(tx, current.MedicineID)
 		if err != nil {
 			return err
 		}
 
 		note := "Batch updated by admin"
-		delta := batch.Quantity - oldQuantity
-		batchID := batch.ID.String()
-		if err := s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
-			MedicineID:      batch.MedicineID,
+		batchID := current.ID.String()
+		return s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
+			MedicineID:      current.MedicineID,
 			MedicineBatchID: &batchID,
 			Source:          "admin",
-			QuantityDelta:   delta,
-			StockBefore:     before,
-			StockAfter:      after,
+			StockBefore:     change.Before,
+			StockAfter:      change.After,
+			ExpiredDelta:    change.ExpiredDelta,
 			Notes:           &note,
 			CreatedByUserID: GetActorUserID(ctx),
-		}); err != nil {
-			return err
-		}
-		return nil
+		})
 	}); err != nil {
 		return err
 	}
 
-	s.invalidateBatchCache(ctx, batch.MedicineID, id.String())
+	invalidateBatchCache(ctx, s.cache, existing.MedicineID, id.String())
 	return nil
 }
 
@@ -211,12 +218,28 @@ func (s *medicineBatchService) DeleteBatch(ctx context.Context, id generated.IdP
 		return err
 	}
 
-	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Delete(&models.MedicineBatch{}, id).Error; err != nil {
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		if err := lockMedicineStock(tx, existing.MedicineID); err != nil {
 			return err
 		}
 
-		before, after, err := recalculateMedicineStockTx(tx, existing.MedicineID)
+		var current models.MedicineBatch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", id).Error; err != nil {
+			return err
+		}
+		inUse, err := batchHasMovementsTx(tx, current.ID.String())
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrBatchInUse
+		}
+
+		if err := tx.Delete(&current).Error; err != nil {
+			return err
+		}
+
+		change, err := recalculateMedicineStockTx(tx, existing.MedicineID)
 		if err != nil {
 			return err
 		}
@@ -227,9 +250,10 @@ func (s *medicineBatchService) DeleteBatch(ctx context.Context, id generated.IdP
 			MedicineID:      existing.MedicineID,
 			MedicineBatchID: &batchID,
 			Source:          "admin",
-			QuantityDelta:   -existing.Quantity,
-			StockBefore:     before,
-			StockAfter:      after,
+			QuantityDelta:   -current.Quantity,
+			StockBefore:     change.Before,
+			StockAfter:      change.After,
+			ExpiredDelta:    change.ExpiredDelta,
 			Notes:           &note,
 			CreatedByUserID: GetActorUserID(ctx),
 		}); err != nil {
@@ -241,40 +265,122 @@ func (s *medicineBatchService) DeleteBatch(ctx context.Context, id generated.IdP
 		return err
 	}
 
-	s.invalidateBatchCache(ctx, existing.MedicineID, id.String())
+	invalidateBatchCache(ctx, s.cache, existing.MedicineID, id.String())
 	return nil
 }
 
-func (s *medicineBatchService) invalidateBatchCache(ctx context.Context, medicineID, batchID string) {
-	s.cache.Delete(ctx, fmt.Sprintf("medicine_batch:%s", batchID))
-	s.cache.Delete(ctx, fmt.Sprintf("medicine:%s", medicineID))
-	s.cache.DeletePattern(ctx, "medicine_batches:list:*")
-	s.cache.DeletePattern(ctx, "medicines:list:*")
-	s.cache.DeletePattern(ctx, fmt.Sprintf("medicine:%s:batches:*", medicineID))
+// batchHasMovementsTx tells whether anything but admin edits touched a
+// batch: dispensing, receipts, adjustments, disposals or stock takes.
+func batchHasMovementsTx(tx *gorm.DB, batchID string) (bool, error) {
+	for _, model := range []any{
+		&models.PatientCheckupMedicineAllocation{},
+		&models.GoodsReceiptLine{},
+		&models.StockAdjustment{},
+		&models.DisposalItem{},
+		&models.StockTakeLine{},
+	} {
+		var count int64
+		if err := tx.Model(model).Where("medicine_batch_id = ?", batchID).Limit(1).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	var count int64
+	err := tx.Model(&models.MedicineStockActivity{}).
+		Where("medicine_batch_id = ? AND source <> ?", batchID, "admin").
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// batchStatus derives the status of a batch from its expiration date and
+// the quantity left. A batch can still be used on its expiration date.
+func batchStatus(expirationDate time.Time, quantity int) string {
+	switch {
+	case expirationDate.Before(utcToday()):
+		return "expired"
+	case quantity <= 0:
+		return "depleted"
+	default:
+		return "active"
+	}
+}
+
+// invalidateBatchCache drops the cached batch and the stock levels of its
+// medicine after the batch quantity changed.
+func invalidateBatchCache(ctx context.Context, c cache.Cache, medicineID, batchID string) {
+	c.Delete(ctx, fmt.Sprintf("medicine_batch:%s", batchID))
+	c.Delete(ctx, fmt.Sprintf("medicine:%s", medicineID))
+	c.DeletePattern(ctx, "medicine_batches:list:*")
+	c.DeletePattern(ctx, "medicines:list:*")
+	c.DeletePattern(ctx, fmt.Sprintf("medicine:%s:batches:*", medicineID))
+}
+
+// utcToday is the current date at midnight UTC, the way expiration dates are
+// stored.
+func utcToday() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
 }
 
-func recalculateMedicineStockTx(tx *gorm.DB, medicineID string) (before int, after int, err error) {
-	if err = tx.Model(&models.Medicine{}).
+// stockChange is how the stock of a medicine moved in a transaction. Before
+// and After are the stock on hand, sellable and expired together, so units
+// only leave the ledger when they leave the shelf. ExpiredDelta is the part
+// of the move that went in or out of the expired stock.
+type stockChange struct {
+	Before       int
+	After        int
+	ExpiredDelta int
+}
+
+// recalculateMedicineStockTx sums the batches of a medicine into its
+// sellable current stock and its expired stock, and returns how the stock
+// moved for the stock activity log.
+func recalculateMedicineStockTx(tx *gorm.DB, medicineID string) (stockChange, error) {
+	var previous struct {
+		CurrentStock int
+		ExpiredStock int
+	}
+	if err := tx.Model(&models.Medicine{}).
 		Where("id = ?", medicineID).
-		Select("current_stock").
-		Scan(&before).Error; err != nil {
-		return 0, 0, err
+		Select("current_stock, expired_stock").
+		Scan(&previous).Error; err != nil {
+		return stockChange{}, err
 	}
 
-	var total int64
-	if err = tx.Model(&models.MedicineBatch{}).
+	var totals struct {
+		Sellable int
+		Expired  int
+	}
+	if err := tx.Model(&models.MedicineBatch{}).
 		Where("medicine_id = ?", medicineID).
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&total).Error; err != nil {
-		return 0, 0, err
+		Select(`COALESCE(SUM(quantity) FILTER (WHERE status <> 'expired'), 0) AS sellable,
+			COALESCE(SUM(quantity) FILTER (WHERE status = 'expired'), 0) AS expired`).
+		Scan(&totals).Error; err != nil {
+		return stockChange{}, err
 	}
-	after = int(total)
 
-	if err = tx.Model(&models.Medicine{}).
+	if err := tx.Model(&models.Medicine{}).
 		Where("id = ?", medicineID).
-		Update("current_stock", after).Error; err != nil {
-		return 0, 0, err
+		Updates(map[string]any{
+			"current_stock": totals.Sellable,
+			"expired_stock": totals.Expired,
+		}).Error; err != nil {
+		return stockChange{}, err
 	}
 
-	return before, after, nil
+	return stockChange{
+		Before:       previous.CurrentStock + previous.ExpiredStock,
+		After:        totals.Sellable + totals.Expired,
+		ExpiredDelta: totals.Expired - previous.ExpiredStock,
+	}, nil
+}
+
+func (s *medicineBatchService) ListBatchRecipients(ctx context.Context, id generated.IdParam, page, perPage int) ([]models.BatchRecipient, int64, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindRecipients(ctx, id, page, perPage)
 }
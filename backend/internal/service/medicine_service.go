@@ -117,6 +117,9 @@ func (s *medicineService) UpdateMedicine(ctx context.Context, id generated.IdPar
 
 	medicine.ID = existing.ID
 	medicine.CreatedAt = existing.CreatedAt
+	// Stock levels are derived from the batches, never from the request.
+	medicine.CurrentStock = existing.CurrentStock
+	medicine.ExpiredStock = existing.ExpiredStock
 
 	if err := s.repo.Update(ctx, medicine); err != nil {
 		return err
@@ -5,58 +5,105 @@ import (
 	"backend/internal/models"
 	"backend/internal/repository"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	defaultStockLedgerLimit = 50
+	maxStockLedgerLimit     = 200
+)
+
+var (
+	ErrInvalidLedgerCursor = errors.New("invalid cursor")
+	ErrInvalidLedgerFilter = errors.New("date_from must not be after date_to")
+)
+
 type MedicineStockChangeInput struct {
-	MedicineID       string
-	MedicineBatchID  *string
-	PatientCheckupID *string
-	Source           string
-	QuantityDelta    int
-	StockBefore      int
-	StockAfter       int
-	Notes            *string
-	CreatedByUserID  *string
+	MedicineID        string
+	MedicineBatchID   *string
+	PatientCheckupID  *string
+	StockAdjustmentID *string
+	DisposalID        *string
+	GoodsReceiptID    *string
+	ReasonCode        *string
+	Source            string
+	QuantityDelta     int
+	StockBefore       int
+	StockAfter        int
+	ExpiredDelta      int
+	UnitCost          *float64
+	SellingPrice      *float64
+	Notes             *string
+	CreatedByUserID   *string
+}
+
+// StockLedgerInput asks for a page of the stock ledger. Dates are clinic
+// dates and Cursor is the next cursor of the previous page.
+type StockLedgerInput struct {
+	DateFrom    *time.Time
+	DateTo      *time.Time
+	Filter      repository.StockLedgerFilter
+	Cursor      string
+	Limit       int
+	NewestFirst bool
 }
 
 type MedicineStockActivityService interface {
 	LogStockChange(ctx context.Context, tx *gorm.DB, input MedicineStockChangeInput) error
 	ListByMedicineID(ctx context.Context, medicineID generated.IdParam, page, perPage int, filter repository.MedicineStockActivityFilter) ([]models.MedicineStockActivity, int64, error)
+	ListLedger(ctx context.Context, input StockLedgerInput) ([]models.StockLedgerEntry, string, error)
 }
 
 type medicineStockActivityService struct {
 	repo repository.MedicineStockActivityRepository
 	db   *gorm.DB
+	loc  *time.Location
 }
 
-func NewMedicineStockActivityService(repo repository.MedicineStockActivityRepository, db *gorm.DB) MedicineStockActivityService {
-	return &medicineStockActivityService{repo: repo, db: db}
+func NewMedicineStockActivityService(repo repository.MedicineStockActivityRepository, db *gorm.DB, loc *time.Location) MedicineStockActivityService {
+	return &medicineStockActivityService{repo: repo, db: db, loc: loc}
 }
 
 func (s *medicineStockActivityService) LogStockChange(ctx context.Context, tx *gorm.DB, input MedicineStockChangeInput) error {
-	if input.QuantityDelta == 0 {
+	if input.QuantityDelta == 0 && input.ExpiredDelta == 0 {
 		return nil
 	}
 
-	changeType := "increase"
-	if input.QuantityDelta < 0 {
+	// Units moving between sellable and expired stock stay on hand: the row
+	// records the move with a zero quantity delta.
+	changeType := "status"
+	switch {
+	case input.QuantityDelta > 0:
+		changeType = "increase"
+	case input.QuantityDelta < 0:
 		changeType = "decrease"
 	}
 
 	activity := &models.MedicineStockActivity{
-		MedicineID:       input.MedicineID,
-		MedicineBatchID:  input.MedicineBatchID,
-		PatientCheckupID: input.PatientCheckupID,
-		ChangeType:       changeType,
-		Source:           input.Source,
-		QuantityDelta:    input.QuantityDelta,
-		StockBefore:      input.StockBefore,
-		StockAfter:       input.StockAfter,
-		Notes:            input.Notes,
-		CreatedByUserID:  input.CreatedByUserID,
+		MedicineID:        input.MedicineID,
+		MedicineBatchID:   input.MedicineBatchID,
+		PatientCheckupID:  input.PatientCheckupID,
+		StockAdjustmentID: input.StockAdjustmentID,
+		DisposalID:        input.DisposalID,
+		GoodsReceiptID:    input.GoodsReceiptID,
+		ReasonCode:        input.ReasonCode,
+		ChangeType:        changeType,
+		Source:            input.Source,
+		QuantityDelta:     input.QuantityDelta,
+		StockBefore:       input.StockBefore,
+		StockAfter:        input.StockAfter,
+		ExpiredDelta:      input.ExpiredDelta,
+		UnitCost:          input.UnitCost,
+		SellingPrice:      input.SellingPrice,
+		Notes:             input.Notes,
+		CreatedByUserID:   input.CreatedByUserID,
 	}
 
 	writer := s.db.WithContext(ctx)
@@ -79,3 +126,76 @@ func (s *medicineStockActivityService) ListByMedicineID(
 	}
 	return s.repo.FindAllByMedicineID(ctx, medicineUUID, page, perPage, filter)
 }
+
+// ListLedger returns a page of the stock ledger and the cursor of the next
+// page, empty on the last page.
+func (s *medicineStockActivityService) ListLedger(ctx context.Context, input StockLedgerInput) ([]models.StockLedgerEntry, string, error) {
+	filter := input.Filter
+	if input.DateFrom != nil {
+		from := time.Date(input.DateFrom.Year(), input.DateFrom.Month(), input.DateFrom.Day(), 0, 0, 0, 0, s.loc)
+		filter.From = &from
+	}
+	if input.DateTo != nil {
+		to := time.Date(input.DateTo.Year(), input.DateTo.Month(), input.DateTo.Day(), 0, 0, 0, 0, s.loc).AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, "", ErrInvalidLedgerFilter
+	}
+
+	var after *repository.StockLedgerCursor
+	if input.Cursor != "" {
+		cursor, err := decodeLedgerCursor(input.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = cursor
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultStockLedgerLimit
+	}
+	if limit > maxStockLedgerLimit {
+		limit = maxStockLedgerLimit
+	}
+
+	// One extra row tells whether another page follows.
+	entries, err := s.repo.FindLedger(ctx, filter, after, limit+1, input.NewestFirst)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+
+	entries = entries[:limit]
+	last := entries[limit-1]
+	return entries, encodeLedgerCursor(repository.StockLedgerCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+
+// Ledger cursors are opaque to clients: the position of the last row of a
+// page, URL-safe encoded.
+func encodeLedgerCursor(cursor repository.StockLedgerCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeLedgerCursor(value string) (*repository.StockLedgerCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidLedgerCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, ErrInvalidLedgerCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidLedgerCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidLedgerCursor
+	}
+	return &repository.StockLedgerCursor{CreatedAt: t, ID: id}, nil
+}
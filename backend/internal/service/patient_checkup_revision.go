@@ -0,0 +1,80 @@
+package service
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func (s *patientCheckupService) ListRevisions(ctx context.Context, id generated.IdParam) ([]models.PatientCheckupRevision, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var revisions []models.PatientCheckupRevision
+	err := s.db.WithContext(ctx).
+		Omit("snapshot").
+		Where("patient_checkup_id = ?", id).
+		Order("revision ASC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetRevision returns a revision with the checkup as it was right after it.
+func (s *patientCheckupService) GetRevision(ctx context.Context, id generated.IdParam, revision int) (*models.PatientCheckupRevision, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var result models.PatientCheckupRevision
+	if err := s.db.WithContext(ctx).
+		Where("patient_checkup_id = ? AND revision = ?", id, revision).
+		First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// recordCheckupRevision stores the current state of a checkup as its next
+// revision. It must run inside the transaction that changed the checkup,
+// after the checkup row was locked, so revision numbers cannot collide.
+// Nothing is recorded when the change left the clinical record as it was.
+func recordCheckupRevision(tx *gorm.DB, checkupID string, actorID *string, reason string) error {
+	var checkup models.PatientCheckup
+	if err := tx.Scopes(repository.PreloadCheckupDiagnoses).First(&checkup, "id = ?", checkupID).Error; err != nil {
+		return err
+	}
+
+	var previous []models.PatientCheckupRevision
+	if err := tx.Where("patient_checkup_id = ?", checkupID).
+		Order("revision DESC").
+		Limit(1).
+		Find(&previous).Error; err != nil {
+		return err
+	}
+
+	snapshot := checkup.Snapshot()
+	revision := 1
+	var changes []models.CheckupFieldChange
+	if len(previous) == 0 {
+		changes = models.DiffSnapshots(nil, snapshot)
+	} else {
+		revision = previous[0].Revision + 1
+		changes = models.DiffSnapshots(&previous[0].Snapshot, snapshot)
+		if len(changes) == 0 {
+			return nil
+		}
+	}
+
+	return tx.Create(&models.PatientCheckupRevision{
+		PatientCheckupID: checkupID,
+		Revision:         revision,
+		Reason:           reason,
+		ChangedByUserID:  actorID,
+		Snapshot:         snapshot,
+		Changes:          changes,
+	}).Error
+}
@@ -6,17 +6,52 @@ import (
 	"backend/internal/models"
 	"backend/internal/repository"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrInvalidCheckupTransition = errors.New("invalid checkup status transition")
+	ErrCheckupLocked            = errors.New("checkup is locked; completed checkups can only be amended")
+	ErrCheckupNotAmendable      = errors.New("only completed checkups can be amended")
+	ErrCheckupNotDispensable    = errors.New("medicines can only be dispensed for in-progress or completed checkups")
+	ErrNothingToDispense        = errors.New("all prescribed medicines have already been dispensed")
+	ErrCheckupHasHistory        = errors.New("checkup has status changes or edits on record; cancel it instead")
+)
+
+// checkupTransitions lists the statuses reachable from each status. An
+// in-progress checkup can still be cancelled, which returns whatever was
+// dispensed for it; a completed one only changes through an amendment.
+var checkupTransitions = map[string][]string{
+	models.CheckupStatusScheduled:  {models.CheckupStatusInProgress, models.CheckupStatusCancelled, models.CheckupStatusNoShow},
+	models.CheckupStatusInProgress: {models.CheckupStatusCompleted, models.CheckupStatusCancelled},
+}
+
+func canTransitionCheckup(from, to string) bool {
+	for _, next := range checkupTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type PatientCheckupService interface {
 	CreateCheckup(ctx context.Context, checkup *models.PatientCheckup, patientUpdate *PatientClinicalUpdate) error
 	GetCheckup(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error)
 	ListCheckups(ctx context.Context, page, perPage int, filter repository.PatientCheckupFilter) ([]models.PatientCheckup, int64, error)
-	UpdateCheckup(ctx context.Context, id generated.IdParam, checkup *models.PatientCheckup, patientUpdate *PatientClinicalUpdate) error
+	UpdateCheckup(ctx context.Context, id generated.IdParam, checkup *models.PatientCheckup, patientUpdate *PatientClinicalUpdate, reason *string) error
+	AmendCheckup(ctx context.Context, id generated.IdParam, checkup *models.PatientCheckup, patientUpdate *PatientClinicalUpdate, reason string) error
+	TransitionCheckup(ctx context.Context, id generated.IdParam, toStatus string, reason *string) (*models.PatientCheckup, error)
+	DispenseCheckup(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error)
+	ListStatusHistory(ctx context.Context, id generated.IdParam) ([]models.PatientCheckupTransition, error)
+	ListRevisions(ctx context.Context, id generated.IdParam) ([]models.PatientCheckupRevision, error)
+	GetRevision(ctx context.Context, id generated.IdParam, revision int) (*models.PatientCheckupRevision, error)
 	DeleteCheckup(ctx context.Context, id generated.IdParam) error
 }
 
@@ -47,26 +82,60 @@ func NewPatientCheckupService(
 }
 
 func (s *patientCheckupService) CreateCheckup(ctx context.Context, checkup *models.PatientCheckup, patientUpdate *PatientClinicalUpdate) error {
+	if err := prepareCheckupInput(checkup); err != nil {
+		return err
+	}
+
 	if checkup.Status == "" {
-		checkup.Status = "scheduled"
+		checkup.Status = models.CheckupStatusScheduled
+	}
+	// Every checkup enters the workflow as scheduled; later statuses need a transition.
+	if checkup.Status != models.CheckupStatusScheduled {
+		return ErrInvalidCheckupTransition
+	}
+
+	now := time.Now()
+	checkup.StatusChangedAt = &now
+	checkup.StatusChangedByUserID = GetActorUserID(ctx)
+
+	// Prescribing never touches stock; medicines leave inventory on dispense.
+	for i := range checkup.Medicines {
+		checkup.Medicines[i].DispensedQuantity = 0
 	}
 
 	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(checkup).Error; err != nil {
+		if err := assignCheckupDoctor(tx, checkup, checkup.StatusChangedByUserID); err != nil {
 			return err
 		}
 
-		if len(checkup.Medicines) > 0 {
-			if err := s.adjustMedicineStockByPrescriptionDelta(ctx, tx, checkup.ID.String(), nil, checkup.Medicines); err != nil {
+		diagnoses := checkup.Diagnoses
+		if err := tx.Omit("Diagnoses").Create(checkup).Error; err != nil {
+			return err
+		}
+
+		if diagnoses != nil {
+			if err := replaceCheckupDiagnoses(tx, checkup, diagnoses); err != nil {
 				return err
 			}
 		}
 
+		if err := tx.Create(&models.PatientCheckupTransition{
+			PatientCheckupID: checkup.ID.String(),
+			ToStatus:         checkup.Status,
+			ChangedByUserID:  checkup.StatusChangedByUserID,
+		}).Error; err != nil {
+			return err
+		}
+
 		if err := s.applyPatientClinicalUpdate(tx, checkup.PatientID, patientUpdate); err != nil {
 			return err
 		}
 
-		return nil
+		if err := completeFollowUps(tx, checkup); err != nil {
+			return err
+		}
+
+		return recordCheckupRevision(tx, checkup.ID.String(), checkup.StatusChangedByUserID, "Checkup created")
 	}); err != nil {
 		return err
 	}
@@ -113,7 +182,7 @@ func (s *patientCheckupService) ListCheckups(ctx context.Context, page, perPage
 	}
 
 	cacheKey := fmt.Sprintf(
-		"patient_checkups:list:%d:%d:%s:%s:%s:%s:%s:%s",
+		"patient_checkups:list:%d:%d:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s",
 		page,
 		perPage,
 		filter.Search,
@@ -122,6 +191,12 @@ func (s *patientCheckupService) ListCheckups(ctx context.Context, page, perPage
 		visitDate,
 		visitDateFrom,
 		visitDateTo,
+		boolFilterKey(filter.Fever),
+		boolFilterKey(filter.Hypoxia),
+		filter.BloodPressureCategory,
+		boolFilterKey(filter.AbnormalVitals),
+		filter.DiagnosisCode,
+		filter.DoctorID,
 	)
 
 	var result struct {
@@ -154,103 +229,570 @@ func (s *patientCheckupService) UpdateCheckup(
 	id generated.IdParam,
 	checkup *models.PatientCheckup,
 	patientUpdate *PatientClinicalUpdate,
+	reason *string,
 ) error {
-	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var existing models.PatientCheckup
-		if err := tx.First(&existing, id).Error; err != nil {
+	if err := prepareCheckupInput(checkup); err != nil {
+		return err
+	}
+
+	revisionReason := "Checkup updated"
+	if reason != nil && *reason != "" {
+		revisionReason = *reason
+	}
+
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		existing, err := lockCheckup(tx, id)
+		if err != nil {
 			return err
 		}
 
-		// Preserve immutable fields
-		checkup.ID = existing.ID
-		checkup.PatientID = existing.PatientID
-		checkup.CreatedAt = existing.CreatedAt
+		if existing.IsLocked() {
+			return ErrCheckupLocked
+		}
+		if checkup.Status != "" && checkup.Status != existing.Status {
+			return ErrInvalidCheckupTransition
+		}
 
-		// If medicines are omitted in request, keep previous medicines as-is.
-		if checkup.Medicines == nil {
-			checkup.Medicines = existing.Medicines
+		if err := s.applyCheckupUpdate(ctx, tx, existing, checkup, patientUpdate); err != nil {
+			return err
 		}
 
-		if err := s.adjustMedicineStockByPrescriptionDelta(ctx, tx, existing.ID.String(), existing.Medicines, checkup.Medicines); err != nil {
+		return recordCheckupRevision(tx, existing.ID.String(), GetActorUserID(ctx), revisionReason)
+	}); err != nil {
+		return err
+	}
+
+	s.invalidateCheckupCache(ctx, id, checkup.PatientID)
+	return nil
+}
+
+func (s *patientCheckupService) AmendCheckup(
+	ctx context.Context,
+	id generated.IdParam,
+	checkup *models.PatientCheckup,
+	patientUpdate *PatientClinicalUpdate,
+	reason string,
+) error {
+	if err := prepareCheckupInput(checkup); err != nil {
+		return err
+	}
+
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		existing, err := lockCheckup(tx, id)
+		if err != nil {
 			return err
 		}
 
-		if err := s.applyPatientClinicalUpdate(tx, existing.PatientID, patientUpdate); err != nil {
+		if existing.Status != models.CheckupStatusCompleted {
+			return ErrCheckupNotAmendable
+		}
+
+		if err := s.applyCheckupUpdate(ctx, tx, existing, checkup, patientUpdate); err != nil {
 			return err
 		}
 
-		if err := tx.Save(checkup).Error; err != nil {
+		if err := tx.Create(&models.PatientCheckupAmendment{
+			PatientCheckupID: existing.ID.String(),
+			Reason:           reason,
+			AmendedByUserID:  GetActorUserID(ctx),
+		}).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return recordCheckupRevision(tx, existing.ID.String(), GetActorUserID(ctx), reason)
 	}); err != nil {
 		return err
 	}
 
+	s.invalidateCheckupCache(ctx, id, checkup.PatientID)
+	return nil
+}
+
+func (s *patientCheckupService) TransitionCheckup(
+	ctx context.Context,
+	id generated.IdParam,
+	toStatus string,
+	reason *string,
+) (*models.PatientCheckup, error) {
+	var checkup *models.PatientCheckup
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		existing, err := lockCheckup(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !canTransitionCheckup(existing.Status, toStatus) {
+			return ErrInvalidCheckupTransition
+		}
+
+		now := time.Now()
+		actorID := GetActorUserID(ctx)
+		patch := map[string]any{
+			"status":                    toStatus,
+			"status_changed_at":         now,
+			"status_changed_by_user_id": actorID,
+		}
+		switch toStatus {
+		case models.CheckupStatusInProgress:
+			patch["started_at"] = now
+		case models.CheckupStatusCompleted:
+			patch["completed_at"] = now
+		case models.CheckupStatusCancelled, models.CheckupStatusNoShow:
+			// Only an in-progress checkup can have had medicines dispensed,
+			// and of the two only cancelling is allowed from in_progress.
+			if toStatus == models.CheckupStatusCancelled {
+				if err := s.returnDispensedMedicines(ctx, tx, existing); err != nil {
+					return err
+				}
+			}
+			if err := reopenFollowUps(tx, existing.ID.String()); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(existing).Updates(patch).Error; err != nil {
+			return err
+		}
+
+		fromStatus := existing.Status
+		if err := tx.Create(&models.PatientCheckupTransition{
+			PatientCheckupID: existing.ID.String(),
+			FromStatus:       &fromStatus,
+			ToStatus:         toStatus,
+			Reason:           reason,
+			ChangedByUserID:  actorID,
+		}).Error; err != nil {
+			return err
+		}
+
+		revisionReason := fmt.Sprintf("Status changed to %s", toStatus)
+		if reason != nil && *reason != "" {
+			revisionReason = *reason
+		}
+		if err := recordCheckupRevision(tx, existing.ID.String(), actorID, revisionReason); err != nil {
+			return err
+		}
+
+		checkup = existing
+		return tx.Scopes(repository.PreloadCheckupDiagnoses).First(checkup, id).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	s.invalidateCheckupCache(ctx, id, checkup.PatientID)
+	return checkup, nil
+}
+
+// DispenseCheckup takes the outstanding prescribed quantities out of stock.
+// Lines that were already dispensed are left alone, so it is safe to call
+// again after a prescription was extended.
+func (s *patientCheckupService) DispenseCheckup(ctx context.Context, id generated.IdParam) (*models.PatientCheckup, error) {
+	var checkup *models.PatientCheckup
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		existing, err := lockCheckup(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if existing.Status != models.CheckupStatusInProgress && existing.Status != models.CheckupStatusCompleted {
+			return ErrCheckupNotDispensable
+		}
+
+		outstanding := make(map[string]int)
+		for i, m := range existing.Medicines {
+			if qty := m.Quantity - m.DispensedQuantity; qty > 0 {
+				outstanding[m.MedicineID] += qty
+				existing.Medicines[i].DispensedQuantity = m.Quantity
+			}
+		}
+		if len(outstanding) == 0 {
+			return ErrNothingToDispense
+		}
+		if err := checkDispensingNotFrozen(tx); err != nil {
+			return err
+		}
+
+		for _, medicineID := range sortedKeys(outstanding) {
+			if err := s.consumeMedicineFromBatches(ctx, tx, existing.ID.String(), medicineID, outstanding[medicineID]); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		existing.DispensedAt = &now
+		existing.DispensedByUserID = GetActorUserID(ctx)
+		if err := tx.Model(existing).
+			Select("medicines", "dispensed_at", "dispensed_by_user_id").
+			Updates(existing).Error; err != nil {
+			return err
+		}
+
+		if err := recordCheckupRevision(tx, existing.ID.String(), existing.DispensedByUserID, "Medicines dispensed"); err != nil {
+			return err
+		}
+
+		checkup = existing
+		return tx.Scopes(repository.PreloadCheckupDiagnoses).First(checkup, id).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	s.invalidateCheckupCache(ctx, id, checkup.PatientID)
+	return checkup, nil
+}
+
+func (s *patientCheckupService) ListStatusHistory(ctx context.Context, id generated.IdParam) ([]models.PatientCheckupTransition, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var transitions []models.PatientCheckupTransition
+	err := s.db.WithContext(ctx).
+		Where("patient_checkup_id = ?", id).
+		Order("created_at ASC").
+		Find(&transitions).Error
+	return transitions, err
+}
+
+// applyCheckupUpdate overwrites the clinical content of existing with checkup
+// while keeping identity and workflow fields untouched.
+func (s *patientCheckupService) applyCheckupUpdate(
+	ctx context.Context,
+	tx *gorm.DB,
+	existing *models.PatientCheckup,
+	checkup *models.PatientCheckup,
+	patientUpdate *PatientClinicalUpdate,
+) error {
+	// Preserve immutable fields
+	checkup.ID = existing.ID
+	checkup.PatientID = existing.PatientID
+	checkup.CreatedAt = existing.CreatedAt
+	checkup.Status = existing.Status
+	checkup.StartedAt = existing.StartedAt
+	checkup.CompletedAt = existing.CompletedAt
+	checkup.StatusChangedAt = existing.StatusChangedAt
+	checkup.StatusChangedByUserID = existing.StatusChangedByUserID
+
+	// If the doctor is omitted in request, keep the previous one.
+	if checkup.DoctorID == nil {
+		checkup.DoctorID = existing.DoctorID
+	} else if existing.DoctorID == nil || *checkup.DoctorID != *existing.DoctorID {
+		if err := assignCheckupDoctor(tx, checkup, nil); err != nil {
+			return err
+		}
+	}
+
+	// If medicines are omitted in request, keep previous medicines as-is.
+	if checkup.Medicines == nil {
+		checkup.Medicines = existing.Medicines
+	}
+
+	checkup.DispensedAt = existing.DispensedAt
+	checkup.DispensedByUserID = existing.DispensedByUserID
+
+	checkup.FollowUpCompletedAt = existing.FollowUpCompletedAt
+	checkup.FollowUpCheckupID = existing.FollowUpCheckupID
+	// A moved follow-up date needs a new reminder.
+	if sameDate(checkup.FollowUpDate, existing.FollowUpDate) {
+		checkup.FollowUpReminderSentAt = existing.FollowUpReminderSentAt
+	}
+
+	if err := s.reconcileDispensedMedicines(ctx, tx, existing.ID.String(), existing.Medicines, checkup.Medicines); err != nil {
+		return err
+	}
+
+	if err := s.applyPatientClinicalUpdate(tx, existing.PatientID, patientUpdate); err != nil {
+		return err
+	}
+
+	// Coded diagnoses are kept unless the request sent a new list.
+	if checkup.Diagnoses != nil {
+		if err := replaceCheckupDiagnoses(tx, checkup, checkup.Diagnoses); err != nil {
+			return err
+		}
+	}
+
+	return tx.Omit("Diagnoses").Save(checkup).Error
+}
+
+func (s *patientCheckupService) invalidateCheckupCache(ctx context.Context, id generated.IdParam, patientID string) {
 	s.cache.Delete(ctx, fmt.Sprintf("patient_checkup:%s", id))
 	s.cache.DeletePattern(ctx, "patient_checkups:list:*")
-	s.cache.Delete(ctx, fmt.Sprintf("patient:%s", checkup.PatientID))
+	s.cache.Delete(ctx, fmt.Sprintf("patient:%s", patientID))
 	s.cache.DeletePattern(ctx, "patients:list:*")
+	s.invalidateMedicineCache(ctx)
+}
+
+// invalidateMedicineCache drops cached stock levels after medicines were
+// dispensed or returned.
+func (s *patientCheckupService) invalidateMedicineCache(ctx context.Context) {
+	s.cache.DeletePattern(ctx, "medicine:*")
+	s.cache.DeletePattern(ctx, "medicines:list:*")
+}
+
+// prepareCheckupInput validates the vitals of a checkup sent by a client and
+// normalizes its coded diagnoses.
+func prepareCheckupInput(c *models.PatientCheckup) error {
+	if err := checkupVitals(c).validate(); err != nil {
+		return err
+	}
+	if c.Diagnoses != nil {
+		diagnoses, err := normalizeDiagnoses(c.Diagnoses)
+		if err != nil {
+			return err
+		}
+		c.Diagnoses = diagnoses
+	}
+	return nil
+}
+
+// assignCheckupDoctor links checkup to the account of its doctor. A doctor
+// given by the client must be an active doctor; without one the checkup goes
+// to actorID when that user is a doctor. An empty doctor name is filled in
+// from the account.
+func assignCheckupDoctor(tx *gorm.DB, checkup *models.PatientCheckup, actorID *string) error {
+	if checkup.DoctorID != nil {
+		if err := requireDoctor(tx, *checkup.DoctorID, false); err != nil {
+			return err
+		}
+	} else if actorID != nil {
+		err := requireDoctor(tx, *actorID, false)
+		if err != nil && !errors.Is(err, ErrNotADoctor) {
+			return err
+		}
+		if err == nil {
+			checkup.DoctorID = actorID
+		}
+	}
+
+	if checkup.DoctorID == nil || (checkup.DoctorName != nil && *checkup.DoctorName != "") {
+		return nil
+	}
+	var name string
+	if err := tx.Model(&models.User{}).
+		Where("id = ?", *checkup.DoctorID).
+		Select("name").
+		Scan(&name).Error; err != nil {
+		return err
+	}
+	checkup.DoctorName = ptrString(name)
 	return nil
 }
 
+func lockCheckup(tx *gorm.DB, id generated.IdParam) (*models.PatientCheckup, error) {
+	var checkup models.PatientCheckup
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&checkup, id).Error; err != nil {
+		return nil, err
+	}
+	return &checkup, nil
+}
+
+// startCheckup creates checkup directly in progress for a patient who is
+// being seen now. It goes through scheduled and in_progress so its status
+// history reads the same as a checkup started from the checkup endpoints;
+// reason is recorded on the in_progress transition and the first revision.
+func startCheckup(tx *gorm.DB, checkup *models.PatientCheckup, actorID *string, reason string) error {
+	now := time.Now()
+	checkup.VisitDate = now
+	checkup.Status = models.CheckupStatusInProgress
+	checkup.StartedAt = &now
+	checkup.StatusChangedAt = &now
+	checkup.StatusChangedByUserID = actorID
+	if checkup.Symptoms == nil {
+		checkup.Symptoms = []string{}
+	}
+	if checkup.DoctorID == nil {
+		if err := assignCheckupDoctor(tx, checkup, actorID); err != nil {
+			return err
+		}
+	}
+	if err := tx.Create(checkup).Error; err != nil {
+		return err
+	}
+
+	scheduled := models.CheckupStatusScheduled
+	if err := tx.Create(&[]models.PatientCheckupTransition{
+		{
+			PatientCheckupID: checkup.ID.String(),
+			ToStatus:         scheduled,
+			ChangedByUserID:  actorID,
+		},
+		{
+			PatientCheckupID: checkup.ID.String(),
+			FromStatus:       &scheduled,
+			ToStatus:         models.CheckupStatusInProgress,
+			Reason:           &reason,
+			ChangedByUserID:  actorID,
+		},
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := completeFollowUps(tx, checkup); err != nil {
+		return err
+	}
+
+	return recordCheckupRevision(tx, checkup.ID.String(), actorID, reason)
+}
+
+// DeleteCheckup removes a checkup entered by mistake. Its status history and
+// revisions cannot be erased, so only a checkup with nothing on record beyond
+// its creation can go; any other is cancelled instead.
 func (s *patientCheckupService) DeleteCheckup(ctx context.Context, id generated.IdParam) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
+	checkup, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		// Checked on the locked row so a concurrent transition cannot slip in.
+		locked, err := lockCheckup(tx, id)
+		if err != nil {
+			return err
+		}
+		if locked.Status == models.CheckupStatusCompleted {
+			return ErrCheckupLocked
+		}
+		hasHistory, err := checkupHasHistoryTx(tx, locked.ID.String())
+		if err != nil {
+			return err
+		}
+		if hasHistory {
+			return ErrCheckupHasHistory
+		}
+		if err := s.returnDispensedMedicines(ctx, tx, locked); err != nil {
+			return err
+		}
+
+		if err := tx.Where("patient_checkup_id = ?", checkup.ID).Delete(&models.PatientCheckupTransition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("patient_checkup_id = ?", checkup.ID).Delete(&models.PatientCheckupAmendment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("patient_checkup_id = ?", checkup.ID).Delete(&models.PatientCheckupRevision{}).Error; err != nil {
+			return err
+		}
+		if err := reopenFollowUps(tx, checkup.ID.String()); err != nil {
+			return err
+		}
+		if err := tx.Where("patient_checkup_id = ?", checkup.ID).Delete(&models.PatientCheckupMedicineAllocation{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []any{&models.Appointment{}, &models.QueueEntry{}} {
+			if err := tx.Model(model).
+				Where("patient_checkup_id = ?", checkup.ID).
+				Update("patient_checkup_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(checkup).Error
+	}); err != nil {
 		return err
 	}
 
 	s.cache.Delete(ctx, fmt.Sprintf("patient_checkup:%s", id))
 	s.cache.DeletePattern(ctx, "patient_checkups:list:*")
+	s.invalidateMedicineCache(ctx)
 	return nil
 }
 
-func (s *patientCheckupService) adjustMedicineStockByPrescriptionDelta(
+// checkupHasHistoryTx tells whether a checkup changed since it was created:
+// a status change after the first, a revision after the first, or an
+// amendment.
+func checkupHasHistoryTx(tx *gorm.DB, checkupID string) (bool, error) {
+	var counts struct {
+		Transitions int64
+		Revisions   int64
+		Amendments  int64
+	}
+	err := tx.Raw(`SELECT
+		(SELECT COUNT(*) FROM patient_checkup_transitions WHERE patient_checkup_id = ?) AS transitions,
+		(SELECT COUNT(*) FROM patient_checkup_revisions WHERE patient_checkup_id = ?) AS revisions,
+		(SELECT COUNT(*) FROM patient_checkup_amendments WHERE patient_checkup_id = ?) AS amendments`,
+		checkupID, checkupID, checkupID).
+		Scan(&counts).Error
+	if err != nil {
+		return false, err
+	}
+	return counts.Transitions > 1 || counts.Revisions > 1 || counts.Amendments > 0, nil
+}
+
+// reconcileDispensedMedicines carries the dispensed quantities of the old
+// prescription over to the new one. Whatever no longer fits because a line was
+// reduced or removed goes back to stock; increases stay outstanding until the
+// next dispense.
+func (s *patientCheckupService) reconcileDispensedMedicines(
 	ctx context.Context,
 	tx *gorm.DB,
 	patientCheckupID string,
 	oldMeds, newMeds []models.PatientCheckupMedicine,
 ) error {
-	oldMap := make(map[string]int)
-	newMap := make(map[string]int)
+	remaining := dispensedByMedicine(oldMeds)
+	for i := range newMeds {
+		take := min(remaining[newMeds[i].MedicineID], newMeds[i].Quantity)
+		newMeds[i].DispensedQuantity = take
+		remaining[newMeds[i].MedicineID] -= take
+	}
 
-	for _, m := range oldMeds {
-		if m.Quantity > 0 {
-			oldMap[m.MedicineID] += m.Quantity
+	note := fmt.Sprintf("Prescription reduced on patient checkup %s", patientCheckupID)
+	for _, medicineID := range sortedKeys(remaining) {
+		if remaining[medicineID] == 0 {
+			continue
 		}
-	}
-	for _, m := range newMeds {
-		if m.Quantity > 0 {
-			newMap[m.MedicineID] += m.Quantity
+		if err := s.returnMedicineToBatches(ctx, tx, patientCheckupID, medicineID, remaining[medicineID], note); err != nil {
+			return err
 		}
 	}
 
-	medicineIDs := make(map[string]struct{})
-	for id := range oldMap {
-		medicineIDs[id] = struct{}{}
-	}
-	for id := range newMap {
-		medicineIDs[id] = struct{}{}
+	return nil
+}
+
+// returnDispensedMedicines puts everything dispensed for the checkup back into
+// stock and clears the dispensed quantities on its prescription lines.
+func (s *patientCheckupService) returnDispensedMedicines(ctx context.Context, tx *gorm.DB, checkup *models.PatientCheckup) error {
+	dispensed := dispensedByMedicine(checkup.Medicines)
+	if len(dispensed) == 0 {
+		return nil
 	}
 
-	for medicineID := range medicineIDs {
-		delta := newMap[medicineID] - oldMap[medicineID]
-		if delta == 0 {
-			continue
+	note := fmt.Sprintf("Returned from %s patient checkup %s", checkup.Status, checkup.ID)
+	for _, medicineID := range sortedKeys(dispensed) {
+		if err := s.returnMedicineToBatches(ctx, tx, checkup.ID.String(), medicineID, dispensed[medicineID], note); err != nil {
+			return err
 		}
+	}
 
-		if delta > 0 {
-			if err := s.consumeMedicineFromBatches(ctx, tx, patientCheckupID, medicineID, delta); err != nil {
-				return err
-			}
-		} else {
-			if err := s.returnMedicineToBatch(ctx, tx, patientCheckupID, medicineID, -delta); err != nil {
-				return err
-			}
+	for i := range checkup.Medicines {
+		checkup.Medicines[i].DispensedQuantity = 0
+	}
+	checkup.DispensedAt = nil
+	checkup.DispensedByUserID = nil
+	if err := tx.Model(checkup).
+		Select("medicines", "dispensed_at", "dispensed_by_user_id").
+		Updates(checkup).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func dispensedByMedicine(medicines []models.PatientCheckupMedicine) map[string]int {
+	dispensed := make(map[string]int)
+	for _, m := range medicines {
+		if m.DispensedQuantity > 0 {
+			dispensed[m.MedicineID] += m.DispensedQuantity
 		}
 	}
+	return dispensed
+}
 
-	return nil
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (s *patientCheckupService) consumeMedicineFromBatches(
@@ -259,13 +801,17 @@ func (s *patientCheckupService) consumeMedicineFromBatches(
 	patientCheckupID, medicineID string,
 	qty int,
 ) error {
+	if err := lockMedicineStock(tx, medicineID); err != nil {
+		return err
+	}
+
 	remaining := qty
 	today := time.Now().UTC().Format("2006-01-02")
 
 	var batches []models.MedicineBatch
-	if err := tx.
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("medicine_id = ? AND expiration_date >= ? AND quantity > 0", medicineID, today).
-		Order("expiration_date ASC").
+		Order("expiration_date ASC, id ASC").
 		Find(&batches).Error; err != nil {
 		return err
 	}
@@ -280,22 +826,34 @@ func (s *patientCheckupService) consumeMedicineFromBatches(
 			continue
 		}
 
-		batches[i].Quantity -= take
-		remaining -= take
-
-		if batches[i].ExpirationDate.Before(time.Now().UTC()) {
-			batches[i].Status = "expired"
-		} else if batches[i].Quantity == 0 {
-			batches[i].Status = "depleted"
-		} else {
-			batches[i].Status = "active"
+		// The decrement is conditional so a batch can never go below zero, even
+		// if a writer slipped past the medicine lock.
+		result := tx.Model(&models.MedicineBatch{}).
+			Where("id = ? AND quantity >= ?", batches[i].ID, take).
+			Updates(map[string]any{
+				"quantity": gorm.Expr("quantity - ?", take),
+				"status":   gorm.Expr("CASE WHEN quantity - ? = 0 THEN 'depleted' ELSE 'active' END", take),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStockConflict
 		}
+		remaining -= take
 
-		if err := tx.Save(&batches[i]).Error; err != nil {
+		if err := tx.Create(&models.PatientCheckupMedicineAllocation{
+			PatientCheckupID: patientCheckupID,
+			MedicineID:       medicineID,
+			MedicineBatchID:  batches[i].ID.String(),
+			Quantity:         take,
+			UnitCost:         batches[i].UnitCost,
+			SellingPrice:     batches[i].SellingPrice,
+		}).Error; err != nil {
 			return err
 		}
 
-		beforeStock, afterStock, err := s.recalculateMedicineStock(tx, medicineID)
+		change, err := recalculateMedicineStockTx(tx, medicineID)
 		if err != nil {
 			return err
 		}
@@ -307,8 +865,11 @@ func (s *patientCheckupService) consumeMedicineFromBatches(
 			PatientCheckupID: checkupIDPtr,
 			Source:           "patient_checkup",
 			QuantityDelta:    -take,
-			StockBefore:      beforeStock,
-			StockAfter:       afterStock,
+			StockBefore:      change.Before,
+			StockAfter:       change.After,
+			ExpiredDelta:     change.ExpiredDelta,
+			UnitCost:         batches[i].UnitCost,
+			SellingPrice:     batches[i].SellingPrice,
 			Notes:            &note,
 			CreatedByUserID:  GetActorUserID(ctx),
 		}); err != nil {
@@ -323,80 +884,120 @@ func (s *patientCheckupService) consumeMedicineFromBatches(
 	return nil
 }
 
-func (s *patientCheckupService) returnMedicineToBatch(
+// returnMedicineToBatches credits qty units back to the batches they were
+// dispensed from, undoing the most recent allocations first.
+func (s *patientCheckupService) returnMedicineToBatches(
 	ctx context.Context,
 	tx *gorm.DB,
 	patientCheckupID, medicineID string,
 	qty int,
+	note string,
 ) error {
+	if err := lockMedicineStock(tx, medicineID); err != nil {
+		return err
+	}
+
+	var allocations []models.PatientCheckupMedicineAllocation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("patient_checkup_id = ? AND medicine_id = ? AND returned_quantity < quantity", patientCheckupID, medicineID).
+		Order("created_at DESC, id DESC").
+		Find(&allocations).Error; err != nil {
+		return err
+	}
+
+	remaining := qty
+	for i := range allocations {
+		if remaining == 0 {
+			break
+		}
+
+		var batch models.MedicineBatch
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", allocations[i].MedicineBatchID).First(&batch).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The batch was removed since; its units fall through to the fallback below.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		give := min(remaining, allocations[i].Outstanding())
+		allocations[i].ReturnedQuantity += give
+		if err := tx.Model(&allocations[i]).Update("returned_quantity", allocations[i].ReturnedQuantity).Error; err != nil {
+			return err
+		}
+
+		if err := s.creditBatch(ctx, tx, patientCheckupID, &batch, &allocations[i], give, note); err != nil {
+			return err
+		}
+		remaining -= give
+	}
+
+	if remaining == 0 {
+		return nil
+	}
+
+	// Units dispensed before allocations were recorded, or whose batch is gone,
+	// go to the earliest-expiring batch of the medicine.
 	var batch models.MedicineBatch
-	if err := tx.
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("medicine_id = ?", medicineID).
-		Order("expiration_date ASC").
+		Order("expiration_date ASC, id ASC").
 		First(&batch).Error; err != nil {
 		return err
 	}
+	return s.creditBatch(ctx, tx, patientCheckupID, &batch, nil, remaining, note)
+}
 
-	batch.Quantity += qty
-	if batch.ExpirationDate.Before(time.Now().UTC()) {
-		batch.Status = "expired"
-	} else if batch.Status == "depleted" {
-		batch.Status = "active"
-	}
-	if err := tx.Save(&batch).Error; err != nil {
+// creditBatch puts qty units back into a batch. They are priced as the
+// allocation they were dispensed under, or at the batch prices without one.
+func (s *patientCheckupService) creditBatch(
+	ctx context.Context,
+	tx *gorm.DB,
+	patientCheckupID string,
+	batch *models.MedicineBatch,
+	allocation *models.PatientCheckupMedicineAllocation,
+	qty int,
+	note string,
+) error {
+	status := gorm.Expr("CASE WHEN expiration_date < ? THEN 'expired' WHEN status = 'depleted' THEN 'active' ELSE status END", time.Now().UTC().Format("2006-01-02"))
+	if err := tx.Model(&models.MedicineBatch{}).
+		Where("id = ?", batch.ID).
+		Updates(map[string]any{
+			"quantity": gorm.Expr("quantity + ?", qty),
+			"status":   status,
+		}).Error; err != nil {
 		return err
 	}
 
-	beforeStock, afterStock, err := s.recalculateMedicineStock(tx, medicineID)
+	change, err := recalculateMedicineStockTx(tx, batch.MedicineID)
 	if err != nil {
 		return err
 	}
 
+	unitCost, sellingPrice := batch.UnitCost, batch.SellingPrice
+	if allocation != nil {
+		unitCost, sellingPrice = allocation.UnitCost, allocation.SellingPrice
+	}
+
 	checkupIDPtr := ptrString(patientCheckupID)
-	note := fmt.Sprintf("Prescription adjustment from patient checkup %s", patientCheckupID)
 	batchID := batch.ID.String()
 	return s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
-		MedicineID:       medicineID,
+		MedicineID:       batch.MedicineID,
 		MedicineBatchID:  &batchID,
 		PatientCheckupID: checkupIDPtr,
 		Source:           "patient_checkup",
 		QuantityDelta:    qty,
-		StockBefore:      beforeStock,
-		StockAfter:       afterStock,
+		StockBefore:      change.Before,
+		StockAfter:       change.After,
+		ExpiredDelta:     change.ExpiredDelta,
+		UnitCost:         unitCost,
+		SellingPrice:     sellingPrice,
 		Notes:            &note,
 		CreatedByUserID:  GetActorUserID(ctx),
 	})
 }
 
-func (s *patientCheckupService) recalculateMedicineStock(tx *gorm.DB, medicineID string) (int, int, error) {
-	var before int
-	if err := tx.
-		Model(&models.Medicine{}).
-		Where("id = ?", medicineID).
-		Select("current_stock").
-		Scan(&before).Error; err != nil {
-		return 0, 0, err
-	}
-
-	var total int64
-	if err := tx.
-		Model(&models.MedicineBatch{}).
-		Where("medicine_id = ?", medicineID).
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&total).Error; err != nil {
-		return 0, 0, err
-	}
-	after := int(total)
-
-	if err := tx.Model(&models.Medicine{}).
-		Where("id = ?", medicineID).
-		Update("current_stock", after).Error; err != nil {
-		return 0, 0, err
-	}
-
-	return before, after, nil
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -404,6 +1005,13 @@ func min(a, b int) int {
 	return b
 }
 
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
 func ptrString(v string) *string {
 	if v == "" {
 		return nil
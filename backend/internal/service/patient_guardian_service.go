@@ -0,0 +1,181 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type PatientGuardianService interface {
+	ListGuardians(ctx context.Context, patientID generated.IdParam) ([]models.PatientGuardian, error)
+	CreateGuardian(ctx context.Context, patientID generated.IdParam, guardian *models.PatientGuardian) error
+	UpdateGuardian(ctx context.Context, patientID generated.IdParam, id generated.GuardianIdParam, guardian *models.PatientGuardian) error
+	DeleteGuardian(ctx context.Context, patientID generated.IdParam, id generated.GuardianIdParam) error
+}
+
+type patientGuardianService struct {
+	repo        repository.PatientGuardianRepository
+	patientRepo repository.PatientRepository
+	cache       cache.Cache
+	db          *gorm.DB
+}
+
+func NewPatientGuardianService(
+	repo repository.PatientGuardianRepository,
+	patientRepo repository.PatientRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+) PatientGuardianService {
+	return &patientGuardianService{
+		repo:        repo,
+		patientRepo: patientRepo,
+		cache:       cache,
+		db:          db,
+	}
+}
+
+func (s *patientGuardianService) ListGuardians(ctx context.Context, patientID generated.IdParam) ([]models.PatientGuardian, error) {
+	if _, err := s.patientRepo.FindByID(ctx, patientID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAllByPatientID(ctx, patientID.String())
+}
+
+func (s *patientGuardianService) CreateGuardian(ctx context.Context, patientID generated.IdParam, guardian *models.PatientGuardian) error {
+	if _, err := s.patientRepo.FindByID(ctx, patientID); err != nil {
+		return err
+	}
+
+	guardian.PatientID = patientID.String()
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.PatientGuardian{}).
+			Where("patient_id = ?", guardian.PatientID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		// The first guardian of a patient always becomes the primary contact.
+		if count == 0 {
+			guardian.IsPrimary = true
+		}
+
+		if guardian.IsPrimary {
+			if err := clearPrimaryGuardian(tx, guardian.PatientID, ""); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(guardian).Error
+	}); err != nil {
+		return err
+	}
+
+	s.invalidatePatientCache(ctx, guardian.PatientID)
+	return nil
+}
+
+func (s *patientGuardianService) UpdateGuardian(
+	ctx context.Context,
+	patientID generated.IdParam,
+	id generated.GuardianIdParam,
+	guardian *models.PatientGuardian,
+) error {
+	existing, err := s.repo.FindByID(ctx, patientID.String(), id)
+	if err != nil {
+		return err
+	}
+
+	guardian.ID = existing.ID
+	guardian.PatientID = existing.PatientID
+	guardian.CreatedAt = existing.CreatedAt
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if guardian.IsPrimary {
+			if err := clearPrimaryGuardian(tx, guardian.PatientID, guardian.ID.String()); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Save(guardian).Error; err != nil {
+			return err
+		}
+
+		if existing.IsPrimary && !guardian.IsPrimary {
+			return promotePrimaryGuardian(tx, guardian.PatientID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.invalidatePatientCache(ctx, guardian.PatientID)
+	return nil
+}
+
+func (s *patientGuardianService) DeleteGuardian(ctx context.Context, patientID generated.IdParam, id generated.GuardianIdParam) error {
+	existing, err := s.repo.FindByID(ctx, patientID.String(), id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.PatientGuardian{}, "id = ?", existing.ID).Error; err != nil {
+			return err
+		}
+
+		if existing.IsPrimary {
+			return promotePrimaryGuardian(tx, existing.PatientID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.invalidatePatientCache(ctx, existing.PatientID)
+	return nil
+}
+
+func (s *patientGuardianService) invalidatePatientCache(ctx context.Context, patientID string) {
+	s.cache.Delete(ctx, fmt.Sprintf("patient:%s", patientID))
+	s.cache.DeletePattern(ctx, "patients:list:*")
+}
+
+func clearPrimaryGuardian(tx *gorm.DB, patientID, exceptID string) error {
+	query := tx.Model(&models.PatientGuardian{}).Where("patient_id = ? AND is_primary = ?", patientID, true)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+	return query.Update("is_primary", false).Error
+}
+
+// promotePrimaryGuardian makes the oldest guardian primary when the patient
+// has guardians left but none of them is flagged as primary.
+func promotePrimaryGuardian(tx *gorm.DB, patientID string) error {
+	var primaryCount int64
+	if err := tx.Model(&models.PatientGuardian{}).
+		Where("patient_id = ? AND is_primary = ?", patientID, true).
+		Count(&primaryCount).Error; err != nil {
+		return err
+	}
+	if primaryCount > 0 {
+		return nil
+	}
+
+	var next models.PatientGuardian
+	err := tx.Where("patient_id = ?", patientID).Order("created_at ASC").First(&next).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&next).Update("is_primary", true).Error
+}
@@ -19,18 +19,25 @@ type PatientService interface {
 }
 
 type patientService struct {
-	repo  repository.PatientRepository
-	cache cache.Cache
+	repo         repository.PatientRepository
+	guardianRepo repository.PatientGuardianRepository
+	cache        cache.Cache
 }
 
-func NewPatientService(repo repository.PatientRepository, cache cache.Cache) PatientService {
+func NewPatientService(repo repository.PatientRepository, guardianRepo repository.PatientGuardianRepository, cache cache.Cache) PatientService {
 	return &patientService{
-		repo:  repo,
-		cache: cache,
+		repo:         repo,
+		guardianRepo: guardianRepo,
+		cache:        cache,
 	}
 }
 
 func (s *patientService) CreatePatient(ctx context.Context, patient *models.Patient) error {
+	// A legacy emergency contact arrives as a single guardian and becomes the primary one.
+	for i := range patient.Guardians {
+		patient.Guardians[i].IsPrimary = i == 0
+	}
+
 	if err := s.repo.Create(ctx, patient); err != nil {
 		return err
 	}
@@ -99,10 +106,19 @@ func (s *patientService) UpdatePatient(ctx context.Context, id generated.IdParam
 	patient.ID = existing.ID
 	patient.CreatedAt = existing.CreatedAt
 
+	legacyContact := patient.PrimaryGuardian()
+	patient.Guardians = existing.Guardians
+
 	if err := s.repo.Update(ctx, patient); err != nil {
 		return err
 	}
 
+	if legacyContact != nil {
+		if err := s.syncLegacyEmergencyContact(ctx, patient, legacyContact); err != nil {
+			return err
+		}
+	}
+
 	// Invalidate cache
 	s.cache.Delete(ctx, fmt.Sprintf("patient:%s", id))
 	s.cache.DeletePattern(ctx, "patients:list:*")
@@ -121,3 +137,26 @@ func (s *patientService) DeletePatient(ctx context.Context, id generated.IdParam
 
 	return nil
 }
+
+// syncLegacyEmergencyContact keeps the deprecated emergency_contact_* request
+// fields working by writing them to the patient's primary guardian.
+func (s *patientService) syncLegacyEmergencyContact(ctx context.Context, patient *models.Patient, contact *models.PatientGuardian) error {
+	primary := patient.PrimaryGuardian()
+	if primary == nil {
+		contact.PatientID = patient.ID.String()
+		contact.IsPrimary = true
+		if err := s.guardianRepo.Create(ctx, contact); err != nil {
+			return err
+		}
+		patient.Guardians = append(patient.Guardians, *contact)
+		return nil
+	}
+
+	if primary.FullName == contact.FullName && primary.PhoneNumber == contact.PhoneNumber {
+		return nil
+	}
+
+	primary.FullName = contact.FullName
+	primary.PhoneNumber = contact.PhoneNumber
+	return s.guardianRepo.Update(ctx, primary)
+}
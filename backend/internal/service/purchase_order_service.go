@@ -0,0 +1,560 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidPurchaseOrder  = errors.New("invalid purchase order")
+	ErrPurchaseOrderState    = errors.New("purchase order cannot be changed in its current status")
+	ErrInvalidGoodsReceipt   = errors.New("invalid goods receipt")
+	ErrReceiptExceedsOrdered = errors.New("received quantity exceeds the quantity still outstanding")
+	ErrReceiptCostMismatch   = errors.New("received unit cost differs from the cost of the batch already in stock")
+)
+
+type PurchaseOrderLineInput struct {
+	MedicineID generated.IdParam
+	Quantity   int
+	UnitCost   *float64
+}
+
+type PurchaseOrderInput struct {
+	SupplierID   generated.IdParam
+	ExpectedDate *time.Time
+	Notes        *string
+	Lines        []PurchaseOrderLineInput
+}
+
+// GoodsReceiptLineInput is one batch delivered against an order line.
+// UnitCost defaults to the price agreed on the order line.
+type GoodsReceiptLineInput struct {
+	PurchaseOrderLineID generated.IdParam
+	BatchNumber         string
+	ExpirationDate      time.Time
+	Quantity            int
+	UnitCost            *float64
+	SellingPrice        *float64
+}
+
+type GoodsReceiptInput struct {
+	ReceivedAt        *time.Time
+	ReferenceDocument *string
+	Notes             *string
+	Lines             []GoodsReceiptLineInput
+}
+
+type PurchaseOrderService interface {
+	CreatePurchaseOrder(ctx context.Context, input PurchaseOrderInput) (*models.PurchaseOrder, error)
+	GetPurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error)
+	ListPurchaseOrders(ctx context.Context, page, perPage int, filter repository.PurchaseOrderFilter) ([]models.PurchaseOrder, int64, error)
+	UpdatePurchaseOrder(ctx context.Context, id generated.IdParam, input PurchaseOrderInput) (*models.PurchaseOrder, error)
+	SendPurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error)
+	ClosePurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error)
+	CancelPurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error)
+	ReceiveGoods(ctx context.Context, id generated.IdParam, input GoodsReceiptInput) (*models.GoodsReceipt, error)
+	ListReceipts(ctx context.Context, id generated.IdParam) ([]models.GoodsReceipt, error)
+}
+
+type purchaseOrderService struct {
+	repo                 repository.PurchaseOrderRepository
+	cache                cache.Cache
+	db                   *gorm.DB
+	stockActivityService MedicineStockActivityService
+	loc                  *time.Location
+}
+
+func NewPurchaseOrderService(
+	repo repository.PurchaseOrderRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	stockActivityService MedicineStockActivityService,
+	loc *time.Location,
+) PurchaseOrderService {
+	return &purchaseOrderService{
+		repo:                 repo,
+		cache:                cache,
+		db:                   db,
+		stockActivityService: stockActivityService,
+		loc:                  loc,
+	}
+}
+
+func (s *purchaseOrderService) CreatePurchaseOrder(ctx context.Context, input PurchaseOrderInput) (*models.PurchaseOrder, error) {
+	if err := validatePurchaseOrder(input); err != nil {
+		return nil, err
+	}
+
+	var order *models.PurchaseOrder
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkOrderReferences(tx, input); err != nil {
+			return err
+		}
+
+		number, err := nextYearlyNumber(tx, models.PurchaseOrder{}.TableName(), "PO", time.Now().In(s.loc).Year())
+		if err != nil {
+			return err
+		}
+		order = &models.PurchaseOrder{
+			Number:          number,
+			SupplierID:      input.SupplierID.String(),
+			Status:          models.PurchaseOrderStatusDraft,
+			ExpectedDate:    input.ExpectedDate,
+			Notes:           input.Notes,
+			CreatedByUserID: GetActorUserID(ctx),
+		}
+		if err := tx.Omit(clause.Associations).Create(order).Error; err != nil {
+			return err
+		}
+		return createOrderLines(tx, order.ID.String(), input.Lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, order.ID)
+}
+
+func (s *purchaseOrderService) GetPurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *purchaseOrderService) ListPurchaseOrders(ctx context.Context, page, perPage int, filter repository.PurchaseOrderFilter) ([]models.PurchaseOrder, int64, error) {
+	return s.repo.FindAll(ctx, page, perPage, filter)
+}
+
+// UpdatePurchaseOrder replaces the supplier, dates and lines of a draft.
+func (s *purchaseOrderService) UpdatePurchaseOrder(ctx context.Context, id generated.IdParam, input PurchaseOrderInput) (*models.PurchaseOrder, error) {
+	if err := validatePurchaseOrder(input); err != nil {
+		return nil, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.PurchaseOrderStatusDraft {
+			return fmt.Errorf("%w: only draft orders can be edited", ErrPurchaseOrderState)
+		}
+		if err := checkOrderReferences(tx, input); err != nil {
+			return err
+		}
+
+		if err := tx.Model(order).Updates(map[string]any{
+			"supplier_id":   input.SupplierID.String(),
+			"expected_date": input.ExpectedDate,
+			"notes":         input.Notes,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		return createOrderLines(tx, order.ID.String(), input.Lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier; the lead time of
+// its deliveries is counted from here.
+func (s *purchaseOrderService) SendPurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error) {
+	return s.transition(ctx, id, []string{models.PurchaseOrderStatusDraft}, models.PurchaseOrderStatusSent, "sent_at")
+}
+
+// ClosePurchaseOrder closes an order by hand, giving up on whatever is still
+// outstanding.
+func (s *purchaseOrderService) ClosePurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error) {
+	return s.transition(ctx, id, []string{models.PurchaseOrderStatusSent, models.PurchaseOrderStatusPartiallyReceived}, models.PurchaseOrderStatusClosed, "closed_at")
+}
+
+// CancelPurchaseOrder cancels an order nothing was received on yet. Orders
+// with deliveries are closed instead.
+func (s *purchaseOrderService) CancelPurchaseOrder(ctx context.Context, id generated.IdParam) (*models.PurchaseOrder, error) {
+	return s.transition(ctx, id, []string{models.PurchaseOrderStatusDraft, models.PurchaseOrderStatusSent}, models.PurchaseOrderStatusCancelled, "cancelled_at")
+}
+
+func (s *purchaseOrderService) transition(ctx context.Context, id generated.IdParam, from []string, to, stampColumn string) (*models.PurchaseOrder, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range from {
+			allowed = allowed || order.Status == status
+		}
+		if !allowed {
+			return fmt.Errorf("%w: a %s order cannot become %s", ErrPurchaseOrderState, order.Status, to)
+		}
+
+		return tx.Model(order).Updates(map[string]any{
+			"status":    to,
+			stampColumn: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// ReceiveGoods books a delivery against a sent order. Every line adds stock
+// to a batch, created unless the same batch was received before, and posts a
+// purchase stock activity. The order is closed once every line has arrived.
+func (s *purchaseOrderService) ReceiveGoods(ctx context.Context, id generated.IdParam, input GoodsReceiptInput) (*models.GoodsReceipt, error) {
+	for i := range input.Lines {
+		input.Lines[i].BatchNumber = strings.TrimSpace(input.Lines[i].BatchNumber)
+	}
+	if err := validateGoodsReceipt(input); err != nil {
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	if input.ReceivedAt != nil {
+		receivedAt = *input.ReceivedAt
+	}
+
+	var receipt *models.GoodsReceipt
+	var received []models.GoodsReceiptLine
+	err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		received = nil
+		order, err := lockPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.PurchaseOrderStatusSent && order.Status != models.PurchaseOrderStatusPartiallyReceived {
+			return fmt.Errorf("%w: goods can only be received on sent orders", ErrPurchaseOrderState)
+		}
+		if order.SentAt != nil && receivedAt.Before(*order.SentAt) {
+			return fmt.Errorf("%w: received_at must not be before the order was sent", ErrInvalidGoodsReceipt)
+		}
+
+		var lines []models.PurchaseOrderLine
+		if err := tx.Preload("Medicine").Where("purchase_order_id = ?", order.ID).Find(&lines).Error; err != nil {
+			return err
+		}
+		deliveries, err := receiptDeliveries(lines, input.Lines)
+		if err != nil {
+			return err
+		}
+
+		receipt = &models.GoodsReceipt{
+			PurchaseOrderID:   order.ID.String(),
+			ReceivedAt:        receivedAt,
+			ReferenceDocument: input.ReferenceDocument,
+			Notes:             input.Notes,
+			ReceivedByUserID:  GetActorUserID(ctx),
+		}
+		if err := tx.Omit(clause.Associations).Create(receipt).Error; err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			line, err := s.receiveBatchTx(ctx, tx, order, receipt, delivery)
+			if err != nil {
+				return err
+			}
+			received = append(received, *line)
+		}
+
+		return closeIfFullyReceived(tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range received {
+		invalidateBatchCache(ctx, s.cache, line.MedicineID, line.MedicineBatchID)
+	}
+	return s.repo.FindReceiptByID(ctx, receipt.ID.String())
+}
+
+func (s *purchaseOrderService) ListReceipts(ctx context.Context, id generated.IdParam) ([]models.GoodsReceipt, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindReceipts(ctx, id)
+}
+
+func validatePurchaseOrder(input PurchaseOrderInput) error {
+	if len(input.Lines) == 0 {
+		return fmt.Errorf("%w: at least one line is required", ErrInvalidPurchaseOrder)
+	}
+
+	seen := make(map[generated.IdParam]bool, len(input.Lines))
+	for _, line := range input.Lines {
+		if seen[line.MedicineID] {
+			return fmt.Errorf("%w: medicine %s is listed more than once", ErrInvalidPurchaseOrder, line.MedicineID)
+		}
+		seen[line.MedicineID] = true
+		if line.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be positive", ErrInvalidPurchaseOrder)
+		}
+		if line.UnitCost != nil && *line.UnitCost < 0 {
+			return fmt.Errorf("%w: unit_cost must not be negative", ErrInvalidPurchaseOrder)
+		}
+	}
+	return nil
+}
+
+// checkOrderReferences makes sure the order goes to an active supplier and
+// asks for medicines that exist.
+func checkOrderReferences(tx *gorm.DB, input PurchaseOrderInput) error {
+	var supplier models.Supplier
+	if err := tx.Select("id", "is_active").First(&supplier, "id = ?", input.SupplierID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: supplier %s not found", ErrInvalidPurchaseOrder, input.SupplierID)
+		}
+		return err
+	}
+	if !supplier.IsActive {
+		return fmt.Errorf("%w: supplier is inactive", ErrInvalidPurchaseOrder)
+	}
+
+	ids := make([]generated.IdParam, len(input.Lines))
+	for i, line := range input.Lines {
+		ids[i] = line.MedicineID
+	}
+	var found int64
+	if err := tx.Model(&models.Medicine{}).Where("id IN ?", ids).Count(&found).Error; err != nil {
+		return err
+	}
+	if int(found) != len(ids) {
+		return fmt.Errorf("%w: unknown medicine", ErrInvalidPurchaseOrder)
+	}
+	return nil
+}
+
+func createOrderLines(tx *gorm.DB, orderID string, inputs []PurchaseOrderLineInput) error {
+	lines := make([]models.PurchaseOrderLine, len(inputs))
+	for i, input := range inputs {
+		lines[i] = models.PurchaseOrderLine{
+			PurchaseOrderID: orderID,
+			MedicineID:      input.MedicineID.String(),
+			QuantityOrdered: input.Quantity,
+			UnitCost:        input.UnitCost,
+		}
+	}
+	return tx.Omit(clause.Associations).Create(&lines).Error
+}
+
+func lockPurchaseOrder(tx *gorm.DB, id generated.IdParam) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func validateGoodsReceipt(input GoodsReceiptInput) error {
+	if input.ReceivedAt != nil && input.ReceivedAt.After(time.Now()) {
+		return fmt.Errorf("%w: received_at must not be in the future", ErrInvalidGoodsReceipt)
+	}
+	if len(input.Lines) == 0 {
+		return fmt.Errorf("%w: at least one line is required", ErrInvalidGoodsReceipt)
+	}
+
+	today := utcToday()
+	for _, line := range input.Lines {
+		if line.BatchNumber == "" {
+			return fmt.Errorf("%w: batch_number is required", ErrInvalidGoodsReceipt)
+		}
+		if line.ExpirationDate.Before(today) {
+			return fmt.Errorf("%w: batch %s has already expired", ErrInvalidGoodsReceipt, line.BatchNumber)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be positive", ErrInvalidGoodsReceipt)
+		}
+		if line.UnitCost != nil && *line.UnitCost < 0 {
+			return fmt.Errorf("%w: unit_cost must not be negative", ErrInvalidGoodsReceipt)
+		}
+		if line.SellingPrice != nil && *line.SellingPrice < 0 {
+			return fmt.Errorf("%w: selling_price must not be negative", ErrInvalidGoodsReceipt)
+		}
+	}
+	return nil
+}
+
+type receiptDelivery struct {
+	orderLine *models.PurchaseOrderLine
+	input     GoodsReceiptLineInput
+}
+
+// receiptDeliveries matches the receipt lines to the order lines, checks
+// nothing is received beyond what is outstanding and orders them by
+// medicine so concurrent writers take the medicine locks in the same order.
+func receiptDeliveries(orderLines []models.PurchaseOrderLine, inputs []GoodsReceiptLineInput) ([]receiptDelivery, error) {
+	byID := make(map[string]*models.PurchaseOrderLine, len(orderLines))
+	for i := range orderLines {
+		byID[orderLines[i].ID.String()] = &orderLines[i]
+	}
+
+	deliveries := make([]receiptDelivery, len(inputs))
+	receiving := make(map[string]int, len(orderLines))
+	for i, input := range inputs {
+		orderLine, ok := byID[input.PurchaseOrderLineID.String()]
+		if !ok {
+			return nil, fmt.Errorf("%w: line %s is not on this order", ErrInvalidGoodsReceipt, input.PurchaseOrderLineID)
+		}
+		receiving[orderLine.ID.String()] += input.Quantity
+		if receiving[orderLine.ID.String()] > orderLine.Outstanding() {
+			return nil, fmt.Errorf("%w: %s has %d outstanding", ErrReceiptExceedsOrdered, orderLine.Medicine.Name, orderLine.Outstanding())
+		}
+		deliveries[i] = receiptDelivery{orderLine: orderLine, input: input}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].orderLine.MedicineID < deliveries[j].orderLine.MedicineID
+	})
+	return deliveries, nil
+}
+
+// receiveBatchTx adds one delivered batch to stock and records it on the
+// receipt. A batch received before is topped up only when the delivery
+// costs the same; the receipt line keeps the cost of record, so the batch
+// cost is never rewritten.
+func (s *purchaseOrderService) receiveBatchTx(
+	ctx context.Context,
+	tx *gorm.DB,
+	order *models.PurchaseOrder,
+	receipt *models.GoodsReceipt,
+	delivery receiptDelivery,
+) (*models.GoodsReceiptLine, error) {
+	orderLine, input := delivery.orderLine, delivery.input
+	if err := lockMedicineStock(tx, orderLine.MedicineID); err != nil {
+		return nil, err
+	}
+
+	unitCost := input.UnitCost
+	if unitCost == nil {
+		unitCost = orderLine.UnitCost
+	}
+
+	var batch models.MedicineBatch
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("medicine_id = ? AND batch_number = ? AND expiration_date = ?", orderLine.MedicineID, input.BatchNumber, input.ExpirationDate).
+		First(&batch).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		batch = models.MedicineBatch{
+			MedicineID:     orderLine.MedicineID,
+			BatchNumber:    input.BatchNumber,
+			ExpirationDate: input.ExpirationDate,
+			Quantity:       input.Quantity,
+			Unit:           orderLine.Medicine.Unit,
+			UnitCost:       unitCost,
+			SellingPrice:   input.SellingPrice,
+			Status:         batchStatus(input.ExpirationDate, input.Quantity),
+		}
+		if err := tx.Omit(clause.Associations).Create(&batch).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if !sameUnitCost(batch.UnitCost, unitCost) {
+			return nil, fmt.Errorf("%w: batch %s of %s; receive it under a new batch number", ErrReceiptCostMismatch, batch.BatchNumber, orderLine.Medicine.Name)
+		}
+		quantity := batch.Quantity + input.Quantity
+		updates := map[string]any{
+			"quantity": quantity,
+			"status":   batchStatus(batch.ExpirationDate, quantity),
+		}
+		if input.SellingPrice != nil {
+			updates["selling_price"] = *input.SellingPrice
+		}
+		if err := tx.Model(&batch).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	batchID := batch.ID.String()
+	line := &models.GoodsReceiptLine{
+		GoodsReceiptID:      receipt.ID.String(),
+		PurchaseOrderLineID: orderLine.ID.String(),
+		MedicineID:          orderLine.MedicineID,
+		MedicineBatchID:     batchID,
+		Quantity:            input.Quantity,
+		UnitCost:            unitCost,
+	}
+	if err := tx.Omit(clause.Associations).Create(line).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(orderLine).
+		Update("quantity_received", gorm.Expr("quantity_received + ?", input.Quantity)).Error; err != nil {
+		return nil, err
+	}
+	orderLine.QuantityReceived += input.Quantity
+
+	change, err := recalculateMedicineStockTx(tx, orderLine.MedicineID)
+	if err != nil {
+		return nil, err
+	}
+	receiptID := receipt.ID.String()
+	note := fmt.Sprintf("Received under %s", order.Number)
+	if receipt.ReferenceDocument != nil {
+		note += fmt.Sprintf(" (%s)", *receipt.ReferenceDocument)
+	}
+	if err := s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
+		MedicineID:      orderLine.MedicineID,
+		MedicineBatchID: &batchID,
+		GoodsReceiptID:  &receiptID,
+		Source:          "purchase",
+		QuantityDelta:   input.Quantity,
+		StockBefore:     change.Before,
+		StockAfter:      change.After,
+		ExpiredDelta:    change.ExpiredDelta,
+		Notes:           &note,
+		CreatedByUserID: GetActorUserID(ctx),
+	}); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// sameUnitCost reports whether two unit costs are equal, an unknown cost
+// matching only another unknown one.
+func sameUnitCost(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// closeIfFullyReceived moves the order on after a delivery: closed when
+// every line has arrived, partially received otherwise.
+func closeIfFullyReceived(tx *gorm.DB, order *models.PurchaseOrder) error {
+	var outstanding int64
+	if err := tx.Model(&models.PurchaseOrderLine{}).
+		Where("purchase_order_id = ? AND quantity_received < quantity_ordered", order.ID).
+		Count(&outstanding).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]any{"status": models.PurchaseOrderStatusPartiallyReceived}
+	if outstanding == 0 {
+		updates = map[string]any{
+			"status":    models.PurchaseOrderStatusClosed,
+			"closed_at": time.Now(),
+		}
+	}
+	return tx.Model(order).Updates(updates).Error
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/events"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAlreadyQueued        = errors.New("patient is already waiting in today's queue")
+	ErrQueueEntryNotWaiting = errors.New("queue entry is no longer waiting")
+	ErrQueueEmpty           = errors.New("no patients are waiting in today's queue")
+)
+
+// Queue event types sent to display screens.
+const (
+	QueueEventCheckedIn = "checked_in"
+	QueueEventTriaged   = "triaged"
+	QueueEventCalled    = "called"
+	QueueEventSkipped   = "skipped"
+	QueueEventNoShow    = "no_show"
+)
+
+// QueueTriage is what the triage nurse records for a waiting patient.
+type QueueTriage struct {
+	Priority               string
+	TemperatureC           *float64
+	BloodPressureSystolic  *int
+	BloodPressureDiastolic *int
+	HeartRate              *int
+	RespiratoryRate        *int
+	OxygenSaturation       *int
+	HeightCm               *float64
+	WeightKg               *float64
+	Notes                  *string
+}
+
+type QueueService interface {
+	ListQueue(ctx context.Context, date *time.Time, status string) ([]models.QueueEntry, error)
+	CheckIn(ctx context.Context, patientID string, chiefComplaint *string) (*models.QueueEntry, error)
+	TriageQueueEntry(ctx context.Context, id generated.IdParam, input QueueTriage) (*models.QueueEntry, error)
+	CallNext(ctx context.Context) (*models.QueueEntry, error)
+	SkipQueueEntry(ctx context.Context, id generated.IdParam) (*models.QueueEntry, error)
+	MarkQueueNoShow(ctx context.Context, id generated.IdParam) (*models.QueueEntry, error)
+	Subscribe() (<-chan events.Event, func())
+}
+
+type queueService struct {
+	repo     repository.QueueRepository
+	cache    cache.Cache
+	db       *gorm.DB
+	broker   *events.Broker
+	location *time.Location
+}
+
+func NewQueueService(
+	repo repository.QueueRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	broker *events.Broker,
+	location *time.Location,
+) QueueService {
+	return &queueService{
+		repo:     repo,
+		cache:    cache,
+		db:       db,
+		broker:   broker,
+		location: location,
+	}
+}
+
+// ListQueue returns the queue of the given clinic day, today by default,
+// in the order patients will be called.
+func (s *queueService) ListQueue(ctx context.Context, date *time.Time, status string) ([]models.QueueEntry, error) {
+	day := s.today()
+	if date != nil {
+		day = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return s.repo.FindByDate(ctx, day, status)
+}
+
+// CheckIn puts a walk-in patient at the back of today's queue with the next
+// queue number of the day.
+func (s *queueService) CheckIn(ctx context.Context, patientID string, chiefComplaint *string) (*models.QueueEntry, error) {
+	today := s.today()
+	now := time.Now()
+
+	entry := &models.QueueEntry{
+		QueueDate:      today,
+		PatientID:      patientID,
+		Status:         models.QueueStatusWaiting,
+		ChiefComplaint: chiefComplaint,
+		CheckedInAt:    now,
+		QueuedAt:       now,
+	}
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the patient serializes check-ins of the same patient so the
+		// duplicate check below holds.
+		var patientIDs []string
+		if err := tx.Raw(
+			"SELECT id FROM patients WHERE id = ? AND deleted_at IS NULL AND anonymized_at IS NULL FOR UPDATE",
+			patientID,
+		).Scan(&patientIDs).Error; err != nil {
+			return err
+		}
+		if len(patientIDs) == 0 {
+			return fmt.Errorf("patient_id=%s: %w", patientID, gorm.ErrRecordNotFound)
+		}
+
+		var waiting int64
+		if err := tx.Model(&models.QueueEntry{}).
+			Where("queue_date = ? AND patient_id = ? AND status = ?", today.Format("2006-01-02"), patientID, models.QueueStatusWaiting).
+			Count(&waiting).Error; err != nil {
+			return err
+		}
+		if waiting > 0 {
+			return ErrAlreadyQueued
+		}
+
+		if err := tx.Raw(
+			`INSERT INTO queue_counters (queue_date, last_number) VALUES (?, 1)
+			ON CONFLICT (queue_date) DO UPDATE SET last_number = queue_counters.last_number + 1
+			RETURNING last_number`,
+			today.Format("2006-01-02"),
+		).Scan(&entry.QueueNumber).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(entry).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.publish(ctx, QueueEventCheckedIn, generated.IdParam(entry.ID))
+}
+
+// TriageQueueEntry records vitals and a priority for a waiting patient. It may
+// be repeated when the patient's condition changes while waiting.
+func (s *queueService) TriageQueueEntry(ctx context.Context, id generated.IdParam, input QueueTriage) (*models.QueueEntry, error) {
+	vitals := vitalSigns{
+		TemperatureC:           input.TemperatureC,
+		BloodPressureSystolic:  input.BloodPressureSystolic,
+		BloodPressureDiastolic: input.BloodPressureDiastolic,
+		HeartRate:              input.HeartRate,
+		RespiratoryRate:        input.RespiratoryRate,
+		OxygenSaturation:       input.OxygenSaturation,
+		HeightCm:               input.HeightCm,
+		WeightKg:               input.WeightKg,
+	}
+	if err := vitals.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entry, err := lockQueueEntry(tx, id)
+		if err != nil {
+			return err
+		}
+		if entry.Status != models.QueueStatusWaiting {
+			return ErrQueueEntryNotWaiting
+		}
+
+		return tx.Model(entry).Updates(map[string]any{
+			"priority":                 input.Priority,
+			"temperature_c":            input.TemperatureC,
+			"blood_pressure_systolic":  input.BloodPressureSystolic,
+			"blood_pressure_diastolic": input.BloodPressureDiastolic,
+			"heart_rate":               input.HeartRate,
+			"respiratory_rate":         input.RespiratoryRate,
+			"oxygen_saturation":        input.OxygenSaturation,
+			"height_cm":                input.HeightCm,
+			"weight_kg":                input.WeightKg,
+			"triage_notes":             input.Notes,
+			"triaged_at":               time.Now(),
+			"triaged_by_user_id":       GetActorUserID(ctx),
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.publish(ctx, QueueEventTriaged, id)
+}
+
+// CallNext takes the first waiting patient of today's queue and opens an
+// in-progress checkup for them with the calling user as doctor and the triage
+// vitals filled in. Entries locked by a concurrent call are skipped so two
+// doctors calling at once get different patients.
+func (s *queueService) CallNext(ctx context.Context) (*models.QueueEntry, error) {
+	var entryID generated.IdParam
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entry models.QueueEntry
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("queue_date = ? AND status = ?", s.today().Format("2006-01-02"), models.QueueStatusWaiting).
+			Order(repository.QueueOrder).
+			Take(&entry).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQueueEmpty
+		}
+		if err != nil {
+			return err
+		}
+		entryID = generated.IdParam(entry.ID)
+
+		actorID := GetActorUserID(ctx)
+		var doctorName string
+		if actorID != nil {
+			if err := tx.Model(&models.User{}).
+				Where("id = ?", *actorID).
+				Select("name").
+				Scan(&doctorName).Error; err != nil {
+				return err
+			}
+		}
+
+		chiefComplaint := "Walk-in visit"
+		if entry.ChiefComplaint != nil && *entry.ChiefComplaint != "" {
+			chiefComplaint = *entry.ChiefComplaint
+		}
+
+		checkup := &models.PatientCheckup{
+			PatientID:              entry.PatientID,
+			ChiefComplaint:         chiefComplaint,
+			TemperatureC:           entry.TemperatureC,
+			BloodPressureSystolic:  entry.BloodPressureSystolic,
+			BloodPressureDiastolic: entry.BloodPressureDiastolic,
+			HeartRate:              entry.HeartRate,
+			RespiratoryRate:        entry.RespiratoryRate,
+			OxygenSaturation:       entry.OxygenSaturation,
+			HeightCm:               entry.HeightCm,
+			WeightKg:               entry.WeightKg,
+			Notes:                  entry.TriageNotes,
+			DoctorName:             ptrString(doctorName),
+		}
+		reason := fmt.Sprintf("Called from queue number %d", entry.QueueNumber)
+		if err := startCheckup(tx, checkup, actorID, reason); err != nil {
+			return err
+		}
+
+		return tx.Model(&entry).Updates(map[string]any{
+			"status":             models.QueueStatusCalled,
+			"called_at":          checkup.StartedAt,
+			"called_by_user_id":  actorID,
+			"patient_checkup_id": checkup.ID.String(),
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	s.cache.DeletePattern(ctx, "patient_checkups:list:*")
+	return s.publish(ctx, QueueEventCalled, entryID)
+}
+
+// SkipQueueEntry sends a waiting patient who did not answer the call to the
+// back of their priority group.
+func (s *queueService) SkipQueueEntry(ctx context.Context, id generated.IdParam) (*models.QueueEntry, error) {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entry, err := lockQueueEntry(tx, id)
+		if err != nil {
+			return err
+		}
+		if entry.Status != models.QueueStatusWaiting {
+			return ErrQueueEntryNotWaiting
+		}
+
+		return tx.Model(entry).Updates(map[string]any{
+			"queued_at":  time.Now(),
+			"skip_count": gorm.Expr("skip_count + 1"),
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.publish(ctx, QueueEventSkipped, id)
+}
+
+// MarkQueueNoShow takes a patient who left before being called off the queue.
+func (s *queueService) MarkQueueNoShow(ctx context.Context, id generated.IdParam) (*models.QueueEntry, error) {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entry, err := lockQueueEntry(tx, id)
+		if err != nil {
+			return err
+		}
+		if entry.Status != models.QueueStatusWaiting {
+			return ErrQueueEntryNotWaiting
+		}
+
+		return tx.Model(entry).Update("status", models.QueueStatusNoShow).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.publish(ctx, QueueEventNoShow, id)
+}
+
+func (s *queueService) Subscribe() (<-chan events.Event, func()) {
+	return s.broker.Subscribe()
+}
+
+// publish reloads the entry after a committed change and announces it to the
+// queue display streams.
+func (s *queueService) publish(ctx context.Context, eventType string, id generated.IdParam) (*models.QueueEntry, error) {
+	entry, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broker.Publish(events.Event{Type: eventType, Payload: entry})
+	return entry, nil
+}
+
+// today is the current clinic day as stored in queue_date.
+func (s *queueService) today() time.Time {
+	now := time.Now().In(s.location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func lockQueueEntry(tx *gorm.DB, id generated.IdParam) (*models.QueueEntry, error) {
+	var entry models.QueueEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
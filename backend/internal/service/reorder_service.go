@@ -0,0 +1,403 @@
+package service
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// minSeasonDays is how many days of history a season needs before its
+	// factor is trusted; until then it counts as a regular period.
+	minSeasonDays = 14
+
+	maxReorderLeadTimeDays = 365
+)
+
+var (
+	ErrInvalidReorderRequest = errors.New("invalid reorder request")
+	ErrNothingToReorder      = errors.New("no medicine needs reordering")
+)
+
+// ReorderInput asks for reorder suggestions. The lead time is LeadTimeDays
+// when given, otherwise the supplier's average lead time, otherwise the
+// configured default. A supplier also limits the suggestions to the
+// medicines it delivered last, unless MedicineIDs picks them.
+type ReorderInput struct {
+	SupplierID   string
+	LeadTimeDays *int
+	Search       string
+	MedicineIDs  []string
+	IncludeAll   bool // also list medicines with enough stock
+}
+
+// ReorderPurchaseOrderInput turns the suggestions for a supplier into a
+// draft purchase order, optionally only for some of the medicines.
+type ReorderPurchaseOrderInput struct {
+	SupplierID   uuid.UUID
+	LeadTimeDays *int
+	MedicineIDs  []string
+	ExpectedDate *time.Time
+	Notes        *string
+}
+
+type ReorderService interface {
+	Suggestions(ctx context.Context, input ReorderInput) ([]models.ReorderSuggestion, error)
+	CreatePurchaseOrder(ctx context.Context, input ReorderPurchaseOrderInput) (*models.PurchaseOrder, error)
+}
+
+type reorderService struct {
+	repo                 repository.ReorderRepository
+	reportRepo           repository.ReportRepository
+	purchaseOrderService PurchaseOrderService
+	cfg                  config.StockConfig
+	loc                  *time.Location
+}
+
+func NewReorderService(
+	repo repository.ReorderRepository,
+	reportRepo repository.ReportRepository,
+	purchaseOrderService PurchaseOrderService,
+	cfg config.StockConfig,
+	loc *time.Location,
+) ReorderService {
+	return &reorderService{
+		repo:                 repo,
+		reportRepo:           reportRepo,
+		purchaseOrderService: purchaseOrderService,
+		cfg:                  cfg,
+		loc:                  loc,
+	}
+}
+
+// Suggestions forecasts the daily patient checkup consumption of every
+// active medicine and works out its reorder point and how much to order.
+// Medicines that need ordering come first, the ones that run out soonest
+// ahead.
+func (s *reorderService) Suggestions(ctx context.Context, input ReorderInput) ([]models.ReorderSuggestion, error) {
+	now := time.Now().In(s.loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+
+	leadTime, err := s.leadTime(ctx, input.SupplierID, input.LeadTimeDays, today)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ReorderCandidateFilter{
+		Search:      input.Search,
+		MedicineIDs: input.MedicineIDs,
+	}
+	if len(input.MedicineIDs) == 0 {
+		filter.SupplierID = input.SupplierID
+	}
+	candidates, err := s.repo.FindCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []models.ReorderSuggestion{}, nil
+	}
+
+	historyFrom := today.AddDate(0, 0, -s.cfg.ForecastHistoryDays)
+	consumption, err := s.repo.DailyConsumption(ctx, historyFrom, today, s.loc, input.MedicineIDs)
+	if err != nil {
+		return nil, err
+	}
+	byMedicine := make(map[string]map[string]float64)
+	for _, row := range consumption {
+		if byMedicine[row.MedicineID] == nil {
+			byMedicine[row.MedicineID] = make(map[string]float64)
+		}
+		byMedicine[row.MedicineID][row.Day.Format("2006-01-02")] = float64(row.Quantity)
+	}
+
+	seasons := newSeasonCalendar(s.cfg.FluSeasonMonths, s.cfg.ExamSeasonMonths)
+	suggestions := make([]models.ReorderSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		suggestion := s.suggest(candidate, byMedicine[candidate.MedicineID], seasons, today, leadTime)
+		if suggestion.SuggestedQuantity > 0 || input.IncludeAll {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if (a.SuggestedQuantity > 0) != (b.SuggestedQuantity > 0) {
+			return a.SuggestedQuantity > 0
+		}
+		if (a.DaysOfCover == nil) != (b.DaysOfCover == nil) {
+			return a.DaysOfCover != nil
+		}
+		return a.DaysOfCover != nil && *a.DaysOfCover < *b.DaysOfCover
+	})
+	return suggestions, nil
+}
+
+// CreatePurchaseOrder drafts a purchase order for everything the
+// suggestions say to order from the supplier, at the unit cost last paid.
+func (s *reorderService) CreatePurchaseOrder(ctx context.Context, input ReorderPurchaseOrderInput) (*models.PurchaseOrder, error) {
+	suggestions, err := s.Suggestions(ctx, ReorderInput{
+		SupplierID:   input.SupplierID.String(),
+		LeadTimeDays: input.LeadTimeDays,
+		MedicineIDs:  input.MedicineIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []PurchaseOrderLineInput
+	for _, suggestion := range suggestions {
+		if suggestion.SuggestedQuantity <= 0 {
+			continue
+		}
+		medicineID, err := uuid.Parse(suggestion.MedicineID)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, PurchaseOrderLineInput{
+			MedicineID: medicineID,
+			Quantity:   suggestion.SuggestedQuantity,
+			UnitCost:   suggestion.UnitCost,
+		})
+	}
+	if len(lines) == 0 {
+		return nil, ErrNothingToReorder
+	}
+
+	expectedDate := input.ExpectedDate
+	if expectedDate == nil {
+		now := time.Now().In(s.loc)
+		expected := time.Date(now.Year(), now.Month(), now.Day()+suggestions[0].LeadTimeDays, 0, 0, 0, 0, time.UTC)
+		expectedDate = &expected
+	}
+	notes := input.Notes
+	if notes == nil {
+		note := "Drafted from reorder suggestions"
+		notes = &note
+	}
+
+	return s.purchaseOrderService.CreatePurchaseOrder(ctx, PurchaseOrderInput{
+		SupplierID:   input.SupplierID,
+		ExpectedDate: expectedDate,
+		Notes:        notes,
+		Lines:        lines,
+	})
+}
+
+// leadTime picks the lead time in days: the one asked for, the supplier's
+// average over the forecast history, or the configured default.
+func (s *reorderService) leadTime(ctx context.Context, supplierID string, requested *int, today time.Time) (int, error) {
+	if requested != nil {
+		if *requested <= 0 || *requested > maxReorderLeadTimeDays {
+			return 0, fmt.Errorf("%w: lead_time_days must be between 1 and %d", ErrInvalidReorderRequest, maxReorderLeadTimeDays)
+		}
+		return *requested, nil
+	}
+	if supplierID == "" {
+		return s.cfg.ForecastLeadTimeDays, nil
+	}
+
+	rows, err := s.reportRepo.SupplierLeadTimes(ctx, repository.SupplierLeadTimeFilter{
+		From:       today.AddDate(0, 0, -s.cfg.ForecastHistoryDays),
+		To:         today.AddDate(0, 0, 1),
+		Location:   s.loc,
+		SupplierID: supplierID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 || rows[0].ReceiptCount == 0 {
+		return s.cfg.ForecastLeadTimeDays, nil
+	}
+	return max(int(math.Ceil(rows[0].AverageLeadDays)), 1), nil
+}
+
+// suggest forecasts one medicine. Daily demand is split into a level and a
+// seasonal factor per season, learnt from the whole history as the season's
+// average over the regular average. The level and its variability come from
+// the recent window with the seasons taken out, and are projected over the
+// lead time and the review period with the factors of the days ahead.
+func (s *reorderService) suggest(
+	candidate models.ReorderCandidate,
+	daily map[string]float64,
+	seasons seasonCalendar,
+	today time.Time,
+	leadTime int,
+) models.ReorderSuggestion {
+	suggestion := models.ReorderSuggestion{
+		ReorderCandidate: candidate,
+		Basis:            models.ReorderBasisForecast,
+		SeasonalFactor:   1,
+		LeadTimeDays:     leadTime,
+	}
+	position := candidate.CurrentStock + candidate.OnOrder
+
+	if len(daily) == 0 {
+		// Nothing to forecast from: keep the medicine at its minimum stock.
+		suggestion.Basis = models.ReorderBasisMinimumStock
+		suggestion.ReorderPoint = candidate.MinimumStock
+		suggestion.OrderUpTo = candidate.MinimumStock
+		if position < candidate.MinimumStock {
+			suggestion.SuggestedQuantity = candidate.MinimumStock - position
+		}
+		suggestion.EstimatedCost = estimatedCost(suggestion.SuggestedQuantity, candidate.UnitCost)
+		return suggestion
+	}
+
+	history := s.cfg.ForecastHistoryDays
+	factors := seasons.factors(daily, today, history)
+
+	window := s.cfg.ForecastWindowDays
+	deseasonalized := make([]float64, window)
+	for i := range deseasonalized {
+		day := today.AddDate(0, 0, i-window)
+		if factor := factors.of(seasons.label(day)); factor > 0 {
+			deseasonalized[i] = max(daily[day.Format("2006-01-02")], 0) / factor
+		}
+	}
+	level, stdDev := meanAndStdDev(deseasonalized)
+
+	leadFactor := seasons.averageFactor(factors, today, leadTime)
+	cover := leadTime + s.cfg.ForecastReviewDays
+	coverFactor := seasons.averageFactor(factors, today, cover)
+
+	z := s.cfg.ForecastSafetyFactor
+	leadDemand := level * leadFactor * float64(leadTime)
+	safetyStock := z * stdDev * leadFactor * math.Sqrt(float64(leadTime))
+	coverDemand := level * coverFactor * float64(cover)
+	coverSafety := z * stdDev * coverFactor * math.Sqrt(float64(cover))
+
+	suggestion.AverageDailyDemand = roundTo(level, 2)
+	suggestion.DemandStdDev = roundTo(stdDev, 2)
+	suggestion.SeasonalFactor = roundTo(leadFactor, 2)
+	suggestion.LeadTimeDemand = roundTo(leadDemand, 1)
+	suggestion.SafetyStock = int(math.Ceil(safetyStock))
+	suggestion.ReorderPoint = int(math.Ceil(leadDemand + safetyStock))
+	suggestion.OrderUpTo = int(math.Ceil(coverDemand + coverSafety))
+	if dailyDemand := level * leadFactor; dailyDemand > 0 {
+		days := roundTo(float64(position)/dailyDemand, 1)
+		suggestion.DaysOfCover = &days
+	}
+	if position <= suggestion.ReorderPoint && suggestion.OrderUpTo > position {
+		suggestion.SuggestedQuantity = suggestion.OrderUpTo - position
+	}
+	suggestion.EstimatedCost = estimatedCost(suggestion.SuggestedQuantity, candidate.UnitCost)
+	return suggestion
+}
+
+// seasonCalendar tells which seasons a day falls in. A day in both the flu
+// and the exam season belongs to a combined season of its own, so the
+// overlap gets its own factor.
+type seasonCalendar map[time.Month][]string
+
+func newSeasonCalendar(fluMonths, examMonths []int) seasonCalendar {
+	calendar := make(seasonCalendar)
+	for _, month := range fluMonths {
+		calendar[time.Month(month)] = append(calendar[time.Month(month)], "flu")
+	}
+	for _, month := range examMonths {
+		calendar[time.Month(month)] = append(calendar[time.Month(month)], "exam")
+	}
+	return calendar
+}
+
+// label names the season of a day; regular days have an empty label.
+func (c seasonCalendar) label(day time.Time) string {
+	return strings.Join(c[day.Month()], "+")
+}
+
+// factors compares the average daily demand of each season over the
+// history to that of the regular days. Seasons with too little history keep
+// a factor of 1.
+func (c seasonCalendar) factors(daily map[string]float64, today time.Time, history int) seasonFactors {
+	totals := make(map[string]float64)
+	days := make(map[string]int)
+	var overall float64
+	for i := 1; i <= history; i++ {
+		day := today.AddDate(0, 0, -i)
+		quantity := max(daily[day.Format("2006-01-02")], 0)
+		label := c.label(day)
+		totals[label] += quantity
+		days[label]++
+		overall += quantity
+	}
+
+	base := overall / float64(history)
+	if days[""] >= minSeasonDays {
+		base = totals[""] / float64(days[""])
+	}
+
+	factors := seasonFactors{"": 1}
+	for label, n := range days {
+		if label == "" || n < minSeasonDays || base <= 0 {
+			continue
+		}
+		factors[label] = totals[label] / float64(n) / base
+	}
+	return factors
+}
+
+// averageFactor is the mean seasonal factor over the next days days.
+func (c seasonCalendar) averageFactor(factors seasonFactors, today time.Time, days int) float64 {
+	if days <= 0 {
+		return 1
+	}
+	var sum float64
+	for i := 0; i < days; i++ {
+		sum += factors.of(c.label(today.AddDate(0, 0, i)))
+	}
+	return sum / float64(days)
+}
+
+// seasonFactors holds the demand factor of each season label.
+type seasonFactors map[string]float64
+
+// of is the factor of a season, 1 for a season without a trusted factor.
+func (f seasonFactors) of(label string) float64 {
+	if factor, ok := f[label]; ok {
+		return factor
+	}
+	return 1
+}
+
+func meanAndStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
+
+func estimatedCost(quantity int, unitCost *float64) *float64 {
+	if quantity <= 0 || unitCost == nil {
+		return nil
+	}
+	cost := float64(quantity) * *unitCost
+	return &cost
+}
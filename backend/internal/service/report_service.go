@@ -0,0 +1,506 @@
+package service
+
+import (
+	"backend/internal/exports"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	defaultReportLimit = 50
+
+	// stockActivityExportBatchSize is how many activities are read per query
+	// while exporting the activity log.
+	stockActivityExportBatchSize = 1000
+)
+
+var (
+	ErrInvalidReportPeriod  = errors.New("visit_date_from must not be after visit_date_to")
+	ErrInvalidReportRange   = errors.New("date_from must not be after date_to")
+	ErrInvalidUsageReport   = errors.New("period must be total, day, week or month and rank_by quantity or value")
+	ErrInvalidCostReport    = errors.New("period must be total, day, week or month and group_by none, patient_type or checkup")
+	ErrInvalidValuationDate = errors.New("as_of must not be in the future")
+)
+
+// MedicineUsageInput asks for the medicine usage report. Dates are clinic
+// dates; without them the report covers the current month.
+type MedicineUsageInput struct {
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	Period     string
+	BySource   bool
+	RankBy     string
+	Limit      int
+	MedicineID string
+}
+
+// StockActivityLogInput selects the activity log export, by default the
+// current month.
+type StockActivityLogInput struct {
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	MedicineID string
+}
+
+// SupplierLeadTimeInput selects the deliveries of the supplier lead time
+// report by the clinic date they arrived on, by default the current month.
+type SupplierLeadTimeInput struct {
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	SupplierID string
+}
+
+// InventoryValuationInput asks for the inventory valuation at the end of a
+// clinic date, by default today.
+type InventoryValuationInput struct {
+	AsOf       *time.Time
+	ByBatch    bool
+	MedicineID string
+}
+
+// CostOfGoodsDispensedInput asks for the cost of goods dispensed. Dates are
+// clinic dates; without them the report covers the current month.
+type CostOfGoodsDispensedInput struct {
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	Period     string
+	GroupBy    string
+	MedicineID string
+}
+
+type ReportService interface {
+	DiagnosisReport(ctx context.Context, filter repository.DiagnosisReportFilter) ([]models.DiagnosisReportRow, error)
+	MedicineUsageReport(ctx context.Context, input MedicineUsageInput) ([]models.MedicineUsageRow, error)
+	ExportMedicineUsage(ctx context.Context, input MedicineUsageInput, format string, w io.Writer) error
+	ExportStockActivities(ctx context.Context, input StockActivityLogInput, format string, w io.Writer) error
+	OutstandingPurchaseOrders(ctx context.Context, supplierID string, overdueOnly bool) ([]models.OutstandingPurchaseOrderRow, error)
+	SupplierLeadTimes(ctx context.Context, input SupplierLeadTimeInput) ([]models.SupplierLeadTimeRow, error)
+	InventoryValuation(ctx context.Context, input InventoryValuationInput) (*models.InventoryValuation, error)
+	ExportInventoryValuation(ctx context.Context, input InventoryValuationInput, format string, w io.Writer) error
+	CostOfGoodsDispensed(ctx context.Context, input CostOfGoodsDispensedInput) (*models.CostOfGoodsDispensed, error)
+	ExportCostOfGoodsDispensed(ctx context.Context, input CostOfGoodsDispensedInput, format string, w io.Writer) error
+}
+
+type reportService struct {
+	repo repository.ReportRepository
+	loc  *time.Location
+}
+
+func NewReportService(repo repository.ReportRepository, loc *time.Location) ReportService {
+	return &reportService{repo: repo, loc: loc}
+}
+
+func (s *reportService) DiagnosisReport(ctx context.Context, filter repository.DiagnosisReportFilter) ([]models.DiagnosisReportRow, error) {
+	if filter.VisitDateFrom != nil && filter.VisitDateTo != nil && filter.VisitDateFrom.After(*filter.VisitDateTo) {
+		return nil, ErrInvalidReportPeriod
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultReportLimit
+	}
+	return s.repo.DiagnosisReport(ctx, filter)
+}
+
+func (s *reportService) MedicineUsageReport(ctx context.Context, input MedicineUsageInput) ([]models.MedicineUsageRow, error) {
+	from, to, err := s.reportRange(input.DateFrom, input.DateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.MedicineUsageFilter{
+		From:       from,
+		To:         to,
+		Location:   s.loc,
+		Period:     input.Period,
+		BySource:   input.BySource,
+		RankBy:     input.RankBy,
+		Limit:      input.Limit,
+		MedicineID: input.MedicineID,
+	}
+	if filter.Period == "" {
+		filter.Period = repository.UsagePeriodTotal
+	}
+	if filter.RankBy == "" {
+		filter.RankBy = repository.UsageRankByQuantity
+	}
+	switch filter.Period {
+	case repository.UsagePeriodTotal, repository.UsagePeriodDay, repository.UsagePeriodWeek, repository.UsagePeriodMonth:
+	default:
+		return nil, ErrInvalidUsageReport
+	}
+	if filter.RankBy != repository.UsageRankByQuantity && filter.RankBy != repository.UsageRankByValue {
+		return nil, ErrInvalidUsageReport
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultReportLimit
+	}
+
+	return s.repo.MedicineUsage(ctx, filter)
+}
+
+// ExportMedicineUsage writes the medicine usage report as a spreadsheet.
+func (s *reportService) ExportMedicineUsage(ctx context.Context, input MedicineUsageInput, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+	rows, err := s.MedicineUsageReport(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	writer, err := exports.New(format, w, "Medicine usage")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader([]string{
+		"Rank", "Medicine code", "Medicine", "Unit", "Period start", "Source",
+		"Quantity", "Value", "Quantity without unit cost",
+	}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		var periodStart, source any
+		if row.PeriodStart != nil {
+			periodStart = row.PeriodStart.Format("2006-01-02")
+		}
+		if row.Source != nil {
+			source = *row.Source
+		}
+		if err := writer.WriteRow([]any{
+			row.Rank,
+			row.MedicineCode,
+			row.MedicineName,
+			row.Unit,
+			periodStart,
+			source,
+			int(row.Quantity),
+			row.Value,
+			int(row.UncostedQuantity),
+		}); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// ExportStockActivities streams every stock activity of the range as a
+// spreadsheet, oldest first.
+func (s *reportService) ExportStockActivities(ctx context.Context, input StockActivityLogInput, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+	from, to, err := s.reportRange(input.DateFrom, input.DateTo)
+	if err != nil {
+		return err
+	}
+
+	writer, err := exports.New(format, w, "Stock activities")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader([]string{
+		"Date", "Medicine code", "Medicine", "Batch", "Expiration date", "Source", "Reason", "Change",
+		"Quantity", "Stock before", "Stock after", "Expired change", "Unit cost", "Value", "Patient checkup", "User", "Notes",
+	}); err != nil {
+		return err
+	}
+
+	filter := repository.StockActivityLogFilter{From: from, To: to, MedicineID: input.MedicineID}
+	if err := s.repo.StreamStockActivities(ctx, filter, stockActivityExportBatchSize, func(batch []models.StockActivityLogRow) error {
+		for _, row := range batch {
+			var expirationDate, value any
+			if row.ExpirationDate != nil {
+				expirationDate = row.ExpirationDate.Format("2006-01-02")
+			}
+			if row.UnitCost != nil {
+				value = float64(row.QuantityDelta) * *row.UnitCost
+			}
+			if err := writer.WriteRow([]any{
+				row.CreatedAt.In(s.loc).Format("2006-01-02 15:04"),
+				row.MedicineCode,
+				row.MedicineName,
+				exportString(row.BatchNumber),
+				expirationDate,
+				row.Source,
+				exportString(row.ReasonCode),
+				row.ChangeType,
+				row.QuantityDelta,
+				row.StockBefore,
+				row.StockAfter,
+				row.ExpiredDelta,
+				exportNumber(row.UnitCost),
+				value,
+				exportString(row.PatientCheckupID),
+				exportString(row.CreatedByName),
+				exportString(row.Notes),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (s *reportService) OutstandingPurchaseOrders(ctx context.Context, supplierID string, overdueOnly bool) ([]models.OutstandingPurchaseOrderRow, error) {
+	return s.repo.OutstandingPurchaseOrders(ctx, repository.OutstandingPurchaseOrderFilter{
+		Today:       time.Now().In(s.loc),
+		SupplierID:  supplierID,
+		OverdueOnly: overdueOnly,
+	})
+}
+
+func (s *reportService) SupplierLeadTimes(ctx context.Context, input SupplierLeadTimeInput) ([]models.SupplierLeadTimeRow, error) {
+	from, to, err := s.reportRange(input.DateFrom, input.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.SupplierLeadTimes(ctx, repository.SupplierLeadTimeFilter{
+		From:       from,
+		To:         to,
+		Location:   s.loc,
+		SupplierID: input.SupplierID,
+	})
+}
+
+// InventoryValuation values the stock held at the end of the clinic date
+// and totals it over all medicines.
+func (s *reportService) InventoryValuation(ctx context.Context, input InventoryValuationInput) (*models.InventoryValuation, error) {
+	now := time.Now().In(s.loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+	asOf := today
+	if input.AsOf != nil {
+		asOf = time.Date(input.AsOf.Year(), input.AsOf.Month(), input.AsOf.Day(), 0, 0, 0, 0, s.loc)
+	}
+	if asOf.After(today) {
+		return nil, ErrInvalidValuationDate
+	}
+
+	rows, err := s.repo.InventoryValuation(ctx, repository.InventoryValuationFilter{
+		AsOf:       asOf,
+		Until:      asOf.AddDate(0, 0, 1),
+		ByBatch:    input.ByBatch,
+		MedicineID: input.MedicineID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.InventoryValuationSummary{AsOf: asOf}
+	medicines := make(map[string]struct{})
+	for _, row := range rows {
+		medicines[row.MedicineID] = struct{}{}
+		summary.Quantity += row.Quantity
+		summary.Value += row.Value
+		summary.ExpiredQuantity += row.ExpiredQuantity
+		summary.ExpiredValue += row.ExpiredValue
+		summary.UncostedQuantity += row.UncostedQuantity
+	}
+	summary.MedicineCount = len(medicines)
+
+	return &models.InventoryValuation{Summary: summary, Rows: rows}, nil
+}
+
+// ExportInventoryValuation writes the inventory valuation as a spreadsheet,
+// closed by a total row.
+func (s *reportService) ExportInventoryValuation(ctx context.Context, input InventoryValuationInput, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+	valuation, err := s.InventoryValuation(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	writer, err := exports.New(format, w, "Inventory valuation")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader([]string{
+		"As of", "Medicine code", "Medicine", "Unit", "Batch", "Expiration date", "Unit cost",
+		"Quantity", "Value", "Expired quantity", "Expired value", "Quantity without unit cost",
+	}); err != nil {
+		return err
+	}
+	asOf := valuation.Summary.AsOf.Format("2006-01-02")
+	for _, row := range valuation.Rows {
+		var expirationDate any
+		if row.ExpirationDate != nil {
+			expirationDate = row.ExpirationDate.Format("2006-01-02")
+		}
+		if err := writer.WriteRow([]any{
+			asOf,
+			row.MedicineCode,
+			row.MedicineName,
+			row.Unit,
+			exportString(row.BatchNumber),
+			expirationDate,
+			exportNumber(row.UnitCost),
+			int(row.Quantity),
+			row.Value,
+			int(row.ExpiredQuantity),
+			row.ExpiredValue,
+			int(row.UncostedQuantity),
+		}); err != nil {
+			return err
+		}
+	}
+	summary := valuation.Summary
+	if err := writer.WriteRow([]any{
+		asOf, nil, "Total", nil, nil, nil, nil,
+		int(summary.Quantity),
+		summary.Value,
+		int(summary.ExpiredQuantity),
+		summary.ExpiredValue,
+		int(summary.UncostedQuantity),
+	}); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// CostOfGoodsDispensed costs what was dispensed in the range per period,
+// patient type or checkup, and totals it over the whole range.
+func (s *reportService) CostOfGoodsDispensed(ctx context.Context, input CostOfGoodsDispensedInput) (*models.CostOfGoodsDispensed, error) {
+	from, to, err := s.reportRange(input.DateFrom, input.DateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.CostOfGoodsDispensedFilter{
+		From:       from,
+		To:         to,
+		Location:   s.loc,
+		Period:     input.Period,
+		GroupBy:    input.GroupBy,
+		MedicineID: input.MedicineID,
+	}
+	if filter.Period == "" {
+		filter.Period = repository.UsagePeriodTotal
+	}
+	if filter.GroupBy == "" {
+		filter.GroupBy = repository.CostGroupByNone
+	}
+	switch filter.Period {
+	case repository.UsagePeriodTotal, repository.UsagePeriodDay, repository.UsagePeriodWeek, repository.UsagePeriodMonth:
+	default:
+		return nil, ErrInvalidCostReport
+	}
+	switch filter.GroupBy {
+	case repository.CostGroupByNone, repository.CostGroupByPatientType, repository.CostGroupByCheckup:
+	default:
+		return nil, ErrInvalidCostReport
+	}
+
+	rows, err := s.repo.CostOfGoodsDispensed(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// A checkup can show up in several rows, so the total is counted on its
+	// own rather than summed.
+	summary := models.CostOfGoodsDispensedSummary{DateFrom: from, DateTo: to.AddDate(0, 0, -1)}
+	filter.Period = repository.UsagePeriodTotal
+	filter.GroupBy = repository.CostGroupByNone
+	totals, err := s.repo.CostOfGoodsDispensed(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) > 0 {
+		total := totals[0]
+		summary.CheckupCount = total.CheckupCount
+		summary.Quantity = total.Quantity
+		summary.Cost = total.Cost
+		summary.SellingValue = total.SellingValue
+		summary.UncostedQuantity = total.UncostedQuantity
+		summary.UnpricedQuantity = total.UnpricedQuantity
+	}
+
+	return &models.CostOfGoodsDispensed{Summary: summary, Rows: rows}, nil
+}
+
+// ExportCostOfGoodsDispensed writes the cost of goods dispensed as a
+// spreadsheet, closed by a total row.
+func (s *reportService) ExportCostOfGoodsDispensed(ctx context.Context, input CostOfGoodsDispensedInput, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+	report, err := s.CostOfGoodsDispensed(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	writer, err := exports.New(format, w, "Cost of goods dispensed")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader([]string{
+		"Period start", "Patient type", "Patient checkup", "Patient", "Visit date", "Checkups",
+		"Quantity", "Cost when dispensed", "Selling value when dispensed", "Quantity without unit cost", "Quantity without selling price",
+	}); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		var periodStart, visitDate any
+		if row.PeriodStart != nil {
+			periodStart = row.PeriodStart.Format("2006-01-02")
+		}
+		if row.VisitDate != nil {
+			visitDate = row.VisitDate.In(s.loc).Format("2006-01-02")
+		}
+		if err := writer.WriteRow([]any{
+			periodStart,
+			exportString(row.PatientType),
+			exportString(row.PatientCheckupID),
+			exportString(row.PatientName),
+			visitDate,
+			int(row.CheckupCount),
+			int(row.Quantity),
+			row.Cost,
+			row.SellingValue,
+			int(row.UncostedQuantity),
+			int(row.UnpricedQuantity),
+		}); err != nil {
+			return err
+		}
+	}
+	summary := report.Summary
+	if err := writer.WriteRow([]any{
+		"Total", nil, nil, nil, nil,
+		int(summary.CheckupCount),
+		int(summary.Quantity),
+		summary.Cost,
+		summary.SellingValue,
+		int(summary.UncostedQuantity),
+		int(summary.UnpricedQuantity),
+	}); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// reportRange turns clinic dates into the instants bounding them, the end
+// exclusive. A missing end is today and a missing start the first day of the
+// end's month.
+func (s *reportService) reportRange(dateFrom, dateTo *time.Time) (time.Time, time.Time, error) {
+	now := time.Now().In(s.loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+	if dateTo != nil {
+		to = time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day(), 0, 0, 0, 0, s.loc)
+	}
+	from := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, s.loc)
+	if dateFrom != nil {
+		from = time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, s.loc)
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, ErrInvalidReportRange
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RetentionTriggerScheduler = "scheduler"
+	RetentionTriggerCLI       = "cli"
+	RetentionTriggerAPI       = "api"
+)
+
+const anonymizedPatientName = "Anonymized patient"
+
+// anonymizedFields lists what an erasure removes. Gender, patient type, blood
+// type, allergies and the birth year are kept for statistics.
+var anonymizedFields = []string{
+	"full_name",
+	"date_of_birth",
+	"phone_number",
+	"email",
+	"address",
+	"medical_record_number",
+	"guardians",
+	"attachments",
+	"patient_checkups.notes",
+	"patient_checkup_revisions.notes",
+	"appointments.notes",
+	"queue_entries.triage_notes",
+}
+
+type RetentionService interface {
+	DryRun(ctx context.Context) ([]models.RetentionCandidate, error)
+	Run(ctx context.Context, trigger string) ([]models.PatientErasureAudit, error)
+	ListErasures(ctx context.Context, page, perPage int) ([]models.PatientErasureAudit, int64, error)
+}
+
+type retentionService struct {
+	repo    repository.RetentionRepository
+	storage storage.Storage
+	cache   cache.Cache
+	db      *gorm.DB
+	policy  map[string]int
+}
+
+func NewRetentionService(
+	repo repository.RetentionRepository,
+	storage storage.Storage,
+	cache cache.Cache,
+	db *gorm.DB,
+	yearsByPatientType map[string]int,
+) RetentionService {
+	return &retentionService{
+		repo:    repo,
+		storage: storage,
+		cache:   cache,
+		db:      db,
+		policy:  yearsByPatientType,
+	}
+}
+
+// DryRun reports every patient that the next run would anonymize without
+// changing anything.
+func (s *retentionService) DryRun(ctx context.Context) ([]models.RetentionCandidate, error) {
+	patientTypes := make([]string, 0, len(s.policy))
+	for patientType := range s.policy {
+		patientTypes = append(patientTypes, patientType)
+	}
+	sort.Strings(patientTypes)
+
+	now := time.Now()
+	candidates := []models.RetentionCandidate{}
+	for _, patientType := range patientTypes {
+		years := s.policy[patientType]
+		if years <= 0 {
+			continue
+		}
+
+		found, err := s.repo.FindCandidates(ctx, patientType, now.AddDate(-years, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			found[i].RetentionYears = years
+			found[i].EligibleSince = found[i].LastActivityAt.AddDate(years, 0, 0)
+		}
+		candidates = append(candidates, found...)
+	}
+
+	return candidates, nil
+}
+
+// Run anonymizes every eligible patient, each in its own transaction, and
+// returns the audit records written. A failure for one patient does not stop
+// the others; all failures are returned together.
+func (s *retentionService) Run(ctx context.Context, trigger string) ([]models.PatientErasureAudit, error) {
+	candidates, err := s.DryRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	audits := []models.PatientErasureAudit{}
+	var errs []error
+	for _, candidate := range candidates {
+		audit, err := s.anonymize(ctx, candidate, trigger)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("patient %s: %w", candidate.PatientID, err))
+		}
+		if audit != nil {
+			audits = append(audits, *audit)
+		}
+	}
+
+	if len(audits) > 0 {
+		s.cache.DeletePattern(ctx, "patients:list:*")
+		s.cache.DeletePattern(ctx, "patient_checkups:list:*")
+	}
+
+	return audits, errors.Join(errs...)
+}
+
+func (s *retentionService) ListErasures(ctx context.Context, page, perPage int) ([]models.PatientErasureAudit, int64, error) {
+	return s.repo.FindAllAudits(ctx, page, perPage)
+}
+
+// anonymize erases the identifiers of one patient. It returns a nil audit when
+// the patient was already anonymized by a concurrent run.
+func (s *retentionService) anonymize(ctx context.Context, candidate models.RetentionCandidate, trigger string) (*models.PatientErasureAudit, error) {
+	audit := &models.PatientErasureAudit{
+		BaseUUID:          models.BaseUUID{ID: uuid.Must(uuid.NewV7())},
+		PatientID:         candidate.PatientID,
+		PatientType:       candidate.PatientType,
+		RetentionYears:    candidate.RetentionYears,
+		LastActivityAt:    candidate.LastActivityAt,
+		Trigger:           trigger,
+		ClearedFields:     anonymizedFields,
+		PerformedByUserID: GetActorUserID(ctx),
+	}
+
+	var attachments []models.Attachment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Patient{}).
+			Where("id = ? AND anonymized_at IS NULL", candidate.PatientID).
+			Updates(map[string]interface{}{
+				"full_name":             anonymizedPatientName,
+				"date_of_birth":         gorm.Expr("date_trunc('year', date_of_birth)::date"),
+				"phone_number":          "",
+				"email":                 nil,
+				"address":               nil,
+				"medical_record_number": nil,
+				"anonymized_at":         time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			audit = nil
+			return nil
+		}
+
+		result = tx.Where("patient_id = ?", candidate.PatientID).Delete(&models.PatientGuardian{})
+		if result.Error != nil {
+			return result.Error
+		}
+		audit.GuardiansDeleted = int(result.RowsAffected)
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("patient_id = ?", candidate.PatientID).
+			Find(&attachments).Error; err != nil {
+			return err
+		}
+		if len(attachments) > 0 {
+			if err := tx.Where("patient_id = ?", candidate.PatientID).Delete(&models.Attachment{}).Error; err != nil {
+				return err
+			}
+		}
+		audit.AttachmentsDeleted = len(attachments)
+
+		// Free-text notes may name the patient; the clinical fields stay for statistics.
+		result = tx.Model(&models.PatientCheckup{}).
+			Where("patient_id = ?", candidate.PatientID).
+			Update("notes", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		audit.CheckupsDeidentified = int(result.RowsAffected)
+
+		if err := tx.Model(&models.Appointment{}).
+			Where("patient_id = ?", candidate.PatientID).
+			Update("notes", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.QueueEntry{}).
+			Where("patient_id = ?", candidate.PatientID).
+			Update("triage_notes", nil).Error; err != nil {
+			return err
+		}
+
+		// Revisions are never edited otherwise; erasure is the one exception.
+		if err := tx.Exec(`
+			UPDATE patient_checkup_revisions r
+			SET snapshot = jsonb_set(r.snapshot, '{notes}', 'null'::jsonb),
+				changes = COALESCE((
+					SELECT jsonb_agg(ch ORDER BY ord)
+					FROM jsonb_array_elements(r.changes) WITH ORDINALITY AS e(ch, ord)
+					WHERE ch->>'field' <> 'notes'
+				), '[]'::jsonb)
+			FROM patient_checkups c
+			WHERE c.id = r.patient_checkup_id AND c.patient_id = ?
+		`, candidate.PatientID).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(audit).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if audit == nil {
+		return nil, nil
+	}
+
+	s.cache.Delete(ctx, fmt.Sprintf("patient:%s", candidate.PatientID))
+	s.cache.DeletePattern(ctx, "patient_checkup:*")
+
+	// Files are removed after commit so a rollback never leaves rows without content.
+	var errs []error
+	for _, attachment := range attachments {
+		if err := s.storage.Delete(ctx, attachment.StorageKey); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			errs = append(errs, fmt.Errorf("delete attachment %s: %w", attachment.ID, err))
+		}
+	}
+
+	return audit, errors.Join(errs...)
+}
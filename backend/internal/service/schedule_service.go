@@ -0,0 +1,173 @@
+package service
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotADoctor      = errors.New("user is not an active doctor")
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrHolidayExists   = errors.New("a holiday is already recorded for this date")
+)
+
+const (
+	minSlotMinutes = 5
+	maxSlotMinutes = 240
+)
+
+type ScheduleService interface {
+	GetDoctorSchedule(ctx context.Context, doctorID string) ([]models.DoctorSchedule, error)
+	ReplaceDoctorSchedule(ctx context.Context, doctorID string, entries []models.DoctorSchedule) ([]models.DoctorSchedule, error)
+	ListHolidays(ctx context.Context, from, to *time.Time) ([]models.ClinicHoliday, error)
+	CreateHoliday(ctx context.Context, holiday *models.ClinicHoliday) error
+	DeleteHoliday(ctx context.Context, id generated.IdParam) error
+}
+
+type scheduleService struct {
+	repo repository.ScheduleRepository
+	db   *gorm.DB
+}
+
+func NewScheduleService(repo repository.ScheduleRepository, db *gorm.DB) ScheduleService {
+	return &scheduleService{
+		repo: repo,
+		db:   db,
+	}
+}
+
+func (s *scheduleService) GetDoctorSchedule(ctx context.Context, doctorID string) ([]models.DoctorSchedule, error) {
+	if err := requireDoctor(s.db.WithContext(ctx), doctorID, false); err != nil {
+		return nil, err
+	}
+	return s.repo.FindSchedules(ctx, doctorID)
+}
+
+// ReplaceDoctorSchedule swaps the doctor's weekly working hours for entries.
+// Existing appointments are kept even if they now fall outside the hours.
+func (s *scheduleService) ReplaceDoctorSchedule(ctx context.Context, doctorID string, entries []models.DoctorSchedule) ([]models.DoctorSchedule, error) {
+	if err := validateScheduleEntries(entries); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := requireDoctor(tx, doctorID, true); err != nil {
+			return err
+		}
+
+		if err := tx.Where("doctor_id = ?", doctorID).Delete(&models.DoctorSchedule{}).Error; err != nil {
+			return err
+		}
+
+		for i := range entries {
+			entries[i].DoctorID = doctorID
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Create(&entries).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindSchedules(ctx, doctorID)
+}
+
+func (s *scheduleService) ListHolidays(ctx context.Context, from, to *time.Time) ([]models.ClinicHoliday, error) {
+	return s.repo.FindHolidays(ctx, from, to)
+}
+
+func (s *scheduleService) CreateHoliday(ctx context.Context, holiday *models.ClinicHoliday) error {
+	_, err := s.repo.FindHolidayByDate(ctx, holiday.Date)
+	if err == nil {
+		return ErrHolidayExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.repo.CreateHoliday(ctx, holiday)
+}
+
+func (s *scheduleService) DeleteHoliday(ctx context.Context, id generated.IdParam) error {
+	if _, err := s.repo.FindHolidayByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteHoliday(ctx, id)
+}
+
+// lockDoctor checks that doctorID belongs to an active doctor and, when lock
+// is set, holds the user row for the rest of the transaction. Bookings for a
+// doctor are serialized on this lock.
+func requireDoctor(tx *gorm.DB, doctorID string, lock bool) error {
+	query := "SELECT id FROM users WHERE id = ? AND role = 'doctor' AND is_active AND deleted_at IS NULL"
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var ids []string
+	if err := tx.Raw(query, doctorID).Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrNotADoctor
+	}
+	return nil
+}
+
+func validateScheduleEntries(entries []models.DoctorSchedule) error {
+	type block struct{ start, end int }
+	byWeekday := make(map[int][]block)
+
+	for _, e := range entries {
+		if e.Weekday < 0 || e.Weekday > 6 {
+			return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", ErrInvalidSchedule)
+		}
+		start, err := parseClock(e.StartTime)
+		if err != nil {
+			return fmt.Errorf("%w: start_time %q: %v", ErrInvalidSchedule, e.StartTime, err)
+		}
+		end, err := parseClock(e.EndTime)
+		if err != nil {
+			return fmt.Errorf("%w: end_time %q: %v", ErrInvalidSchedule, e.EndTime, err)
+		}
+		if end <= start {
+			return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidSchedule)
+		}
+		if e.SlotMinutes < minSlotMinutes || e.SlotMinutes > maxSlotMinutes {
+			return fmt.Errorf("%w: slot_minutes must be between %d and %d", ErrInvalidSchedule, minSlotMinutes, maxSlotMinutes)
+		}
+		if end-start < e.SlotMinutes {
+			return fmt.Errorf("%w: %s-%s is shorter than one slot", ErrInvalidSchedule, e.StartTime, e.EndTime)
+		}
+		byWeekday[e.Weekday] = append(byWeekday[e.Weekday], block{start, end})
+	}
+
+	for weekday, blocks := range byWeekday {
+		sort.Slice(blocks, func(i, j int) bool { return blocks[i].start < blocks[j].start })
+		for i := 1; i < len(blocks); i++ {
+			if blocks[i].start < blocks[i-1].end {
+				return fmt.Errorf("%w: overlapping hours on %s", ErrInvalidSchedule, time.Weekday(weekday))
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseClock turns "HH:MM" into minutes since midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil || len(value) != len("15:04") {
+		return 0, errors.New("expected HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidStockAdjustment = errors.New("invalid stock adjustment")
+	ErrAdjustmentExceedsStock = errors.New("adjustment would take the batch below zero")
+	ErrAdjustmentNotPending   = errors.New("stock adjustment is no longer pending")
+	ErrSelfApproval           = errors.New("stock adjustments must be approved by someone other than the requester")
+)
+
+// stockAdjustmentReasons lists the reason codes accepted for each adjustment
+// type.
+var stockAdjustmentReasons = map[string][]string{
+	models.StockAdjustmentTypeDamage:     {"broken", "contaminated", "storage_failure", "recalled"},
+	models.StockAdjustmentTypeLoss:       {"missing", "theft", "spillage"},
+	models.StockAdjustmentTypeFound:      {"miscount", "unrecorded_receipt", "unlogged_return"},
+	models.StockAdjustmentTypeDonation:   {"donation_received", "donation_given"},
+	models.StockAdjustmentTypeCorrection: {"data_entry_error", "miscount", "system_error", "stock_take"},
+}
+
+// StockAdjustmentInput requests an adjustment of one batch. QuantityDelta is
+// signed: damage and loss take stock away, found stock adds to it, and
+// donations add or take away depending on their reason code.
+type StockAdjustmentInput struct {
+	MedicineBatchID generated.IdParam
+	Type            string
+	ReasonCode      string
+	Justification   string
+	QuantityDelta   int
+}
+
+type StockAdjustmentService interface {
+	CreateAdjustment(ctx context.Context, input StockAdjustmentInput) (*models.StockAdjustment, error)
+	GetAdjustment(ctx context.Context, id generated.IdParam) (*models.StockAdjustment, error)
+	ListAdjustments(ctx context.Context, page, perPage int, filter repository.StockAdjustmentFilter) ([]models.StockAdjustment, int64, error)
+	ApproveAdjustment(ctx context.Context, id generated.IdParam, notes *string) (*models.StockAdjustment, error)
+	RejectAdjustment(ctx context.Context, id generated.IdParam, notes *string) (*models.StockAdjustment, error)
+	PostAdjustment(ctx context.Context, tx *gorm.DB, adjustment *models.StockAdjustment) error
+}
+
+type stockAdjustmentService struct {
+	repo                 repository.StockAdjustmentRepository
+	batchRepo            repository.MedicineBatchRepository
+	cache                cache.Cache
+	db                   *gorm.DB
+	stockActivityService MedicineStockActivityService
+	approvalThreshold    int
+}
+
+func NewStockAdjustmentService(
+	repo repository.StockAdjustmentRepository,
+	batchRepo repository.MedicineBatchRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	stockActivityService MedicineStockActivityService,
+	approvalThreshold int,
+) StockAdjustmentService {
+	return &stockAdjustmentService{
+		repo:                 repo,
+		batchRepo:            batchRepo,
+		cache:                cache,
+		db:                   db,
+		stockActivityService: stockActivityService,
+		approvalThreshold:    approvalThreshold,
+	}
+}
+
+// CreateAdjustment records an adjustment and applies it right away unless it
+// changes stock by more than the approval threshold, in which case it stays
+// pending.
+func (s *stockAdjustmentService) CreateAdjustment(ctx context.Context, input StockAdjustmentInput) (*models.StockAdjustment, error) {
+	justification := strings.TrimSpace(input.Justification)
+	if err := validateStockAdjustment(input.Type, input.ReasonCode, justification, input.QuantityDelta); err != nil {
+		return nil, err
+	}
+
+	batch, err := s.batchRepo.FindByID(ctx, input.MedicineBatchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Quantity+input.QuantityDelta < 0 {
+		return nil, ErrAdjustmentExceedsStock
+	}
+
+	adjustment := &models.StockAdjustment{
+		MedicineID:        batch.MedicineID,
+		MedicineBatchID:   batch.ID.String(),
+		Type:              input.Type,
+		ReasonCode:        input.ReasonCode,
+		Justification:     justification,
+		QuantityDelta:     input.QuantityDelta,
+		RequestedByUserID: GetActorUserID(ctx),
+	}
+	needsApproval := abs(input.QuantityDelta) > s.approvalThreshold
+
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		adjustment.Status = models.StockAdjustmentStatusPending
+		adjustment.AppliedAt = nil
+		if err := tx.Omit(clause.Associations).Create(adjustment).Error; err != nil {
+			return err
+		}
+		if needsApproval {
+			return nil
+		}
+		return s.applyAdjustmentTx(ctx, tx, adjustment)
+	}); err != nil {
+		return nil, err
+	}
+
+	if !needsApproval {
+		invalidateBatchCache(ctx, s.cache, adjustment.MedicineID, adjustment.MedicineBatchID)
+	}
+	return s.repo.FindByID(ctx, adjustment.ID)
+}
+
+func (s *stockAdjustmentService) GetAdjustment(ctx context.Context, id generated.IdParam) (*models.StockAdjustment, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *stockAdjustmentService) ListAdjustments(
+	ctx context.Context,
+	page, perPage int,
+	filter repository.StockAdjustmentFilter,
+) ([]models.StockAdjustment, int64, error) {
+	return s.repo.FindAll(ctx, page, perPage, filter)
+}
+
+// ApproveAdjustment applies a pending adjustment. The approver must be known
+// and must not be the user who requested it.
+func (s *stockAdjustmentService) ApproveAdjustment(ctx context.Context, id generated.IdParam, notes *string) (*models.StockAdjustment, error) {
+	var adjustment models.StockAdjustment
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		adjustment = models.StockAdjustment{}
+		if err := s.reviewAdjustmentTx(ctx, tx, id, notes, &adjustment); err != nil {
+			return err
+		}
+		// Without both users on record the two-person rule cannot be checked,
+		// so such adjustments are never approved here.
+		reviewer := adjustment.ReviewedByUserID
+		switch {
+		case reviewer == nil:
+			return fmt.Errorf("%w: the approver could not be identified", ErrSelfApproval)
+		case adjustment.RequestedByUserID == nil:
+			return fmt.Errorf("%w: the adjustment has no recorded requester", ErrSelfApproval)
+		case *reviewer == *adjustment.RequestedByUserID:
+			return ErrSelfApproval
+		}
+		return s.applyAdjustmentTx(ctx, tx, &adjustment)
+	}); err != nil {
+		return nil, err
+	}
+
+	invalidateBatchCache(ctx, s.cache, adjustment.MedicineID, adjustment.MedicineBatchID)
+	return s.repo.FindByID(ctx, id)
+}
+
+// RejectAdjustment closes a pending adjustment without touching stock.
+// Requesters may reject their own adjustments to withdraw them.
+func (s *stockAdjustmentService) RejectAdjustment(ctx context.Context, id generated.IdParam, notes *string) (*models.StockAdjustment, error) {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var adjustment models.StockAdjustment
+		if err := s.reviewAdjustmentTx(ctx, tx, id, notes, &adjustment); err != nil {
+			return err
+		}
+		adjustment.Status = models.StockAdjustmentStatusRejected
+		return tx.Omit(clause.Associations).Save(&adjustment).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// PostAdjustment creates and applies, within tx, an adjustment that was
+// already reviewed elsewhere, e.g. as part of a stock take. It skips the
+// approval threshold; the caller drops cached stock levels after commit.
+func (s *stockAdjustmentService) PostAdjustment(ctx context.Context, tx *gorm.DB, adjustment *models.StockAdjustment) error {
+	adjustment.Justification = strings.TrimSpace(adjustment.Justification)
+	if err := validateStockAdjustment(adjustment.Type, adjustment.ReasonCode, adjustment.Justification, adjustment.QuantityDelta); err != nil {
+		return err
+	}
+
+	adjustment.Status = models.StockAdjustmentStatusPending
+	adjustment.AppliedAt = nil
+	if err := tx.Omit(clause.Associations).Create(adjustment).Error; err != nil {
+		return err
+	}
+	return s.applyAdjustmentTx(ctx, tx, adjustment)
+}
+
+// reviewAdjustmentTx locks a pending adjustment and records who reviewed it.
+func (s *stockAdjustmentService) reviewAdjustmentTx(
+	ctx context.Context,
+	tx *gorm.DB,
+	id generated.IdParam,
+	notes *string,
+	adjustment *models.StockAdjustment,
+) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(adjustment, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if adjustment.Status != models.StockAdjustmentStatusPending {
+		return ErrAdjustmentNotPending
+	}
+
+	now := time.Now()
+	adjustment.ReviewedByUserID = GetActorUserID(ctx)
+	adjustment.ReviewedAt = &now
+	adjustment.ReviewNotes = notes
+	return nil
+}
+
+// applyAdjustmentTx changes the batch quantity, logs the stock activity and
+// marks the adjustment applied.
+func (s *stockAdjustmentService) applyAdjustmentTx(ctx context.Context, tx *gorm.DB, adjustment *models.StockAdjustment) error {
+	if err := lockMedicineStock(tx, adjustment.MedicineID); err != nil {
+		return err
+	}
+
+	var batch models.MedicineBatch
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, "id = ?", adjustment.MedicineBatchID).Error; err != nil {
+		return err
+	}
+	quantity := batch.Quantity + adjustment.QuantityDelta
+	if quantity < 0 {
+		return ErrAdjustmentExceedsStock
+	}
+
+	if err := tx.Model(&models.MedicineBatch{}).
+		Where("id = ?", batch.ID).
+		Updates(map[string]any{
+			"quantity": quantity,
+			"status":   batchStatus(batch.ExpirationDate, quantity),
+		}).Error; err != nil {
+		return err
+	}
+
+	change, err := recalculateMedicineStockTx(tx, adjustment.MedicineID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	adjustment.Status = models.StockAdjustmentStatusApplied
+	adjustment.AppliedAt = &now
+	if err := tx.Omit(clause.Associations).Save(adjustment).Error; err != nil {
+		return err
+	}
+
+	adjustmentID := adjustment.ID.String()
+	note := fmt.Sprintf("%s adjustment: %s", adjustment.Type, adjustment.Justification)
+	return s.stockActivityService.LogStockChange(ctx, tx, MedicineStockChangeInput{
+		MedicineID:        adjustment.MedicineID,
+		MedicineBatchID:   &adjustment.MedicineBatchID,
+		StockAdjustmentID: &adjustmentID,
+		ReasonCode:        &adjustment.ReasonCode,
+		Source:            "adjustment",
+		QuantityDelta:     adjustment.QuantityDelta,
+		StockBefore:       change.Before,
+		StockAfter:        change.After,
+		ExpiredDelta:      change.ExpiredDelta,
+		Notes:             &note,
+		CreatedByUserID:   adjustment.RequestedByUserID,
+	})
+}
+
+func validateStockAdjustment(adjustmentType, reasonCode, justification string, delta int) error {
+	reasons, ok := stockAdjustmentReasons[adjustmentType]
+	if !ok {
+		return fmt.Errorf("%w: type must be damage, loss, found, donation or correction", ErrInvalidStockAdjustment)
+	}
+	if !slices.Contains(reasons, reasonCode) {
+		return fmt.Errorf("%w: reason_code for %s must be one of %s", ErrInvalidStockAdjustment, adjustmentType, strings.Join(reasons, ", "))
+	}
+	if justification == "" {
+		return fmt.Errorf("%w: justification is required", ErrInvalidStockAdjustment)
+	}
+	if delta == 0 {
+		return fmt.Errorf("%w: quantity_delta must not be zero", ErrInvalidStockAdjustment)
+	}
+
+	increase := delta > 0
+	switch {
+	case adjustmentType == models.StockAdjustmentTypeDamage,
+		adjustmentType == models.StockAdjustmentTypeLoss,
+		reasonCode == "donation_given":
+		if increase {
+			return fmt.Errorf("%w: %s must decrease stock", ErrInvalidStockAdjustment, reasonCode)
+		}
+	case adjustmentType == models.StockAdjustmentTypeFound,
+		reasonCode == "donation_received":
+		if !increase {
+			return fmt.Errorf("%w: %s must increase stock", ErrInvalidStockAdjustment, reasonCode)
+		}
+	}
+	return nil
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
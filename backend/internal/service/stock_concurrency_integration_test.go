@@ -0,0 +1,398 @@
+//go:build integration
+
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/cache"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// These tests hammer stock from many goroutines at once against a real
+// Postgres and check that stock never goes negative or drifts. They need a
+// disposable database:
+//
+//	TEST_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=mcu_test sslmode=disable" \
+//		go test -tags integration ./internal/service/...
+
+const adjustmentApprovalThreshold = 20
+
+type stockServices struct {
+	db          *gorm.DB
+	checkups    service.PatientCheckupService
+	batches     service.MedicineBatchService
+	adjustments service.StockAdjustmentService
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
+
+func newStockServices(t *testing.T) *stockServices {
+	db := openTestDB(t)
+	noCache := cache.NewNoOpCache()
+	batchRepo := repository.NewMedicineBatchRepository(db)
+	stockActivityService := service.NewMedicineStockActivityService(repository.NewMedicineStockActivityRepository(db), db, time.UTC)
+
+	return &stockServices{
+		db:       db,
+		checkups: service.NewPatientCheckupService(repository.NewPatientCheckupRepository(db), noCache, db, stockActivityService),
+		batches:  service.NewMedicineBatchService(batchRepo, noCache, db, stockActivityService),
+		adjustments: service.NewStockAdjustmentService(
+			repository.NewStockAdjustmentRepository(db), batchRepo, noCache, db, stockActivityService, adjustmentApprovalThreshold,
+		),
+	}
+}
+
+type stockFixtures struct {
+	medicineID   string
+	medicineName string
+	patientID    string
+	batchIDs     []uuid.UUID
+	initialStock int
+}
+
+// createStockFixtures adds a medicine with two batches of perBatch units and
+// a patient to prescribe it to, and removes them when the test ends.
+func createStockFixtures(t *testing.T, s *stockServices, perBatch int) *stockFixtures {
+	t.Helper()
+	ctx := context.Background()
+	suffix := uuid.NewString()[:8]
+
+	medicine := models.Medicine{
+		Name:       "Stock concurrency " + suffix,
+		Code:       "CONC-" + suffix,
+		DosageForm: "tablet",
+		Unit:       "tablet",
+		Status:     "active",
+	}
+	if err := s.db.Create(&medicine).Error; err != nil {
+		t.Fatalf("create medicine: %v", err)
+	}
+	patient := models.Patient{
+		FullName:    "Stock concurrency " + suffix,
+		DateOfBirth: "2000-01-01",
+		Gender:      "other",
+		PatientType: "general",
+		PhoneNumber: "000",
+	}
+	if err := s.db.Create(&patient).Error; err != nil {
+		t.Fatalf("create patient: %v", err)
+	}
+
+	f := &stockFixtures{
+		medicineID:   medicine.ID.String(),
+		medicineName: medicine.Name,
+		patientID:    patient.ID.String(),
+		initialStock: 2 * perBatch,
+	}
+	t.Cleanup(func() { f.cleanup(t, s.db) })
+
+	for i, months := range []int{6, 12} {
+		batch := &models.MedicineBatch{
+			MedicineID:     f.medicineID,
+			BatchNumber:    fmt.Sprintf("CONC-%s-%d", suffix, i+1),
+			ExpirationDate: time.Now().UTC().AddDate(0, months, 0).Truncate(24 * time.Hour),
+			Quantity:       perBatch,
+			Unit:           "tablet",
+		}
+		if err := s.batches.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("create batch: %v", err)
+		}
+		f.batchIDs = append(f.batchIDs, batch.ID)
+	}
+	return f
+}
+
+func (f *stockFixtures) cleanup(t *testing.T, db *gorm.DB) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		checkupIDs := tx.Model(&models.PatientCheckup{}).Select("id").Where("patient_id = ?", f.patientID)
+		for _, model := range []any{
+			&models.PatientCheckupTransition{},
+			&models.PatientCheckupAmendment{},
+			&models.PatientCheckupRevision{},
+			&models.PatientCheckupMedicineAllocation{},
+		} {
+			if err := tx.Where("patient_checkup_id IN (?)", checkupIDs).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("patient_id = ?", f.patientID).Delete(&models.PatientCheckup{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []any{
+			&models.MedicineStockActivity{},
+			&models.StockAdjustment{},
+			&models.MedicineBatch{},
+		} {
+			if err := tx.Where("medicine_id = ?", f.medicineID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&models.Medicine{}, "id = ?", f.medicineID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Patient{}, "id = ?", f.patientID).Error
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Logf("remove fixtures: %v", err)
+	}
+}
+
+func (f *stockFixtures) newCheckup(qty int) *models.PatientCheckup {
+	return &models.PatientCheckup{
+		PatientID:      f.patientID,
+		VisitDate:      time.Now(),
+		ChiefComplaint: "stock concurrency",
+		Symptoms:       []string{},
+		Medicines: []models.PatientCheckupMedicine{{
+			MedicineID:   f.medicineID,
+			MedicineName: f.medicineName,
+			Quantity:     qty,
+			Dosage:       "1x1",
+			Frequency:    "daily",
+			DurationDays: 1,
+		}},
+	}
+}
+
+// assertStockConsistent checks that no batch went negative and that the
+// batches, the medicine total, the stock ledger and the dispense allocations
+// all agree.
+func assertStockConsistent(t *testing.T, db *gorm.DB, f *stockFixtures) {
+	t.Helper()
+
+	var batches []models.MedicineBatch
+	if err := db.Where("medicine_id = ?", f.medicineID).Find(&batches).Error; err != nil {
+		t.Fatalf("load batches: %v", err)
+	}
+	batchTotal := 0
+	for _, b := range batches {
+		if b.Quantity < 0 {
+			t.Errorf("batch %s has negative quantity %d", b.BatchNumber, b.Quantity)
+		}
+		batchTotal += b.Quantity
+	}
+
+	var medicine models.Medicine
+	if err := db.First(&medicine, "id = ?", f.medicineID).Error; err != nil {
+		t.Fatalf("load medicine: %v", err)
+	}
+	// The ledger follows the stock on hand, sellable and expired together.
+	onHand := medicine.CurrentStock + medicine.ExpiredStock
+	if onHand != batchTotal {
+		t.Errorf("stock on hand %d does not match batch total %d", onHand, batchTotal)
+	}
+
+	var activities []models.MedicineStockActivity
+	if err := db.Where("medicine_id = ?", f.medicineID).Order("created_at ASC, id ASC").Find(&activities).Error; err != nil {
+		t.Fatalf("load stock activities: %v", err)
+	}
+	ledger := 0
+	for i, a := range activities {
+		ledger += a.QuantityDelta
+		if a.StockBefore+a.QuantityDelta != a.StockAfter {
+			t.Errorf("activity %s: %d %+d does not give %d", a.ID, a.StockBefore, a.QuantityDelta, a.StockAfter)
+		}
+		if i > 0 && a.StockBefore != activities[i-1].StockAfter {
+			t.Errorf("activity %s starts at %d but the previous one ended at %d", a.ID, a.StockBefore, activities[i-1].StockAfter)
+		}
+	}
+	if ledger != onHand {
+		t.Errorf("stock ledger nets to %d but the stock on hand is %d", ledger, onHand)
+	}
+
+	var adjusted int64
+	if err := db.Model(&models.StockAdjustment{}).
+		Where("medicine_id = ? AND status = ?", f.medicineID, models.StockAdjustmentStatusApplied).
+		Select("COALESCE(SUM(quantity_delta), 0)").
+		Scan(&adjusted).Error; err != nil {
+		t.Fatalf("load adjustments: %v", err)
+	}
+	var outstanding int64
+	if err := db.Model(&models.PatientCheckupMedicineAllocation{}).
+		Where("medicine_id = ?", f.medicineID).
+		Select("COALESCE(SUM(quantity - returned_quantity), 0)").
+		Scan(&outstanding).Error; err != nil {
+		t.Fatalf("load allocations: %v", err)
+	}
+	if left := f.initialStock + int(adjusted) - onHand; int(outstanding) != left {
+		t.Errorf("allocations hold %d units but %d were dispensed", outstanding, left)
+	}
+
+	var checkups []models.PatientCheckup
+	if err := db.Where("patient_id = ?", f.patientID).Find(&checkups).Error; err != nil {
+		t.Fatalf("load checkups: %v", err)
+	}
+	dispensed := 0
+	for _, c := range checkups {
+		for _, m := range c.Medicines {
+			if m.DispensedQuantity > m.Quantity {
+				t.Errorf("checkup %s dispensed %d of %d", c.ID, m.DispensedQuantity, m.Quantity)
+			}
+			dispensed += m.DispensedQuantity
+		}
+	}
+	if dispensed != int(outstanding) {
+		t.Errorf("checkups record %d dispensed units but allocations hold %d", dispensed, outstanding)
+	}
+}
+
+func isInsufficientStock(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "insufficient stock")
+}
+
+// TestConcurrentDispenseAdjustDelete dispenses and cancels checkups, and
+// tries to delete them, while adjustments post against the same batches.
+// Started checkups have a status history, so deleting them is refused.
+func TestConcurrentDispenseAdjustDelete(t *testing.T) {
+	s := newStockServices(t)
+	f := createStockFixtures(t, s, 200)
+	ctx := context.Background()
+
+	const workers, iterations = 12, 20
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w) + 1))
+
+			for i := 0; i < iterations; i++ {
+				if w%3 == 0 {
+					// Every third worker adjusts stock instead of dispensing.
+					input := service.StockAdjustmentInput{
+						MedicineBatchID: f.batchIDs[rng.Intn(len(f.batchIDs))],
+						Type:            models.StockAdjustmentTypeDamage,
+						ReasonCode:      "broken",
+						Justification:   "stock concurrency",
+						QuantityDelta:   -(1 + rng.Intn(3)),
+					}
+					if rng.Intn(2) == 0 {
+						input.Type, input.ReasonCode, input.QuantityDelta = models.StockAdjustmentTypeFound, "miscount", 1+rng.Intn(3)
+					}
+					if _, err := s.adjustments.CreateAdjustment(ctx, input); err != nil && !errors.Is(err, service.ErrAdjustmentExceedsStock) {
+						t.Errorf("worker %d: adjust: %v", w, err)
+					}
+					continue
+				}
+
+				checkup := f.newCheckup(1 + rng.Intn(5))
+				if err := s.checkups.CreateCheckup(ctx, checkup, nil); err != nil {
+					t.Errorf("worker %d: create: %v", w, err)
+					continue
+				}
+				if _, err := s.checkups.TransitionCheckup(ctx, checkup.ID, models.CheckupStatusInProgress, nil); err != nil {
+					t.Errorf("worker %d: start: %v", w, err)
+					continue
+				}
+				if _, err := s.checkups.DispenseCheckup(ctx, checkup.ID); err != nil {
+					if !isInsufficientStock(err) {
+						t.Errorf("worker %d: dispense: %v", w, err)
+					}
+					continue
+				}
+
+				switch rng.Intn(3) {
+				case 0:
+					if err := s.checkups.DeleteCheckup(ctx, checkup.ID); !errors.Is(err, service.ErrCheckupHasHistory) {
+						t.Errorf("worker %d: delete of a started checkup returned %v", w, err)
+					}
+				case 1:
+					if _, err := s.checkups.TransitionCheckup(ctx, checkup.ID, models.CheckupStatusCancelled, nil); err != nil {
+						t.Errorf("worker %d: cancel: %v", w, err)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assertStockConsistent(t, s.db, f)
+}
+
+// TestConcurrentDispenseNeverOversells has more demand than stock and checks
+// that every unit is handed out at most once.
+func TestConcurrentDispenseNeverOversells(t *testing.T) {
+	s := newStockServices(t)
+	f := createStockFixtures(t, s, 15)
+	ctx := context.Background()
+
+	const workers = 24
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		dispensed int
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			qty := 1 + w%3
+			checkup := f.newCheckup(qty)
+			if err := s.checkups.CreateCheckup(ctx, checkup, nil); err != nil {
+				t.Errorf("worker %d: create: %v", w, err)
+				return
+			}
+			if _, err := s.checkups.TransitionCheckup(ctx, checkup.ID, models.CheckupStatusInProgress, nil); err != nil {
+				t.Errorf("worker %d: start: %v", w, err)
+				return
+			}
+			if _, err := s.checkups.DispenseCheckup(ctx, checkup.ID); err != nil {
+				if !isInsufficientStock(err) {
+					t.Errorf("worker %d: dispense: %v", w, err)
+				}
+				return
+			}
+			mu.Lock()
+			dispensed += qty
+			mu.Unlock()
+		}(w)
+	}
+	wg.Wait()
+
+	if dispensed > f.initialStock {
+		t.Errorf("dispensed %d units out of %d", dispensed, f.initialStock)
+	}
+	var medicine models.Medicine
+	if err := s.db.First(&medicine, "id = ?", f.medicineID).Error; err != nil {
+		t.Fatalf("load medicine: %v", err)
+	}
+	if medicine.CurrentStock != f.initialStock-dispensed {
+		t.Errorf("current_stock is %d, want %d", medicine.CurrentStock, f.initialStock-dispensed)
+	}
+	assertStockConsistent(t, s.db, f)
+}
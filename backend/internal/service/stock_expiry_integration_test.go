@@ -0,0 +1,97 @@
+//go:build integration
+
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/cache"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"gorm.io/gorm"
+)
+
+// TestExpiredBatchLeavesStockOnce expires a batch and disposes of it: the
+// units stay on hand while expired and leave the ledger once, on disposal.
+func TestExpiredBatchLeavesStockOnce(t *testing.T) {
+	s := newStockServices(t)
+	f := createStockFixtures(t, s, 30)
+	ctx := context.Background()
+
+	stockActivityService := service.NewMedicineStockActivityService(repository.NewMedicineStockActivityRepository(s.db), s.db, time.UTC)
+	disposals := service.NewDisposalService(repository.NewDisposalRepository(s.db), cache.NewNoOpCache(), s.db, stockActivityService, nil, time.UTC)
+	t.Cleanup(func() { removeDisposals(t, s.db, f) })
+
+	batchID := f.batchIDs[0]
+	batch, err := s.batches.GetBatch(ctx, batchID)
+	if err != nil {
+		t.Fatalf("load batch: %v", err)
+	}
+	expired := &models.MedicineBatch{
+		BatchNumber:    batch.BatchNumber,
+		ExpirationDate: time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour),
+		Unit:           batch.Unit,
+	}
+	if err := s.batches.UpdateBatch(ctx, batchID, expired); err != nil {
+		t.Fatalf("expire batch: %v", err)
+	}
+
+	var medicine models.Medicine
+	if err := s.db.First(&medicine, "id = ?", f.medicineID).Error; err != nil {
+		t.Fatalf("load medicine: %v", err)
+	}
+	if medicine.CurrentStock != 30 || medicine.ExpiredStock != 30 {
+		t.Errorf("after expiry current_stock=%d expired_stock=%d, want 30 and 30", medicine.CurrentStock, medicine.ExpiredStock)
+	}
+	assertStockConsistent(t, s.db, f)
+
+	if _, err := disposals.CreateDisposal(ctx, service.DisposalInput{
+		Method:            models.DisposalMethodIncineration,
+		WitnessName:       "Stock witness",
+		ReferenceDocument: "STOCK-TEST",
+		Items:             []service.DisposalItemInput{{MedicineBatchID: batchID}},
+	}); err != nil {
+		t.Fatalf("dispose batch: %v", err)
+	}
+
+	if err := s.db.First(&medicine, "id = ?", f.medicineID).Error; err != nil {
+		t.Fatalf("load medicine: %v", err)
+	}
+	if medicine.CurrentStock != 30 || medicine.ExpiredStock != 0 {
+		t.Errorf("after disposal current_stock=%d expired_stock=%d, want 30 and 0", medicine.CurrentStock, medicine.ExpiredStock)
+	}
+
+	var disposed models.MedicineStockActivity
+	if err := s.db.Where("medicine_batch_id = ? AND source = ?", batchID.String(), "disposal").First(&disposed).Error; err != nil {
+		t.Fatalf("load disposal activity: %v", err)
+	}
+	if disposed.QuantityDelta != -30 || disposed.ExpiredDelta != -30 || disposed.StockBefore != 60 || disposed.StockAfter != 30 {
+		t.Errorf("disposal activity logged %+d (expired %+d) from %d to %d, want -30 (expired -30) from 60 to 30",
+			disposed.QuantityDelta, disposed.ExpiredDelta, disposed.StockBefore, disposed.StockAfter)
+	}
+	f.initialStock -= 30
+	assertStockConsistent(t, s.db, f)
+}
+
+func removeDisposals(t *testing.T, db *gorm.DB, f *stockFixtures) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var disposalIDs []string
+		if err := tx.Model(&models.DisposalItem{}).Where("medicine_id = ?", f.medicineID).Pluck("disposal_id", &disposalIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("medicine_id = ?", f.medicineID).Delete(&models.DisposalItem{}).Error; err != nil {
+			return err
+		}
+		if len(disposalIDs) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", disposalIDs).Delete(&models.Disposal{}).Error
+	})
+	if err != nil {
+		t.Logf("remove disposals: %v", err)
+	}
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"backend/internal/cache"
+	"backend/internal/documents"
+	"backend/internal/exports"
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidStockTake    = errors.New("invalid stock take")
+	ErrStockTakeInProgress = errors.New("another stock take is still open")
+	ErrStockTakeNotOpen    = errors.New("stock take is no longer open")
+	ErrDispensingFrozen    = errors.New("dispensing is frozen while a stock take is in progress")
+)
+
+type OpenStockTakeInput struct {
+	Name             string
+	Notes            *string
+	FreezeDispensing bool
+}
+
+// StockCountInput records the counted quantity of one batch. Batches that
+// were not holding stock when the stock take was opened can be counted too.
+type StockCountInput struct {
+	MedicineBatchID generated.IdParam
+	CountedQuantity int
+	Notes           *string
+}
+
+type StockTakeService interface {
+	OpenStockTake(ctx context.Context, input OpenStockTakeInput) (*models.StockTake, error)
+	GetStockTake(ctx context.Context, id generated.IdParam) (*models.StockTake, error)
+	ListStockTakes(ctx context.Context, page, perPage int, status string) ([]models.StockTake, int64, error)
+	ListLines(ctx context.Context, id generated.IdParam) ([]models.StockTakeLine, error)
+	RecordCounts(ctx context.Context, id generated.IdParam, counts []StockCountInput) ([]models.StockTakeLine, error)
+	ApproveStockTake(ctx context.Context, id generated.IdParam) (*models.StockTake, error)
+	CancelStockTake(ctx context.Context, id generated.IdParam) (*models.StockTake, error)
+	VarianceReport(ctx context.Context, id generated.IdParam) (*models.StockTakeVariance, []models.StockTakeLine, error)
+	ExportVariance(ctx context.Context, id generated.IdParam, format string, w io.Writer) error
+	CountSheet(ctx context.Context, id generated.IdParam) (*models.StockTake, []byte, error)
+}
+
+type stockTakeService struct {
+	repo              repository.StockTakeRepository
+	cache             cache.Cache
+	db                *gorm.DB
+	adjustmentService StockAdjustmentService
+	renderer          *documents.Renderer
+	loc               *time.Location
+}
+
+func NewStockTakeService(
+	repo repository.StockTakeRepository,
+	cache cache.Cache,
+	db *gorm.DB,
+	adjustmentService StockAdjustmentService,
+	renderer *documents.Renderer,
+	loc *time.Location,
+) StockTakeService {
+	return &stockTakeService{
+		repo:              repo,
+		cache:             cache,
+		db:                db,
+		adjustmentService: adjustmentService,
+		renderer:          renderer,
+		loc:               loc,
+	}
+}
+
+// OpenStockTake starts a stock take with one line for every batch that
+// currently holds stock.
+func (s *stockTakeService) OpenStockTake(ctx context.Context, input OpenStockTakeInput) (*models.StockTake, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidStockTake)
+	}
+
+	stockTake := &models.StockTake{
+		Name:             name,
+		Notes:            input.Notes,
+		Status:           models.StockTakeStatusOpen,
+		FreezeDispensing: input.FreezeDispensing,
+		OpenedByUserID:   GetActorUserID(ctx),
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var open int64
+		if err := tx.Model(&models.StockTake{}).Where("status = ?", models.StockTakeStatusOpen).Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrStockTakeInProgress
+		}
+
+		if err := tx.Create(stockTake).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO stock_take_lines (id, stock_take_id, medicine_id, medicine_batch_id, system_quantity, created_at, updated_at)
+			SELECT gen_random_uuid(), ?, b.medicine_id, b.id, b.quantity, NOW(), NOW()
+			FROM medicine_batches b
+			WHERE b.quantity > 0
+		`, stockTake.ID).Error
+	})
+	if err != nil {
+		// The unique index on open stock takes catches a concurrent open.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrStockTakeInProgress
+		}
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+func (s *stockTakeService) GetStockTake(ctx context.Context, id generated.IdParam) (*models.StockTake, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *stockTakeService) ListStockTakes(ctx context.Context, page, perPage int, status string) ([]models.StockTake, int64, error) {
+	return s.repo.FindAll(ctx, page, perPage, status)
+}
+
+func (s *stockTakeService) ListLines(ctx context.Context, id generated.IdParam) ([]models.StockTakeLine, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindLines(ctx, id, repository.StockTakeLineFilter{})
+}
+
+// RecordCounts stores counted quantities. Counting a batch again replaces the
+// earlier count, and the system quantity is taken anew each time so the
+// variance reflects the stock at the moment of counting.
+func (s *stockTakeService) RecordCounts(ctx context.Context, id generated.IdParam, counts []StockCountInput) ([]models.StockTakeLine, error) {
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("%w: counts are required", ErrInvalidStockTake)
+	}
+	for _, count := range counts {
+		if count.CountedQuantity < 0 {
+			return nil, fmt.Errorf("%w: counted_quantity must not be negative", ErrInvalidStockTake)
+		}
+	}
+
+	batchIDs := make([]string, len(counts))
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOpenStockTake(tx, id); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		actorID := GetActorUserID(ctx)
+		for i, count := range counts {
+			var batch models.MedicineBatch
+			if err := tx.First(&batch, "id = ?", count.MedicineBatchID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: medicine batch %s not found", ErrInvalidStockTake, count.MedicineBatchID)
+				}
+				return err
+			}
+
+			counted := count.CountedQuantity
+			line := models.StockTakeLine{
+				StockTakeID:     id.String(),
+				MedicineID:      batch.MedicineID,
+				MedicineBatchID: batch.ID.String(),
+				SystemQuantity:  batch.Quantity,
+				CountedQuantity: &counted,
+				CountedByUserID: actorID,
+				CountedAt:       &now,
+				Notes:           count.Notes,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "stock_take_id"}, {Name: "medicine_batch_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"system_quantity", "counted_quantity", "counted_by_user_id", "counted_at", "notes", "updated_at",
+				}),
+			}).Omit(clause.Associations).Create(&line).Error; err != nil {
+				return err
+			}
+			batchIDs[i] = line.MedicineBatchID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindLines(ctx, id, repository.StockTakeLineFilter{BatchIDs: batchIDs})
+}
+
+// ApproveStockTake closes the stock take and posts every non-zero variance
+// as an applied correction. The approver must be known and must be neither
+// the user who opened it nor anyone who counted it, since the corrections are
+// applied without a separate review.
+func (s *stockTakeService) ApproveStockTake(ctx context.Context, id generated.IdParam) (*models.StockTake, error) {
+	var stockTake *models.StockTake
+	var posted []models.StockTakeLine
+	if err := runStockTransaction(ctx, s.db, func(tx *gorm.DB) error {
+		posted = nil
+		var err error
+		stockTake, err = lockOpenStockTake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		approverID := GetActorUserID(ctx)
+		if approverID == nil {
+			return fmt.Errorf("%w: the approver could not be identified", ErrSelfApproval)
+		}
+		if stockTake.OpenedByUserID != nil && *approverID == *stockTake.OpenedByUserID {
+			return ErrSelfApproval
+		}
+
+		var lines []models.StockTakeLine
+		if err := tx.Where("stock_take_id = ? AND counted_quantity IS NOT NULL", id).
+			Order("id ASC").
+			Find(&lines).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			if lines[i].CountedByUserID != nil && *lines[i].CountedByUserID == *approverID {
+				return fmt.Errorf("%w: the approver counted batch %s", ErrSelfApproval, lines[i].MedicineBatchID)
+			}
+		}
+
+		now := time.Now()
+		for i := range lines {
+			variance := *lines[i].Variance()
+			if variance == 0 {
+				continue
+			}
+
+			adjustment := &models.StockAdjustment{
+				MedicineID:      lines[i].MedicineID,
+				MedicineBatchID: lines[i].MedicineBatchID,
+				Type:            models.StockAdjustmentTypeCorrection,
+				ReasonCode:      "stock_take",
+				Justification: fmt.Sprintf("Stock take %q: counted %d, expected %d",
+					stockTake.Name, *lines[i].CountedQuantity, lines[i].SystemQuantity),
+				QuantityDelta:     variance,
+				RequestedByUserID: lines[i].CountedByUserID,
+				ReviewedByUserID:  approverID,
+				ReviewedAt:        &now,
+			}
+			if err := s.adjustmentService.PostAdjustment(ctx, tx, adjustment); err != nil {
+				return fmt.Errorf("batch %s: %w", lines[i].MedicineBatchID, err)
+			}
+			if err := tx.Model(&lines[i]).Update("stock_adjustment_id", adjustment.ID.String()).Error; err != nil {
+				return err
+			}
+			posted = append(posted, lines[i])
+		}
+
+		stockTake.Status = models.StockTakeStatusApproved
+		stockTake.ApprovedByUserID = approverID
+		stockTake.ApprovedAt = &now
+		return tx.Save(stockTake).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, line := range posted {
+		invalidateBatchCache(ctx, s.cache, line.MedicineID, line.MedicineBatchID)
+	}
+	return stockTake, nil
+}
+
+// CancelStockTake closes the stock take without touching stock.
+func (s *stockTakeService) CancelStockTake(ctx context.Context, id generated.IdParam) (*models.StockTake, error) {
+	var stockTake *models.StockTake
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		stockTake, err = lockOpenStockTake(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stockTake.Status = models.StockTakeStatusCancelled
+		stockTake.CancelledAt = &now
+		return tx.Save(stockTake).Error
+	}); err != nil {
+		return nil, err
+	}
+	return stockTake, nil
+}
+
+// VarianceReport sums up the stock take and returns its counted lines.
+func (s *stockTakeService) VarianceReport(ctx context.Context, id generated.IdParam) (*models.StockTakeVariance, []models.StockTakeLine, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	lines, err := s.repo.FindLines(ctx, id, repository.StockTakeLineFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary := &models.StockTakeVariance{TotalLines: len(lines)}
+	counted := make([]models.StockTakeLine, 0, len(lines))
+	for _, line := range lines {
+		variance := line.Variance()
+		if variance == nil {
+			continue
+		}
+		counted = append(counted, line)
+		summary.CountedLines++
+		if *variance == 0 {
+			continue
+		}
+
+		summary.LinesWithVariance++
+		summary.NetQuantity += *variance
+		if *variance > 0 {
+			summary.SurplusQuantity += *variance
+		} else {
+			summary.ShortageQuantity -= *variance
+		}
+		if line.MedicineBatch.UnitCost != nil {
+			summary.NetValue += float64(*variance) * *line.MedicineBatch.UnitCost
+		}
+	}
+	return summary, counted, nil
+}
+
+func (s *stockTakeService) ExportVariance(ctx context.Context, id generated.IdParam, format string, w io.Writer) error {
+	if !exports.IsValidFormat(format) {
+		return exports.ErrUnknownFormat
+	}
+	_, lines, err := s.VarianceReport(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	writer, err := exports.New(format, w, "Variance")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader([]string{
+		"Medicine code", "Medicine", "Batch", "Expiration date", "Unit", "System quantity",
+		"Counted quantity", "Variance", "Unit cost", "Variance value", "Counted at", "Notes",
+	}); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		variance := *line.Variance()
+		var value any
+		if line.MedicineBatch.UnitCost != nil {
+			value = float64(variance) * *line.MedicineBatch.UnitCost
+		}
+		if err := writer.WriteRow([]any{
+			line.Medicine.Code,
+			line.Medicine.Name,
+			line.MedicineBatch.BatchNumber,
+			line.MedicineBatch.ExpirationDate.Format("2006-01-02"),
+			line.MedicineBatch.Unit,
+			line.SystemQuantity,
+			*line.CountedQuantity,
+			variance,
+			exportNumber(line.MedicineBatch.UnitCost),
+			value,
+			line.CountedAt.In(s.loc).Format("2006-01-02 15:04"),
+			exportString(line.Notes),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// CountSheet renders the printable count sheet of the stock take.
+func (s *stockTakeService) CountSheet(ctx context.Context, id generated.IdParam) (*models.StockTake, []byte, error) {
+	stockTake, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := s.repo.FindLines(ctx, id, repository.StockTakeLineFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := s.renderer.RenderCountSheet(stockTake, lines)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stockTake, pdf, nil
+}
+
+// lockOpenStockTake locks the stock take for the rest of the transaction and
+// fails unless it is still open.
+func lockOpenStockTake(tx *gorm.DB, id generated.IdParam) (*models.StockTake, error) {
+	var stockTake models.StockTake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stockTake, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	if stockTake.Status != models.StockTakeStatusOpen {
+		return nil, ErrStockTakeNotOpen
+	}
+	return &stockTake, nil
+}
+
+// checkDispensingNotFrozen fails while an open stock take freezes dispensing.
+func checkDispensingNotFrozen(tx *gorm.DB) error {
+	var frozen int64
+	if err := tx.Model(&models.StockTake{}).
+		Where("status = ? AND freeze_dispensing", models.StockTakeStatusOpen).
+		Count(&frozen).Error; err != nil {
+		return err
+	}
+	if frozen > 0 {
+		return ErrDispensingFrozen
+	}
+	return nil
+}
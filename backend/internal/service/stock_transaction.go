@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const maxStockTxAttempts = 5
+
+// errStockConflict signals that a conditional stock update lost a race. The
+// transaction is rolled back and retried like a serialization failure.
+var errStockConflict = errors.New("stock changed concurrently")
+
+// runStockTransaction runs fn in a transaction and retries it when Postgres
+// aborts it with a serialization failure or deadlock, or when a conditional
+// stock update lost a race. fn must be safe to run more than once.
+func runStockTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	for attempt := 1; ; attempt++ {
+		err := db.WithContext(ctx).Transaction(fn)
+		if err == nil || attempt == maxStockTxAttempts || !isRetryableTxError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt*attempt) * 10 * time.Millisecond):
+		}
+	}
+}
+
+func isRetryableTxError(err error) bool {
+	if errors.Is(err, errStockConflict) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01": // deadlock_detected
+			return true
+		}
+	}
+	return false
+}
+
+// lockMedicineStock takes a row lock on the medicine for the rest of the
+// transaction. Every stock change locks the medicine before touching its
+// batches, which serializes changes per medicine and keeps the before/after
+// values of stock activities consistent.
+func lockMedicineStock(tx *gorm.DB, medicineID string) error {
+	var ids []string
+	if err := tx.Raw("SELECT id FROM medicines WHERE id = ? FOR UPDATE", medicineID).Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("medicine_id=%s: %w", medicineID, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
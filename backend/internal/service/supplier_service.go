@@ -0,0 +1,84 @@
+package service
+
+import (
+	"backend/internal/generated"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrInvalidSupplier   = errors.New("invalid supplier")
+	ErrSupplierCodeTaken = errors.New("supplier code is already in use")
+)
+
+type SupplierService interface {
+	CreateSupplier(ctx context.Context, supplier *models.Supplier) error
+	GetSupplier(ctx context.Context, id generated.IdParam) (*models.Supplier, error)
+	ListSuppliers(ctx context.Context, page, perPage int, filter repository.SupplierFilter) ([]models.Supplier, int64, error)
+	UpdateSupplier(ctx context.Context, id generated.IdParam, supplier *models.Supplier) error
+}
+
+type supplierService struct {
+	repo repository.SupplierRepository
+}
+
+func NewSupplierService(repo repository.SupplierRepository) SupplierService {
+	return &supplierService{repo: repo}
+}
+
+func (s *supplierService) CreateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	if err := normalizeSupplier(supplier); err != nil {
+		return err
+	}
+	return supplierWriteError(s.repo.Create(ctx, supplier))
+}
+
+func (s *supplierService) GetSupplier(ctx context.Context, id generated.IdParam) (*models.Supplier, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *supplierService) ListSuppliers(ctx context.Context, page, perPage int, filter repository.SupplierFilter) ([]models.Supplier, int64, error) {
+	return s.repo.FindAll(ctx, page, perPage, filter)
+}
+
+func (s *supplierService) UpdateSupplier(ctx context.Context, id generated.IdParam, supplier *models.Supplier) error {
+	existing, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := normalizeSupplier(supplier); err != nil {
+		return err
+	}
+
+	supplier.ID = existing.ID
+	supplier.CreatedAt = existing.CreatedAt
+	return supplierWriteError(s.repo.Update(ctx, supplier))
+}
+
+func normalizeSupplier(supplier *models.Supplier) error {
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	supplier.Code = strings.ToUpper(strings.TrimSpace(supplier.Code))
+	if supplier.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSupplier)
+	}
+	if supplier.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidSupplier)
+	}
+	return nil
+}
+
+// supplierWriteError turns a violation of the unique supplier code into
+// ErrSupplierCodeTaken.
+func supplierWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrSupplierCodeTaken
+	}
+	return err
+}
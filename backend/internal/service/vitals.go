@@ -0,0 +1,91 @@
+package service
+
+import (
+	"backend/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrImplausibleVitals = errors.New("implausible vital signs")
+
+// vitalSigns is the set of vitals recorded at triage and on a checkup.
+type vitalSigns struct {
+	TemperatureC           *float64
+	BloodPressureSystolic  *int
+	BloodPressureDiastolic *int
+	HeartRate              *int
+	RespiratoryRate        *int
+	OxygenSaturation       *int
+	HeightCm               *float64
+	WeightKg               *float64
+}
+
+func checkupVitals(c *models.PatientCheckup) vitalSigns {
+	return vitalSigns{
+		TemperatureC:           c.TemperatureC,
+		BloodPressureSystolic:  c.BloodPressureSystolic,
+		BloodPressureDiastolic: c.BloodPressureDiastolic,
+		HeartRate:              c.HeartRate,
+		RespiratoryRate:        c.RespiratoryRate,
+		OxygenSaturation:       c.OxygenSaturation,
+		HeightCm:               c.HeightCm,
+		WeightKg:               c.WeightKg,
+	}
+}
+
+// validate rejects readings outside what a living patient can present with,
+// which catches typos such as "12/80" or 60 °C. It does not judge whether a
+// value is normal; that is what the abnormal flags are for.
+func (v vitalSigns) validate() error {
+	if err := floatInRange("temperature_c", v.TemperatureC, 30, 45); err != nil {
+		return err
+	}
+	if (v.BloodPressureSystolic == nil) != (v.BloodPressureDiastolic == nil) {
+		return fmt.Errorf("%w: blood pressure needs both systolic and diastolic values", ErrImplausibleVitals)
+	}
+	if err := intInRange("blood_pressure_systolic", v.BloodPressureSystolic, 50, 280); err != nil {
+		return err
+	}
+	if err := intInRange("blood_pressure_diastolic", v.BloodPressureDiastolic, 20, 180); err != nil {
+		return err
+	}
+	if v.BloodPressureSystolic != nil && *v.BloodPressureSystolic <= *v.BloodPressureDiastolic {
+		return fmt.Errorf("%w: systolic blood pressure must be higher than diastolic", ErrImplausibleVitals)
+	}
+	if err := intInRange("heart_rate", v.HeartRate, 20, 250); err != nil {
+		return err
+	}
+	if err := intInRange("respiratory_rate", v.RespiratoryRate, 4, 80); err != nil {
+		return err
+	}
+	if err := intInRange("oxygen_saturation", v.OxygenSaturation, 50, 100); err != nil {
+		return err
+	}
+	if err := floatInRange("height_cm", v.HeightCm, 30, 250); err != nil {
+		return err
+	}
+	return floatInRange("weight_kg", v.WeightKg, 0.5, 400)
+}
+
+func intInRange(field string, value *int, min, max int) error {
+	if value != nil && (*value < min || *value > max) {
+		return fmt.Errorf("%w: %s must be between %d and %d", ErrImplausibleVitals, field, min, max)
+	}
+	return nil
+}
+
+func floatInRange(field string, value *float64, min, max float64) error {
+	if value != nil && (*value < min || *value > max) {
+		return fmt.Errorf("%w: %s must be between %g and %g", ErrImplausibleVitals, field, min, max)
+	}
+	return nil
+}
+
+// boolFilterKey renders an optional boolean filter for a cache key.
+func boolFilterKey(value *bool) string {
+	if value == nil {
+		return "none"
+	}
+	return strconv.FormatBool(*value)
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"backend/internal/config"
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrObjectNotFound is returned when the requested key does not exist
+var ErrObjectNotFound = errors.New("storage object not found")
+
+// Storage interface - all file storage implementations must satisfy this
+type Storage interface {
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the storage driver selected in the configuration
+func New(cfg config.StorageConfig) (Storage, error) {
+	if cfg.Driver == "s3" {
+		return NewS3Storage(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	}
+	return NewLocalStorage(cfg.LocalPath)
+}
@@ -42,7 +42,6 @@ function toUpdatePayload(form: BatchFormState): UpdateMedicineBatchRequest {
   return {
     batch_number: form.batch_number,
     expiration_date: form.expiration_date,
-    stock: Number(form.stock),
     unit: form.unit,
   };
 }
@@ -51,6 +50,7 @@ function toCreatePayload(medicineId: string, form: BatchFormState): CreateMedici
   return {
     medicine_id: medicineId,
     ...toUpdatePayload(form),
+    stock: Number(form.stock),
   };
 }
 
@@ -251,6 +251,11 @@ export function MedicineBatchesDialog({ medicine, open, onOpenChange }: Medicine
                       <td className="p-2">{activity.source}</td>
                       <td className={`p-2 font-medium ${activity.quantity_delta < 0 ? "text-destructive" : "text-emerald-600"}`}>
                         {activity.quantity_delta > 0 ? `+${activity.quantity_delta}` : activity.quantity_delta}
+                        {activity.change_type === "status" && (
+                          <span className="ml-1 text-xs font-normal text-muted-foreground">
+                            ({activity.expired_delta > 0 ? `${activity.expired_delta} expired` : `${-activity.expired_delta} no longer expired`})
+                          </span>
+                        )}
                       </td>
                       <td className="p-2">{activity.stock_before} → {activity.stock_after}</td>
                       <td className="p-2">{activity.medicine_batch_id ? activity.medicine_batch_id.slice(0, 8) : "-"}</td>
@@ -284,7 +289,7 @@ export function MedicineBatchesDialog({ medicine, open, onOpenChange }: Medicine
           <DialogHeader>
             <DialogTitle>Edit Batch</DialogTitle>
           </DialogHeader>
-          <BatchForm form={editForm} onChange={setEditForm} />
+          <BatchForm form={editForm} onChange={setEditForm} stockLocked />
           <Button
             onClick={() => editBatch && updateMutation.mutate({ id: editBatch.id, data: toUpdatePayload(editForm) })}
             disabled={updateMutation.isPending}
@@ -300,9 +305,11 @@ export function MedicineBatchesDialog({ medicine, open, onOpenChange }: Medicine
 function BatchForm({
   form,
   onChange,
+  stockLocked = false,
 }: {
   form: BatchFormState;
   onChange: (value: BatchFormState) => void;
+  stockLocked?: boolean;
 }) {
   return (
     <div className="grid grid-cols-1 gap-3">
@@ -316,7 +323,16 @@ function BatchForm({
       </div>
       <div className="space-y-2">
         <Label>Stock</Label>
-        <Input type="number" min={0} value={form.stock} onChange={(e) => onChange({ ...form, stock: e.target.value })} />
+        <Input
+          type="number"
+          min={0}
+          value={form.stock}
+          disabled={stockLocked}
+          onChange={(e) => onChange({ ...form, stock: e.target.value })}
+        />
+        {stockLocked && (
+          <p className="text-xs text-muted-foreground">Use a stock adjustment to correct the quantity of a batch.</p>
+        )}
       </div>
       <div className="space-y-2">
         <Label>Unit</Label>